@@ -0,0 +1,13 @@
+package parallel
+
+// MARK: Private functions
+
+// pinnedCPU returns the CPU of affinity the routine-th routine is pinned to,
+// as a set for bindToCPUs. Routines are assigned the CPUs in turn. If affinity
+// is empty, nil is returned.
+func pinnedCPU(affinity []int, routine int) []int {
+	if len(affinity) == 0 {
+		return nil
+	}
+	return affinity[routine%len(affinity) : routine%len(affinity)+1]
+}
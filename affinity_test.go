@@ -0,0 +1,37 @@
+package parallel
+
+import (
+	"reflect"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestPinnedCPU(t *testing.T) {
+	if cpus := pinnedCPU(nil, 3); cpus != nil {
+		t.Errorf("Routines shouldn't be pinned without an affinity, not to %v.", cpus)
+	}
+
+	affinity := []int{4, 6, 8}
+	for routine, expected := range []int{4, 6, 8, 4, 6} {
+		if cpus := pinnedCPU(affinity, routine); !reflect.DeepEqual(cpus, []int{expected}) {
+			t.Errorf("Routine %d should be pinned to CPU %d, not %v.", routine, expected, cpus)
+		}
+	}
+}
+
+func TestSetCPUAffinityCopies(t *testing.T) {
+	cpus := []int{0, 1}
+	p := NewFixedProcess(2)
+	p.SetCPUAffinity(cpus)
+	cpus[0] = 5
+
+	if affinity := p.CPUAffinity(); !reflect.DeepEqual(affinity, []int{0, 1}) {
+		t.Errorf("The affinity, %v, should be a copy of the CPUs.", affinity)
+	}
+
+	p.SetCPUAffinity(nil)
+	if affinity := p.CPUAffinity(); affinity != nil {
+		t.Errorf("The affinity, %v, should be cleared.", affinity)
+	}
+}
@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"math"
+	"time"
+)
+
+// aimdTolerance is the relative change in throughput between optimizations
+// that an AIMD optimizer considers noise rather than an improvement or a
+// regression.
+const aimdTolerance = 0.05
+
+// aimdOptimizer types choose a number of routines by additive increase and
+// multiplicative decrease. Routines are added one at a time while throughput
+// improves and halved when it regresses.
+type aimdOptimizer struct {
+	// The number of routines the optimizer calls for.
+	routines float64
+
+	// The meter measuring throughput between optimizations.
+	meter throughputMeter
+
+	// The throughput measured at the last optimization in iterations per
+	// second, or zero if there is no measurement to compare to.
+	throughput float64
+}
+
+// MARK: Private methods
+
+// reset resets the optimizer to call for the specified number of routines,
+// measuring throughput from now.
+func (a *aimdOptimizer) reset(routines int, now time.Time) {
+	a.routines = float64(routines)
+	a.meter.reset(now)
+	a.throughput = 0.0
+}
+
+// next returns the number of routines to use given the number of iterations
+// that have begun by now, along with the relative change in throughput since
+// the last optimization.
+func (a *aimdOptimizer) next(started int, now time.Time, maxRoutines int) (float64, float64) {
+	throughput, ok := a.meter.measure(started, now)
+	if !ok {
+		return a.routines, 0.0
+	}
+
+	change := 0.0
+	if a.throughput > 0.0 {
+		change = (throughput - a.throughput) / a.throughput
+	}
+
+	if a.throughput == 0.0 || change > aimdTolerance {
+		a.routines++
+		a.throughput = throughput
+	} else if change < -aimdTolerance {
+		// Measure the next change from the reduced number of routines rather
+		// than comparing it to the regressed throughput.
+		a.routines = math.Max(1.0, math.Floor(a.routines/2.0))
+		a.throughput = 0.0
+	} else {
+		a.throughput = throughput
+	}
+
+	a.routines = math.Min(a.routines, math.Max(1.0, float64(maxRoutines)))
+	return a.routines, change
+}
@@ -0,0 +1,96 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestAIMDOptimizerIncreases(t *testing.T) {
+	var a aimdOptimizer
+	now := time.Now()
+	a.reset(1, now)
+
+	// Throughput scales with the number of routines.
+	started := 0
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		started += int(a.routines) * 100
+		a.next(started, now, 8)
+	}
+
+	if a.routines != 6.0 {
+		t.Errorf("Routines, %f, should be 6.", a.routines)
+	}
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		started += int(a.routines) * 100
+		a.next(started, now, 8)
+	}
+
+	if a.routines != 8.0 {
+		t.Errorf("Routines, %f, should be limited to 8.", a.routines)
+	}
+}
+
+func TestAIMDOptimizerHalves(t *testing.T) {
+	var a aimdOptimizer
+	now := time.Now()
+	a.reset(8, now)
+
+	now = now.Add(time.Second)
+	a.next(1000, now, 16)
+
+	now = now.Add(time.Second)
+	u, change := a.next(1500, now, 16)
+
+	if u != 4.0 {
+		t.Errorf("Routines, %f, should be halved to 4.", u)
+	}
+
+	if math.Abs(change+0.5) > 1e-9 {
+		t.Errorf("Change, %f, should be -0.5.", change)
+	}
+}
+
+func TestAIMDOptimizerHolds(t *testing.T) {
+	var a aimdOptimizer
+	now := time.Now()
+	a.reset(4, now)
+
+	now = now.Add(time.Second)
+	a.next(1000, now, 16)
+
+	now = now.Add(time.Second)
+	u, _ := a.next(2010, now, 16)
+
+	if u != 5.0 {
+		t.Errorf("Routines, %f, should be held at 5.", u)
+	}
+}
+
+func TestVariableProcessAIMD(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, c, false)
+	p.SetOptimizer(OptimizerAIMD)
+
+	v := make([]int, 2000)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Configuration.Optimizer != "aimd" {
+		t.Errorf("Summary optimizer, %s, should be aimd.", s.Configuration.Optimizer)
+	}
+}
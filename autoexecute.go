@@ -0,0 +1,178 @@
+package parallel
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+const (
+	// The maximum fraction of the iterations AutoExecute runs sequentially while
+	// calibrating.
+	autoCalibrationDivisor = 20
+
+	// The time after which AutoExecute stops calibrating.
+	autoCalibrationTime = 2 * time.Millisecond
+
+	// The minimum calibration time over which the process' CPU time is
+	// considered precise enough to detect blocking operations.
+	autoCPUTimePrecision = time.Millisecond
+
+	// Operations using less than this fraction of their wall time on a CPU are
+	// considered to block.
+	autoBlockingFraction = 0.5
+
+	// Operations allocating at least this many bytes per iteration are
+	// considered memory heavy.
+	autoHeavyAllocation = 1 << 20
+
+	// Operations with a coefficient of variation of their latency above this
+	// value are considered skewed.
+	autoSkewedVariation = 1.0
+
+	// Operations faster than this with a coefficient of variation below
+	// autoUniformVariation are considered cheap and uniform.
+	autoCheapLatency     = time.Microsecond
+	autoUniformVariation = 0.25
+
+	// The maximum number of routines AutoExecute starts for blocking
+	// operations.
+	autoMaxRoutines = 256
+)
+
+// MARK: Public functions
+
+// AutoExecute executes operation for the given number of iterations on the
+// process best suited to it and returns the process.
+//
+// AutoExecute first runs a few iterations sequentially while measuring their
+// latency, the CPU time they use and the memory they allocate. Cheap and
+// CPU-bound operations execute on a fixed process with one routine per CPU,
+// using the work-stealing schedule when their latencies are skewed. Operations
+// that block or allocate heavily execute on a variable process whose
+// controller finds the number of routines, and whose controller configuration
+// sheds routines under memory pressure for heavy allocators.
+//
+// The iterations run while calibrating aren't included in the returned
+// process' stats.
+func AutoExecute(iterations int, operation Operation) Process {
+	checkIterations("AutoExecute", iterations)
+	checkOperation("AutoExecute", operation == nil)
+
+	c := calibrateWorkload(iterations, operation)
+	p := c.process(iterations - c.iterations)
+	p.Execute(iterations-c.iterations, func(i int) {
+		operation(i + c.iterations)
+	})
+	return p
+}
+
+// MARK: Private types
+
+// workloadCalibration types contain the measurements AutoExecute makes of an
+// operation.
+type workloadCalibration struct {
+	// The number of iterations executed while calibrating.
+	iterations int
+
+	// The mean latency of an iteration.
+	latency time.Duration
+
+	// The coefficient of variation of the iterations' latencies.
+	variation float64
+
+	// The fraction of the calibration's wall time spent on a CPU, or 1.0 if the
+	// calibration was too short to measure it.
+	cpuFraction float64
+
+	// The mean number of bytes allocated per iteration.
+	allocated uint64
+}
+
+// MARK: Private functions
+
+// calibrateWorkload executes the first iterations of operation sequentially
+// and returns its measurements. At least one iteration is executed unless
+// iterations is zero.
+func calibrateWorkload(iterations int, operation Operation) workloadCalibration {
+	c := workloadCalibration{cpuFraction: 1.0}
+	if iterations == 0 {
+		return c
+	}
+
+	limit := maxInt(1, iterations/autoCalibrationDivisor)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	cpuStart := processCPUTime()
+	start := time.Now()
+
+	var sum, sumSquares float64
+	for c.iterations < limit && time.Since(start) < autoCalibrationTime {
+		iterationStart := time.Now()
+		operation(c.iterations)
+		d := float64(time.Since(iterationStart))
+		sum += d
+		sumSquares += d * d
+		c.iterations++
+	}
+
+	elapsed := time.Since(start)
+	cpu := processCPUTime() - cpuStart
+	runtime.ReadMemStats(&after)
+
+	n := float64(c.iterations)
+	mean := sum / n
+	c.latency = time.Duration(mean)
+	if mean > 0 {
+		c.variation = math.Sqrt(math.Max(0.0, sumSquares/n-mean*mean)) / mean
+	}
+
+	if elapsed >= autoCPUTimePrecision {
+		c.cpuFraction = math.Min(1.0, float64(cpu)/float64(elapsed))
+	}
+
+	c.allocated = (after.TotalAlloc - before.TotalAlloc) / uint64(c.iterations)
+	return c
+}
+
+// process returns a process configured for executing the given number of
+// remaining iterations of the calibrated workload.
+func (c workloadCalibration) process(remaining int) Process {
+	cpus := runtime.NumCPU()
+	blocking := c.cpuFraction < autoBlockingFraction
+	heavy := c.allocated >= autoHeavyAllocation
+
+	if !blocking && !heavy {
+		p := NewFixedProcess(maxInt(1, minInt(cpus, remaining)))
+		if c.variation > autoSkewedVariation {
+			p.SetSchedule(ScheduleWorkStealing)
+		} else if c.latency < autoCheapLatency && c.variation < autoUniformVariation {
+			p.SetSchedule(ScheduleStatic)
+		}
+		return p
+	}
+
+	// Blocking operations can keep about 1/cpuFraction routines busy per CPU.
+	maxRoutines := cpus
+	if blocking {
+		perCPU := int(math.Ceil(1.0 / math.Max(c.cpuFraction, 1.0/autoMaxRoutines)))
+		maxRoutines = minInt(autoMaxRoutines, 2*cpus*perCPU)
+	}
+	maxRoutines = maxInt(1, minInt(maxRoutines, remaining))
+
+	// Give each optimization interval time to complete a few iterations.
+	interval := 20 * c.latency
+	if interval < 50*time.Millisecond {
+		interval = 50 * time.Millisecond
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+
+	configuration := NewBalancedControllerConfiguration()
+	if heavy {
+		configuration.MemoryHighWater = 0.9
+	}
+
+	return NewVariableProcess(interval, minInt(cpus, maxRoutines), maxRoutines, configuration, false)
+}
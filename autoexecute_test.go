@@ -0,0 +1,98 @@
+package parallel
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestAutoExecuteCompleteness(t *testing.T) {
+	counts := make([]int32, 10000)
+	p := AutoExecute(len(counts), func(i int) {
+		atomic.AddInt32(&counts[i], 1)
+	})
+
+	for i, count := range counts {
+		if count != 1 {
+			t.Fatalf("Iteration %d executed %d times.", i, count)
+		}
+	}
+
+	if _, ok := p.(*FixedProcess); !ok {
+		t.Errorf("A cheap operation should execute on a fixed process, not %T.", p)
+	}
+}
+
+func TestAutoExecuteBlocking(t *testing.T) {
+	var count int32
+	p := AutoExecute(100, func(i int) {
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&count, 1)
+	})
+
+	if count != 100 {
+		t.Errorf("%d iterations executed, expected 100.", count)
+	}
+
+	if _, ok := p.(*VariableProcess); !ok {
+		t.Errorf("A blocking operation should execute on a variable process, not %T.", p)
+	}
+}
+
+func TestAutoExecuteNoIterations(t *testing.T) {
+	p := AutoExecute(0, func(i int) {
+		t.Errorf("Iteration %d should not have executed.", i)
+	})
+
+	if p == nil {
+		t.Error("A process should be returned.")
+	}
+}
+
+func TestWorkloadCalibrationProcess(t *testing.T) {
+	cpus := runtime.NumCPU()
+
+	p := workloadCalibration{latency: 100 * time.Nanosecond, cpuFraction: 1.0}.process(1000)
+	if f, ok := p.(*FixedProcess); !ok || f.Schedule() != ScheduleStatic {
+		t.Errorf("Cheap uniform operations should use a static fixed process, not %#v.", p)
+	}
+
+	p = workloadCalibration{latency: time.Millisecond, variation: 2.0, cpuFraction: 1.0}.process(1000)
+	if f, ok := p.(*FixedProcess); !ok || f.Schedule() != ScheduleWorkStealing {
+		t.Errorf("Skewed operations should use a work-stealing fixed process, not %#v.", p)
+	}
+
+	p = workloadCalibration{latency: time.Millisecond, variation: 0.5, cpuFraction: 1.0}.process(1000)
+	if f, ok := p.(*FixedProcess); !ok || f.Schedule() != ScheduleDynamic || f.NumRoutines() != cpus {
+		t.Errorf("CPU-bound operations should use a dynamic fixed process, not %#v.", p)
+	}
+
+	p = workloadCalibration{latency: 10 * time.Millisecond, cpuFraction: 0.1}.process(1000)
+	v, ok := p.(*VariableProcess)
+	if !ok {
+		t.Fatalf("Blocking operations should use a variable process, not %T.", p)
+	}
+	if m := v.GetMaxRoutines(); m != minInt(autoMaxRoutines, 20*cpus) {
+		t.Errorf("Max routines, %d, should allow 10 routines per CPU with headroom.", m)
+	}
+	if v.GetOptimizationInterval() != 200*time.Millisecond {
+		t.Errorf("Optimization interval, %s, should be 20 latencies.", v.GetOptimizationInterval())
+	}
+
+	p = workloadCalibration{latency: time.Millisecond, cpuFraction: 1.0, allocated: 4 << 20}.process(1000)
+	v, ok = p.(*VariableProcess)
+	if !ok {
+		t.Fatalf("Memory heavy operations should use a variable process, not %T.", p)
+	}
+	if v.GetMaxRoutines() != cpus || v.GetControllerConfiguration().MemoryHighWater == 0.0 {
+		t.Error("Memory heavy operations should be limited to the CPUs and have a high water mark.")
+	}
+
+	p = workloadCalibration{latency: 10 * time.Millisecond, cpuFraction: 0.1}.process(3)
+	if v, ok := p.(*VariableProcess); !ok || v.GetMaxRoutines() != 3 {
+		t.Error("Max routines should be limited to the remaining iterations.")
+	}
+}
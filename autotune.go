@@ -0,0 +1,113 @@
+package parallel
+
+import "math"
+
+// autoTuneMaxTicks is the maximum number of optimizations a relay test runs
+// for before it gives up without tuning the controller.
+const autoTuneMaxTicks = 60
+
+// autoTuneHalfPeriods is the number of error sign changes a relay test
+// observes before it tunes the controller. The first is discarded because the
+// oscillation hasn't settled yet.
+const autoTuneHalfPeriods = 5
+
+// autoTuneSetpoint is the error the relay switches at. CPU usage can't exceed
+// the number of CPUs, so the error never becomes negative and the relay
+// switches around a utilization of 90% instead.
+const autoTuneSetpoint = 0.1
+
+// autoTuner types tune a PID controller with a relay feedback test. The
+// routine count is switched between two levels depending on the sign of the
+// error, which makes the loop oscillate at its ultimate period. The ultimate
+// gain and period give the controller coefficients by the Ziegler-Nichols
+// rules.
+type autoTuner struct {
+	// The number of CPUs the error is normalized by.
+	cpuCount float64
+
+	// The relay's center and amplitude in routines.
+	bias      float64
+	amplitude float64
+
+	// The number of optimizations the test has run for.
+	ticks int
+
+	// The optimizations at which the error changed sign.
+	crossings []int
+
+	// The sign of the last error.
+	sign float64
+
+	// The largest and smallest error since the first sign change.
+	maxError float64
+	minError float64
+}
+
+// MARK: Initializers
+
+// newAutoTuner creates and returns a new auto tuner for a process that may use
+// up to maxRoutines routines.
+func newAutoTuner(cpuCount int, maxRoutines int) *autoTuner {
+	bias := math.Max(2.0, math.Floor(float64(maxRoutines)/2.0))
+	return &autoTuner{
+		cpuCount:  float64(cpuCount),
+		bias:      bias,
+		amplitude: math.Max(1.0, math.Floor(bias/2.0)),
+		maxError:  math.Inf(-1),
+		minError:  math.Inf(1),
+	}
+}
+
+// MARK: Private methods
+
+// next returns the relay's output for the measured CPU usage and the error the
+// output responds to. Once the test is over, done is true and ok reports
+// whether an oscillation was measured.
+func (t *autoTuner) next(usage float64) (output float64, e float64, done bool, ok bool) {
+	t.ticks++
+	e = 1.0 - usage/t.cpuCount
+
+	sign := 1.0
+	if e < autoTuneSetpoint {
+		sign = -1.0
+	}
+
+	if t.sign != 0.0 && sign != t.sign {
+		t.crossings = append(t.crossings, t.ticks)
+	}
+	t.sign = sign
+
+	if len(t.crossings) > 0 {
+		t.maxError = math.Max(t.maxError, e)
+		t.minError = math.Min(t.minError, e)
+	}
+
+	if len(t.crossings) >= autoTuneHalfPeriods {
+		return t.bias, e, true, t.maxError > t.minError
+	}
+
+	if t.ticks >= autoTuneMaxTicks {
+		return t.bias, e, true, false
+	}
+
+	return t.bias + sign*t.amplitude, e, false, false
+}
+
+// configuration returns configuration with its coefficients replaced by the
+// ones derived from the relay test.
+func (t *autoTuner) configuration(configuration *ControllerConfiguration) *ControllerConfiguration {
+	// The ultimate gain is the relay's amplitude over the amplitude of the
+	// oscillation it caused, and the ultimate period is twice the average
+	// number of optimizations between sign changes.
+	a := (t.maxError - t.minError) / 2.0
+	ku := 4.0 * t.amplitude / (math.Pi * a)
+
+	first, last := t.crossings[1], t.crossings[len(t.crossings)-1]
+	tu := 2.0 * float64(last-first) / float64(len(t.crossings)-2)
+
+	tuned := configuration.Copy()
+	tuned.Kp = 0.6 * ku
+	tuned.Ki = tuned.Kp / (tu / 2.0)
+	tuned.Kd = tuned.Kp * tu / 8.0
+	return tuned
+}
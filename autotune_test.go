@@ -0,0 +1,74 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestAutoTunerRelay(t *testing.T) {
+	tuner := newAutoTuner(4, 8)
+
+	// A first-order plant whose CPU usage follows the routine count up to
+	// the number of CPUs.
+	usage, routines := 0.0, 4.0
+	done, ok := false, false
+	for i := 0; i < autoTuneMaxTicks && !done; i++ {
+		usage = 0.5*usage + 0.5*math.Min(routines, 4.0)
+		routines, _, done, ok = tuner.next(usage)
+	}
+
+	if !done || !ok {
+		t.Fatalf("The relay test should finish with an oscillation after %d ticks.", tuner.ticks)
+	}
+
+	c := tuner.configuration(NewControllerConfiguration(1.0, 1.0, 1.0, 0.1, 1.0))
+	for _, k := range []float64{c.Kp, c.Ki, c.Kd} {
+		if !(k > 0.0) || math.IsInf(k, 0) {
+			t.Errorf("Configuration, %+v, should have positive, finite coefficients.", c)
+			break
+		}
+	}
+
+	if c.ErrorResponse != 0.1 {
+		t.Errorf("Tuning should not change the error response.")
+	}
+}
+
+func TestAutoTunerGivesUp(t *testing.T) {
+	tuner := newAutoTuner(4, 8)
+
+	done, ok := false, false
+	for i := 0; i < autoTuneMaxTicks && !done; i++ {
+		_, _, done, ok = tuner.next(4.0)
+	}
+
+	if !done || ok {
+		t.Errorf("The relay test should give up when the usage never changes.")
+	}
+}
+
+func TestVariableProcessAutoTune(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, c, false)
+	p.SetAutoTune(true)
+
+	v := make([]int, 2000)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if !p.AutoTune() {
+		t.Errorf("Auto-tuning should remain enabled.")
+	}
+}
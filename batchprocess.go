@@ -0,0 +1,155 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// BatchOperation types represent a batch of operations in a parallel process.
+// Responders should perform the operations with indices in [start, end) of the
+// batch-th batch.
+type BatchOperation func(batch int, start int, end int)
+
+// BatchProcess types group the iteration space into batches of a fixed size
+// and execute a batch-level operation for each batch on another process.
+type BatchProcess struct {
+	// The number of iterations in the current execution and how many have
+	// completed. They are updated atomically and are the first fields so that
+	// they are 64-bit aligned.
+	iterations int64
+	completed  int64
+
+	// An optional function called on a batch's routine before its operation.
+	Setup BatchOperation
+
+	// An optional function called on a batch's routine after its operation
+	// finishes, such as flushing the batch's results to disk or a database.
+	Commit BatchOperation
+
+	// The process used to execute batches.
+	process Process
+
+	// The number of iterations in each batch.
+	batchSize int
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// MARK: Initializers
+
+// NewBatchProcess creates and returns a new batch process that executes
+// batches of batchSize iterations on the given process.
+func NewBatchProcess(process Process, batchSize int) *BatchProcess {
+	if process == nil {
+		misuse("NewBatchProcess called with a nil process")
+	}
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	return &BatchProcess{
+		process:    process,
+		batchSize:  batchSize,
+		throughput: newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+}
+
+// MARK: Public methods
+
+// Execute executes the batch process for the specified number of operations,
+// calling the operation for each index of every batch.
+func (p *BatchProcess) Execute(iterations int, operation Operation) {
+	checkOperation("BatchProcess.Execute", operation == nil)
+	p.ExecuteBatches(iterations, func(batch int, start int, end int) {
+		for i := start; i < end; i++ {
+			operation(i)
+		}
+	})
+}
+
+// ExecuteContext executes the batch process for the specified number of
+// operations, passing ctx to each operation. The process stops when ctx is done
+// and the context's error is returned.
+func (p *BatchProcess) ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error {
+	checkOperation("BatchProcess.ExecuteContext", operation == nil)
+	return executeContext(ctx, p, iterations, operation)
+}
+
+// ExecuteBatches executes the batch operation once for each batch of the
+// specified number of iterations. The last batch contains the remaining
+// iterations and may be smaller than the batch size.
+func (p *BatchProcess) ExecuteBatches(iterations int, operation BatchOperation) {
+	checkIterations("BatchProcess.ExecuteBatches", iterations)
+	checkOperation("BatchProcess.ExecuteBatches", operation == nil)
+
+	if iterations <= 0 {
+		return
+	}
+
+	atomic.StoreInt64(&p.iterations, int64(iterations))
+	atomic.StoreInt64(&p.completed, 0)
+
+	numBatches := (iterations + p.batchSize - 1) / p.batchSize
+	p.process.Execute(numBatches, func(batch int) {
+		start := batch * p.batchSize
+		end := start + p.batchSize
+		if end > iterations {
+			end = iterations
+		}
+
+		if p.Setup != nil {
+			p.Setup(batch, start, end)
+		}
+
+		operation(batch, start, end)
+
+		if p.Commit != nil {
+			p.Commit(batch, start, end)
+		}
+
+		atomic.AddInt64(&p.completed, int64(end-start))
+		p.throughput.Add(end - start)
+	})
+}
+
+// Stop stops the batch process after all of the current batches have finished
+// executing.
+func (p *BatchProcess) Stop() {
+	p.process.Stop()
+}
+
+// NumRoutines returns the number of routines that the underlying process is
+// using.
+func (p *BatchProcess) NumRoutines() int {
+	return p.process.NumRoutines()
+}
+
+// Stats returns a snapshot of the current or last execution. Iterations
+// complete when their batch's operation and commit have returned.
+func (p *BatchProcess) Stats() ProcessStats {
+	stats := p.process.Stats()
+	stats.Completed = atomic.LoadInt64(&p.completed)
+	stats.Iterations = atomic.LoadInt64(&p.iterations)
+	return stats
+}
+
+// BatchSize returns the number of iterations in each batch.
+func (p *BatchProcess) BatchSize() int {
+	return p.batchSize
+}
+
+// Throughput returns the meter measuring the number of iterations the process
+// completes per second.
+func (p *BatchProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private methods
+
+// wasStopped returns whether or not the underlying process' last execution was
+// stopped.
+func (p *BatchProcess) wasStopped() bool {
+	return processStopped(p.process)
+}
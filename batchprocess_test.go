@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestBatchProcessCompleteness(t *testing.T) {
+	v := make([]float64, 1000001)
+	p := NewBatchProcess(NewFixedProcess(4), 1000)
+	p.Execute(len(v), func(i int) {
+		v[i] = float64(i + 1)
+	})
+
+	for i, value := range v {
+		if float64(i+1) != value {
+			t.Errorf("Value, %f, should be equal to %f.", value, float64(i+1))
+			break
+		}
+	}
+}
+
+func TestBatchProcessCallbacks(t *testing.T) {
+	var mutex sync.Mutex
+	setup := make(map[int]bool)
+	committed := make(map[int][2]int)
+
+	p := NewBatchProcess(NewFixedProcess(2), 10)
+	p.Setup = func(batch int, start int, end int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		setup[batch] = true
+	}
+	p.Commit = func(batch int, start int, end int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		committed[batch] = [2]int{start, end}
+	}
+
+	p.ExecuteBatches(95, func(batch int, start int, end int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !setup[batch] {
+			t.Errorf("Batch %d executed before its setup.", batch)
+		}
+	})
+
+	if len(committed) != 10 {
+		t.Fatalf("Committed batch count, %d, should be 10.", len(committed))
+	}
+
+	if committed[9] != [2]int{90, 95} {
+		t.Errorf("Last batch, %v, should span [90, 95).", committed[9])
+	}
+}
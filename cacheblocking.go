@@ -0,0 +1,97 @@
+package parallel
+
+import (
+	"math"
+	"runtime"
+)
+
+// The number of bytes of data ExecuteBlocked and ExecuteGridBlocked keep in
+// each block, the size of a typical L1 data cache.
+const cacheBlockSize = 32 << 10
+
+// MARK: Public functions
+
+// ExecuteBlocked executes the operation on process once for every index of a
+// one dimensional iteration space of length elements, each elementSize bytes
+// large.
+//
+// Consecutive indices are grouped into blocks that are executed in parallel.
+// Indices within a block are visited in order by the same routine. Blocks hold
+// at most a cache's worth of elements, and are smaller if that's what it
+// takes to give each of the process' routines several to balance the load.
+func ExecuteBlocked(process Process, length int, elementSize int, operation Operation) {
+	checkIterations("ExecuteBlocked", length)
+	checkOperation("ExecuteBlocked", operation == nil)
+	checkElementSize("ExecuteBlocked", elementSize)
+	if length == 0 {
+		return
+	}
+
+	balanced := balancedBlocks(process)
+	size := maxInt(1, cacheBlockSize/elementSize)
+	size = minInt(size, (length+balanced-1)/balanced)
+	blocks := (length + size - 1) / size
+
+	process.Execute(blocks, func(i int) {
+		end := minInt((i+1)*size, length)
+		for j := i * size; j < end; j++ {
+			operation(j)
+		}
+	})
+}
+
+// ExecuteGridBlocked executes the operation once for every cell of a width by
+// height grid on process, where the grid is stored in row-major order with
+// cells elementSize bytes large.
+//
+// The grid is divided into tiles that each hold at most a cache's worth of
+// cells, and are smaller if that's what it takes to give each of the process'
+// routines several to balance the load. Tiles are as close to square as
+// possible with their width a multiple of a cache line, so that each row of a
+// tile reads whole cache lines.
+func ExecuteGridBlocked(process Process, width int, height int, elementSize int, operation GridOperation) {
+	checkOperation("ExecuteGridBlocked", operation == nil)
+	checkElementSize("ExecuteGridBlocked", elementSize)
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	tileWidth, tileHeight := cacheTileSize(width, height, elementSize, balancedBlocks(process))
+	ExecuteGridTiled(process, width, height, tileWidth, tileHeight, operation)
+}
+
+// MARK: Private functions
+
+// cacheTileSize returns the width and height of the tiles ExecuteGridBlocked
+// divides a grid into so that it has at least blocks tiles.
+func cacheTileSize(width int, height int, elementSize int, blocks int) (int, int) {
+	area := maxInt(1, cacheBlockSize/elementSize)
+	area = minInt(area, maxInt(1, width*height/blocks))
+
+	lineCells := maxInt(1, cacheLineSize/elementSize)
+	tileWidth := int(math.Sqrt(float64(area)))
+	tileWidth = maxInt(lineCells, tileWidth/lineCells*lineCells)
+	tileWidth = minInt(tileWidth, width)
+	tileHeight := clampTileSize(area/tileWidth, height)
+	return tileWidth, tileHeight
+}
+
+// balancedBlocks returns the number of blocks an iteration space should be
+// divided into to give each of process' routines, or each CPU if there are
+// more, several to balance the load.
+func balancedBlocks(process Process) int {
+	// Variable processes report no routines until they execute, so size the
+	// blocks for at least as many routines as there are CPUs.
+	routines := process.NumRoutines()
+	if cpus := runtime.NumCPU(); routines < cpus {
+		routines = cpus
+	}
+	return windowBlocksPerRoutine * routines
+}
+
+// checkElementSize panics if an element size isn't positive.
+func checkElementSize(method string, elementSize int) {
+	if elementSize < 1 {
+		misuse("%s called with an element size of %d bytes; the size must be positive", method, elementSize)
+	}
+}
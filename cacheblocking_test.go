@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestExecuteBlocked(t *testing.T) {
+	for _, length := range []int{0, 1, 7, 100000} {
+		counts := make([]int32, length)
+		ExecuteBlocked(NewFixedProcess(4), length, 8, func(i int) {
+			atomic.AddInt32(&counts[i], 1)
+		})
+
+		for i, count := range counts {
+			if count != 1 {
+				t.Fatalf("Index %d of %d executed %d times.", i, length, count)
+			}
+		}
+	}
+}
+
+func TestExecuteGridBlocked(t *testing.T) {
+	width, height := 300, 70
+	counts := make([]int32, width*height)
+	ExecuteGridBlocked(NewFixedProcess(4), width, height, 4, func(x int, y int) {
+		atomic.AddInt32(&counts[y*width+x], 1)
+	})
+
+	for i, count := range counts {
+		if count != 1 {
+			t.Fatalf("Cell (%d, %d) executed %d times.", i%width, i/width, count)
+		}
+	}
+}
+
+func TestCacheTileSize(t *testing.T) {
+	tests := []struct {
+		width, height, elementSize, blocks int
+		tileWidth, tileHeight              int
+	}{
+		{1024, 1024, 8, 4, 64, 64},
+		{100, 100, 8, 16, 24, 26},
+		{1024, 1024, 128, 4, 16, 16},
+		{4, 1000, 1, 1, 4, 1000},
+		{10, 10, 1 << 20, 1, 1, 1},
+	}
+
+	for _, test := range tests {
+		w, h := cacheTileSize(test.width, test.height, test.elementSize, test.blocks)
+		if w != test.tileWidth || h != test.tileHeight {
+			t.Errorf("Tiles of a %dx%d grid of %d byte cells should be %dx%d, not %dx%d.", test.width, test.height, test.elementSize, test.tileWidth, test.tileHeight, w, h)
+		}
+	}
+}
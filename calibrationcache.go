@@ -0,0 +1,316 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The time a process waits for another process to release a calibration cache
+// file's lock, and the age after which a lock is considered abandoned.
+const (
+	calibrationLockTimeout = 5 * time.Second
+	calibrationLockStale   = 30 * time.Second
+)
+
+// CalibrationEntry types contain the calibration results of a single
+// workload.
+type CalibrationEntry struct {
+	// The fingerprint identifying the workload.
+	Workload string `json:"workload"`
+
+	// The optimal number of goroutines measured for the workload.
+	Routines int `json:"routines"`
+
+	// The overhead of claiming a single iteration measured by a fixed
+	// process' adaptive schedule, or zero.
+	ClaimOverhead time.Duration `json:"claimOverhead,omitempty"`
+
+	// The state of the controller where the workload converged, or nil.
+	Controller *PIDState `json:"controller,omitempty"`
+
+	// The time the entry was last updated.
+	Updated time.Time `json:"updated"`
+}
+
+// CalibrationCache types store calibration results per workload in a file so
+// that repeated runs on the same host can skip recalibration.
+//
+// A cache file records the host it was written on. Entries loaded from a file
+// written by a different host (a different CPU count, OS or architecture) are
+// discarded.
+//
+// Processes can share a cache file. Changes are made under a lock file next to
+// the cache file and are applied to the file's current contents, so entries
+// written by other processes aren't lost.
+type CalibrationCache struct {
+	// The path of the cache file.
+	path string
+
+	// A mutex to protect the entries.
+	mutex sync.Mutex
+
+	// The cached entries keyed by workload.
+	entries map[string]CalibrationEntry
+}
+
+// calibrationHost types identify the host a calibration cache was written on.
+type calibrationHost struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	CPUCount int    `json:"cpuCount"`
+}
+
+// calibrationFile types represent the contents of a calibration cache file.
+type calibrationFile struct {
+	Host    calibrationHost    `json:"host"`
+	Entries []CalibrationEntry `json:"entries"`
+}
+
+// MARK: Initializers
+
+// NewCalibrationCache creates and returns a new calibration cache backed by the
+// file at path, loading any entries it contains. A missing file is not an
+// error.
+func NewCalibrationCache(path string) (*CalibrationCache, error) {
+	entries, err := readCalibrationFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CalibrationCache{
+		path:    path,
+		entries: entries,
+	}, nil
+}
+
+// DefaultCalibrationCachePath returns the path of the calibration cache file
+// in the user's cache directory.
+func DefaultCalibrationCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "parallel", "calibration.json"), nil
+}
+
+// MARK: Public methods
+
+// Path returns the path of the cache file.
+func (c *CalibrationCache) Path() string {
+	return c.path
+}
+
+// Get returns the entry for workload and whether or not it exists.
+func (c *CalibrationCache) Get(workload string) (CalibrationEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[workload]
+	return entry, ok
+}
+
+// Set stores entry, replacing any entry for the same workload, and writes the
+// cache file. If the entry's update time is zero it is set to the current
+// time.
+func (c *CalibrationCache) Set(entry CalibrationEntry) error {
+	if entry.Updated.IsZero() {
+		entry.Updated = time.Now()
+	}
+
+	return c.update(func(entries map[string]CalibrationEntry) {
+		entries[entry.Workload] = entry
+	})
+}
+
+// Entries returns every cached entry sorted by workload.
+func (c *CalibrationCache) Entries() []CalibrationEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.sortedEntries()
+}
+
+// Invalidate removes the entry for workload and writes the cache file.
+func (c *CalibrationCache) Invalidate(workload string) error {
+	return c.update(func(entries map[string]CalibrationEntry) {
+		delete(entries, workload)
+	})
+}
+
+// Clear removes every entry and deletes the cache file.
+func (c *CalibrationCache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	unlock, err := lockCalibrationFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c.entries = make(map[string]CalibrationEntry)
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// MARK: Private methods
+
+// sortedEntries returns the cached entries sorted by workload. The caller must
+// hold the cache's mutex.
+func (c *CalibrationCache) sortedEntries() []CalibrationEntry {
+	entries := make([]CalibrationEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Workload < entries[j].Workload
+	})
+
+	return entries
+}
+
+// update re-reads the cache file under its lock, applies change to its
+// entries, and writes the result to the file.
+func (c *CalibrationCache) update(change func(entries map[string]CalibrationEntry)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	unlock, err := lockCalibrationFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := readCalibrationFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	change(entries)
+	c.entries = entries
+	return c.save()
+}
+
+// save atomically writes the cache file. The caller must hold the cache's
+// mutex and the file's lock.
+func (c *CalibrationCache) save() error {
+	data, err := json.MarshalIndent(calibrationFile{
+		Host:    currentCalibrationHost(),
+		Entries: c.sortedEntries(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(c.path, data)
+}
+
+// MARK: Private functions
+
+// readCalibrationFile returns the entries of the cache file at path keyed by
+// workload. A missing file or a file written by a different host has no
+// entries.
+func readCalibrationFile(path string) (map[string]CalibrationEntry, error) {
+	entries := make(map[string]CalibrationEntry)
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file calibrationFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Host == currentCalibrationHost() {
+		for _, entry := range file.Entries {
+			entries[entry.Workload] = entry
+		}
+	}
+
+	return entries, nil
+}
+
+// lockCalibrationFile takes the lock of the cache file at path by creating a
+// lock file next to it, and returns a function that releases the lock. Locks
+// older than calibrationLockStale were abandoned by a process that exited and
+// are broken.
+func lockCalibrationFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(calibrationLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(lockPath)
+			}, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > calibrationLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("parallel: timed out waiting for the calibration cache lock %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeFileAtomically writes data to a temporary file in the directory of path
+// and renames it to path, so that readers never observe a partial file.
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// currentCalibrationHost returns the identity of the current host.
+func currentCalibrationHost() calibrationHost {
+	return calibrationHost{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		CPUCount: runtime.NumCPU(),
+	}
+}
@@ -0,0 +1,232 @@
+package parallel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestCalibrationCachePersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cache", "calibration.json")
+	c, err := NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err = c.Set(CalibrationEntry{Workload: "sqrt", Routines: 6, ClaimOverhead: time.Microsecond}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.Set(CalibrationEntry{Workload: "fft", Routines: 3})
+
+	c, err = NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entry, ok := c.Get("sqrt")
+	if !ok || entry.Routines != 6 || entry.ClaimOverhead != time.Microsecond {
+		t.Errorf("Entry, %v, should have been loaded from the cache file.", entry)
+	}
+
+	if len(c.Entries()) != 2 {
+		t.Errorf("Entry count, %d, should be 2.", len(c.Entries()))
+	}
+
+	c.Invalidate("sqrt")
+	if _, ok = c.Get("sqrt"); ok {
+		t.Errorf("An invalidated entry should not be returned.")
+	}
+
+	if err = c.Clear(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Clearing the cache should remove its file.")
+	}
+}
+
+func TestCalibrationCacheHostMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "calibration.json")
+	data := `{"host":{"os":"plan9","arch":"mips","cpuCount":1},"entries":[{"workload":"sqrt","routines":6}]}`
+	ioutil.WriteFile(path, []byte(data), 0644)
+
+	c, err := NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, ok := c.Get("sqrt"); ok {
+		t.Errorf("Entries from another host should be discarded.")
+	}
+}
+
+func TestCalibrationCacheSharedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Caches opened before each other's writes shouldn't drop those entries.
+	path := filepath.Join(dir, "calibration.json")
+	caches := make([]*CalibrationCache, 8)
+	for i := range caches {
+		if caches[i], err = NewCalibrationCache(path); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	var group sync.WaitGroup
+	for i, c := range caches {
+		group.Add(1)
+		go func(i int, c *CalibrationCache) {
+			defer group.Done()
+			if err := c.Set(CalibrationEntry{Workload: fmt.Sprintf("w%d", i), Routines: i + 1}); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}(i, c)
+	}
+	group.Wait()
+
+	if err = caches[0].Invalidate("w1"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c, err := NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(c.Entries()) != len(caches)-1 {
+		t.Errorf("Entry count, %d, should be %d.", len(c.Entries()), len(caches)-1)
+	}
+
+	if _, ok := c.Get("w1"); ok {
+		t.Errorf("An invalidated entry should not be returned.")
+	}
+
+	if _, err = os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("The cache file's lock should have been released.")
+	}
+}
+
+func TestVariableProcessCalibrationCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "calibration.json")
+	c, err := NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.Set(CalibrationEntry{Workload: "cached", Routines: 3, Controller: &PIDState{Integral: 2.0}})
+	c.Set(CalibrationEntry{Workload: "learned", ClaimOverhead: time.Microsecond})
+
+	configuration := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, configuration, false)
+	p.SetCalibrationCache(c, "cached")
+	if n := p.startingRoutines(); n != 3 {
+		t.Errorf("The process should start with the cached 3 routines, not %d.", n)
+	}
+	if state := p.ControllerState(); state.Integral != 2.0 {
+		t.Errorf("The controller's integral, %f, should be restored from the cache.", state.Integral)
+	}
+
+	p = NewVariableProcess(time.Millisecond, 1, 8, configuration, false)
+	p.SetCalibrationCache(c, "learned")
+	p.Execute(200, func(i int) {
+		time.Sleep(100 * time.Microsecond)
+	})
+
+	c, err = NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entry, ok := c.Get("learned")
+	if !ok || entry.Routines < 1 || entry.Controller == nil {
+		t.Fatalf("The converged state, %v, should have been stored in the cache.", entry)
+	}
+	if entry.Routines != p.convergedRoutines {
+		t.Errorf("The cached routines, %d, should be the converged %d.", entry.Routines, p.convergedRoutines)
+	}
+	if entry.ClaimOverhead != time.Microsecond {
+		t.Errorf("The cached claim overhead, %s, should be kept.", entry.ClaimOverhead)
+	}
+
+	if entry, _ = c.Get("cached"); entry.Routines != 3 {
+		t.Error("Other workloads' entries should be kept.")
+	}
+}
+
+func TestFixedProcessCalibrationCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "calibration.json")
+	c, err := NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.Set(CalibrationEntry{Workload: "sqrt", Routines: 6})
+
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleAdaptive)
+	p.SetCalibrationCache(c, "sqrt")
+	p.Execute(1000, func(i int) {
+		time.Sleep(10 * time.Microsecond)
+	})
+
+	if s := p.Summary(); s.Errors.Count != 0 {
+		t.Fatalf("Unexpected errors: %v", s.Errors.Messages)
+	}
+
+	c, err = NewCalibrationCache(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entry, ok := c.Get("sqrt")
+	if !ok || entry.ClaimOverhead <= 0 {
+		t.Fatalf("The measured claim overhead, %v, should have been stored in the cache.", entry)
+	}
+	if entry.Routines != 6 {
+		t.Errorf("The cached routines, %d, should be kept.", entry.Routines)
+	}
+
+	c.Set(CalibrationEntry{Workload: "sqrt", ClaimOverhead: time.Second})
+	p.SetCalibrationCache(c, "sqrt")
+	if overhead := p.cachedClaimOverhead(); overhead != time.Second {
+		t.Errorf("The cached claim overhead, %s, should be %s.", overhead, time.Second)
+	}
+
+	s := newAdaptiveScheduler(1000, 4, defaultOverheadTarget, p.cachedClaimOverhead())
+	if overhead := s.claimOverhead(); overhead != time.Second {
+		t.Errorf("The scheduler's claim overhead, %s, should be seeded from the cache.", overhead)
+	}
+}
@@ -0,0 +1,112 @@
+package parallel
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CheckpointWriter types persist a checkpoint of an execution. Responders
+// should record progress so that the execution can be resumed later.
+type CheckpointWriter func(progress Progress) error
+
+// checkpointBarrier types pause the routines of an execution at iteration
+// boundaries so that a consistent checkpoint can be taken.
+//
+// Routines call wait before claiming iterations. The call is a single atomic
+// load unless a checkpoint has been requested, in which case the routine parks
+// until the checkpoint has been written.
+type checkpointBarrier struct {
+	// Set to 1 while a checkpoint is being taken.
+	paused int32
+
+	// A mutex to protect the routine counts and serialize checkpoints.
+	mutex sync.Mutex
+
+	// Signaled when routines park, leave, or are resumed.
+	cond *sync.Cond
+
+	// The number of routines that have entered the barrier and not left it.
+	active int
+
+	// The number of routines parked at the barrier.
+	parked int
+
+	// A mutex held while a checkpoint is being taken.
+	checkpointMutex sync.Mutex
+}
+
+// MARK: Initializers
+
+// newCheckpointBarrier creates and returns a new checkpoint barrier.
+func newCheckpointBarrier() *checkpointBarrier {
+	b := &checkpointBarrier{}
+	b.cond = sync.NewCond(&b.mutex)
+	return b
+}
+
+// MARK: Private methods
+
+// enter registers a routine with the barrier. Routines that enter while a
+// checkpoint is being taken wait for it to finish.
+func (b *checkpointBarrier) enter() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for atomic.LoadInt32(&b.paused) == 1 {
+		b.cond.Wait()
+	}
+	b.active++
+}
+
+// leave unregisters a routine from the barrier.
+func (b *checkpointBarrier) leave() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.active--
+	b.cond.Broadcast()
+}
+
+// wait parks the calling routine while a checkpoint is being taken. The
+// routine's progress is flushed before it parks so that the checkpoint
+// observes it.
+func (b *checkpointBarrier) wait(progress *routineProgress) {
+	if atomic.LoadInt32(&b.paused) == 0 {
+		return
+	}
+
+	progress.flush()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.parked++
+	b.cond.Broadcast()
+	for atomic.LoadInt32(&b.paused) == 1 {
+		b.cond.Wait()
+	}
+	b.parked--
+}
+
+// checkpoint pauses every registered routine at its next iteration boundary,
+// calls writer with the snapshot of counter, then resumes the routines.
+func (b *checkpointBarrier) checkpoint(counter *progressCounter, writer CheckpointWriter) error {
+	b.checkpointMutex.Lock()
+	defer b.checkpointMutex.Unlock()
+
+	b.mutex.Lock()
+	atomic.StoreInt32(&b.paused, 1)
+	for b.parked < b.active {
+		b.cond.Wait()
+	}
+	b.mutex.Unlock()
+
+	err := writer(counter.snapshot())
+
+	b.mutex.Lock()
+	atomic.StoreInt32(&b.paused, 0)
+	b.cond.Broadcast()
+	b.mutex.Unlock()
+
+	return err
+}
@@ -0,0 +1,133 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessCheckpoint(t *testing.T) {
+	p := NewFixedProcess(4)
+	testCheckpoint(t, p, p.CheckpointNow)
+}
+
+func TestVariableProcessCheckpoint(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 2, 8, c, false)
+	testCheckpoint(t, p, p.CheckpointNow)
+}
+
+func TestVariableProcessCheckpointRemovingRoutines(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(100*time.Microsecond, 8, 8, c, false)
+
+	var completed int64
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		p.Execute(3000, func(i int) {
+			time.Sleep(10 * time.Microsecond)
+			atomic.AddInt64(&completed, 1)
+		})
+	}()
+
+	checkpoints := 0
+	for {
+		select {
+		case <-finished:
+			if checkpoints == 0 {
+				t.Errorf("No checkpoints were taken.")
+			}
+			return
+		default:
+		}
+
+		// Alternate the maximum so that the optimizer keeps removing routines
+		// while checkpoints are taken.
+		if checkpoints%2 == 0 {
+			p.SetMaxRoutines(1)
+		} else {
+			p.SetMaxRoutines(8)
+		}
+
+		err := p.CheckpointNow(func(progress Progress) error {
+			if n := atomic.LoadInt64(&completed); progress.Completed != n {
+				t.Errorf("Completed iterations, %d, should be %d.", progress.Completed, n)
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Error, %s, should be nil.", err)
+		}
+		checkpoints++
+		time.Sleep(200 * time.Microsecond)
+	}
+}
+
+func TestCheckpointIdle(t *testing.T) {
+	p := NewFixedProcess(2)
+	p.Execute(100, func(i int) {})
+
+	err := p.CheckpointNow(func(progress Progress) error {
+		if progress.Completed != 100 {
+			t.Errorf("Completed iterations, %d, should be 100.", progress.Completed)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Error, %s, should be nil.", err)
+	}
+}
+
+// MARK: Helpers
+
+// testCheckpoint takes checkpoints while p executes and verifies that every
+// checkpoint is consistent with the iterations that have executed.
+func testCheckpoint(t *testing.T, p Process, checkpoint func(CheckpointWriter) error) {
+	done := make([]int32, 5000)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		p.Execute(len(done), func(i int) {
+			atomic.StoreInt32(&done[i], 1)
+			time.Sleep(10 * time.Microsecond)
+		})
+	}()
+
+	checkpoints := 0
+	for {
+		select {
+		case <-finished:
+			if checkpoints == 0 {
+				t.Errorf("No checkpoints were taken.")
+			}
+			return
+		default:
+		}
+
+		err := checkpoint(func(progress Progress) error {
+			if progress.Discrepancy() != 0 {
+				t.Errorf("Progress, %+v, should not have a discrepancy.", progress)
+			}
+
+			for i := 0; i < len(done); i++ {
+				if (int64(i) < progress.Completed) != (atomic.LoadInt32(&done[i]) == 1) {
+					t.Fatalf("Iteration %d is inconsistent with progress %+v.", i, progress)
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Error, %s, should be nil.", err)
+		}
+		checkpoints++
+		time.Sleep(time.Millisecond)
+	}
+}
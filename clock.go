@@ -0,0 +1,227 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock types tell time and create tickers for variable processes. The system
+// clock is used unless another is set, and a manual clock lets tests drive a
+// process' optimizations deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a new ticker that ticks every interval d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker types deliver ticks at intervals.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker. No more ticks are delivered, but the channel
+	// isn't closed.
+	Stop()
+}
+
+// SystemClock types tell time with the time package.
+type SystemClock struct{}
+
+// systemTicker types wrap a time.Ticker.
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// ManualClock types only move forward when they're advanced. Tickers created by
+// a manual clock tick as the clock passes their intervals.
+type ManualClock struct {
+	// A mutex to protect the time and tickers.
+	mutex sync.Mutex
+
+	// The clock's current time.
+	now time.Time
+
+	// The tickers that haven't been stopped.
+	tickers []*manualTicker
+}
+
+// handlingTicker types are tickers whose receiver reports when it has
+// finished handling each tick.
+type handlingTicker interface {
+	Ticker
+
+	// awaitHandling makes the ticker wait for each tick to be handled before
+	// delivering the next. It must be called before the first tick.
+	awaitHandling()
+
+	// handled reports that a tick has been handled.
+	handled()
+}
+
+// manualTicker types are the tickers of a manual clock.
+type manualTicker struct {
+	// The clock the ticker belongs to.
+	clock *ManualClock
+
+	// The channel ticks are delivered on.
+	c chan time.Time
+
+	// If non-nil, the channel the receiver reports handled ticks on.
+	handledTicks chan struct{}
+
+	// Closed once when the ticker is stopped.
+	stopped  chan struct{}
+	stopOnce sync.Once
+
+	// The ticker's interval and the time of its next tick.
+	interval time.Duration
+	next     time.Time
+}
+
+// MARK: Initializers
+
+// NewManualClock creates and returns a new manual clock set to now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// MARK: Public methods
+
+// Now returns the current time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a new time.Ticker that ticks every interval d.
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// NewTicker returns a new ticker that ticks each time the clock is advanced
+// past a multiple of d. The interval must be positive.
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	checkInterval("ManualClock.NewTicker", d)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &manualTicker{
+		clock:    c,
+		c:        make(chan time.Time),
+		stopped:  make(chan struct{}),
+		interval: d,
+		next:     c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d. Each tick the clock passes is
+// delivered in order with the clock set to the tick's time, and Advance
+// blocks until every tick has been received or its ticker has been stopped.
+// The ticks of a variable process' ticker are also waited on until the
+// process has finished optimizing, so once Advance returns, every optimization
+// the ticks triggered has run.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	for {
+		t := c.nextTicker(end)
+		if t == nil {
+			break
+		}
+
+		tick := t.next
+		t.next = t.next.Add(t.interval)
+		c.now = tick
+		handledTicks := t.handledTicks
+
+		// Deliver the tick without holding the mutex, so the receiver may
+		// read the clock.
+		c.mutex.Unlock()
+		select {
+		case t.c <- tick:
+			if handledTicks != nil {
+				select {
+				case <-handledTicks:
+				case <-t.stopped:
+				}
+			}
+		case <-t.stopped:
+		}
+		c.mutex.Lock()
+	}
+	c.now = end
+	c.mutex.Unlock()
+}
+
+// C returns the channel the ticks are delivered on.
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns off the ticker.
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// C returns the channel the ticks are delivered on.
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop turns off the ticker.
+func (t *manualTicker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopped)
+	})
+}
+
+// MARK: Private methods
+
+// awaitHandling makes the ticker wait for each tick to be handled before the
+// clock moves on.
+func (t *manualTicker) awaitHandling() {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	t.handledTicks = make(chan struct{})
+}
+
+// handled reports that a tick has been handled.
+func (t *manualTicker) handled() {
+	select {
+	case t.handledTicks <- struct{}{}:
+	case <-t.stopped:
+	}
+}
+
+// nextTicker returns the running ticker whose next tick is the earliest at or
+// before end, removing stopped tickers, or nil if no ticker ticks by end. The
+// mutex must be held.
+func (c *ManualClock) nextTicker(end time.Time) *manualTicker {
+	var next *manualTicker
+	running := c.tickers[:0]
+	for _, t := range c.tickers {
+		select {
+		case <-t.stopped:
+			continue
+		default:
+		}
+
+		running = append(running, t)
+		if !t.next.After(end) && (next == nil || t.next.Before(next.next)) {
+			next = t
+		}
+	}
+	c.tickers = running
+	return next
+}
@@ -0,0 +1,163 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestManualClockTicks(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewManualClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	ticks := make(chan time.Time, 10)
+	go func() {
+		for tick := range ticker.C() {
+			ticks <- tick
+		}
+	}()
+
+	clock.Advance(3500 * time.Millisecond)
+	if now := clock.Now(); !now.Equal(start.Add(3500 * time.Millisecond)) {
+		t.Errorf("Time, %s, should be 3.5s after the start.", now)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if tick := <-ticks; !tick.Equal(start.Add(time.Duration(i) * time.Second)) {
+			t.Errorf("Tick %d, %s, should be %ds after the start.", i, tick, i)
+		}
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case tick := <-ticks:
+		t.Errorf("A stopped ticker delivered a tick at %s.", tick)
+	default:
+	}
+}
+
+func TestVariableProcessManualClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewManualClock(start)
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Second, 1, 4, c, false)
+	p.SetClock(clock)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Execute(10, func(i int) {
+			<-release
+		})
+		close(done)
+	}()
+
+	// Wait for the execution to start its ticker.
+	for p.NumRoutines() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(3 * time.Second)
+	samples := p.UsageHistory()
+	if len(samples) != 3 {
+		t.Fatalf("History length, %d, should be 3.", len(samples))
+	}
+
+	for i, sample := range samples {
+		if !sample.Time.Equal(start.Add(time.Duration(i+1) * time.Second)) {
+			t.Errorf("Sample %d time, %s, should be %ds after the start.", i, sample.Time, i+1)
+		}
+
+		if sample.Interval != time.Second {
+			t.Errorf("Sample %d interval, %s, should be 1s.", i, sample.Interval)
+		}
+	}
+
+	close(release)
+	<-done
+}
+
+func TestVariableProcessUsageSamples(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewManualClock(start)
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Second, 1, 4, c, false)
+	p.SetClock(clock)
+	p.SetUsageSamples(4)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Execute(10, func(i int) {
+			<-release
+		})
+		close(done)
+	}()
+
+	for p.NumRoutines() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Three of the four samples don't optimize.
+	clock.Advance(750 * time.Millisecond)
+	if samples := p.UsageHistory(); len(samples) != 0 {
+		t.Errorf("History, %+v, should be empty before the interval is over.", samples)
+	}
+
+	clock.Advance(250 * time.Millisecond)
+	samples := p.UsageHistory()
+	if len(samples) != 1 {
+		t.Fatalf("History length, %d, should be 1 after one interval.", len(samples))
+	}
+
+	if samples[0].Interval != time.Second {
+		t.Errorf("Sample interval, %s, should be the optimization interval.", samples[0].Interval)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestVariableProcessWarmUp(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(time.Second, 1, 8, c, false)
+	p.SetClock(clock)
+	p.SetWarmUp(2 * time.Second)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Execute(100, func(i int) {
+			<-release
+		})
+		close(done)
+	}()
+
+	for p.NumRoutines() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Second)
+	if n := p.NumRoutines(); n != 1 {
+		t.Errorf("Routines, %d, should be held at 1 while warming up.", n)
+	}
+
+	if samples := p.UsageHistory(); len(samples) != 1 {
+		t.Errorf("History length, %d, should be 1 while warming up.", len(samples))
+	}
+
+	clock.Advance(time.Second)
+	if n := p.NumRoutines(); n <= 1 {
+		t.Errorf("Routines, %d, should be added once the process has warmed up.", n)
+	}
+
+	close(release)
+	<-done
+}
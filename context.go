@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ContextOperation types represent a single operation in a parallel process
+// that receives the context of its execution. Responders should perform the
+// i-th operation.
+//
+// If the execution's context has a deadline, operations can use
+// ctx.Deadline to find the time remaining in the execution.
+type ContextOperation func(ctx context.Context, i int)
+
+// contextExecution types pass the context of an execution to its operations
+// and stop the executing process once the context is done.
+type contextExecution struct {
+	// The execution's context.
+	ctx context.Context
+
+	// Stops the execution.
+	stop func()
+
+	// The operation to call for each iteration.
+	operation ContextOperation
+
+	// The execution's deadline and whether it has one.
+	deadline    time.Time
+	hasDeadline bool
+
+	// Set to 1 once an iteration has been skipped because it could not start
+	// before the deadline.
+	expired int32
+}
+
+// MARK: Private functions
+
+// executeContext executes operation on process for the specified number of
+// iterations, passing ctx to every invocation, and returns the context's error.
+//
+// Every routine of a process, including routines spawned by an optimizer after
+// execution has begun, calls the same wrapped operation, so no invocation can
+// observe a context other than ctx.
+//
+// When ctx has a deadline, each iteration is passed a context derived from ctx
+// with the same deadline, which is canceled as soon as the iteration returns.
+// Iterations that can't start before the deadline are skipped and
+// context.DeadlineExceeded is returned.
+func executeContext(ctx context.Context, process Process, iterations int, operation ContextOperation) error {
+	return runContext(ctx, func(op Operation) {
+		process.Execute(iterations, op)
+	}, process.Stop, operation)
+}
+
+// runContext calls execute with an operation that passes ctx to operation, and
+// calls stop once ctx is done. It behaves like executeContext for executions
+// that can be stopped individually.
+func runContext(ctx context.Context, execute func(Operation), stop func(), operation ContextOperation) error {
+	e := &contextExecution{
+		ctx:       ctx,
+		stop:      stop,
+		operation: operation,
+	}
+	e.deadline, e.hasDeadline = ctx.Deadline()
+
+	execute(e.execute)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if atomic.LoadInt32(&e.expired) == 1 {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// MARK: Private methods
+
+// execute performs the i-th iteration of the execution.
+func (e *contextExecution) execute(i int) {
+	if !e.hasDeadline {
+		if e.ctx.Err() != nil {
+			e.stop()
+			return
+		}
+		e.operation(e.ctx, i)
+		return
+	}
+
+	// No iteration can start once the deadline has passed, so the remaining
+	// iterations are skipped by stopping the process.
+	if !time.Now().Before(e.deadline) || e.ctx.Err() != nil {
+		atomic.StoreInt32(&e.expired, 1)
+		e.stop()
+		return
+	}
+
+	ctx, cancel := context.WithDeadline(e.ctx, e.deadline)
+	defer cancel()
+	e.operation(ctx, i)
+}
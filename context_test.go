@@ -0,0 +1,161 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// contextKey is the type of the context values used by the tests.
+type contextKey string
+
+// MARK: Tests
+
+func TestFixedProcessContextValues(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey("request"), "abc")
+
+	var stale int64
+	p := NewFixedProcess(4)
+	err := p.ExecuteContext(ctx, 10000, func(ctx context.Context, i int) {
+		if ctx.Value(contextKey("request")) != "abc" {
+			atomic.AddInt64(&stale, 1)
+		}
+	})
+
+	if err != nil {
+		t.Errorf("Error, %s, should be nil.", err)
+	}
+
+	if stale != 0 {
+		t.Errorf("Stale context count, %d, should be 0.", stale)
+	}
+}
+
+func TestVariableProcessContextValues(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey("request"), "abc")
+
+	var stale, active, maxActive int64
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(5*time.Millisecond, 1, 8, c, false)
+	err := p.ExecuteContext(ctx, 200, func(ctx context.Context, i int) {
+		n := atomic.AddInt64(&active, 1)
+		defer atomic.AddInt64(&active, -1)
+
+		for {
+			m := atomic.LoadInt64(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt64(&maxActive, m, n) {
+				break
+			}
+		}
+
+		if ctx.Value(contextKey("request")) != "abc" {
+			atomic.AddInt64(&stale, 1)
+		}
+		time.Sleep(time.Millisecond)
+	})
+
+	if err != nil {
+		t.Errorf("Error, %s, should be nil.", err)
+	}
+
+	if maxActive < 2 {
+		t.Errorf("The optimizer should have added routines, but at most %d ran at once.", maxActive)
+	}
+
+	if stale != 0 {
+		t.Errorf("Stale context count, %d, should be 0.", stale)
+	}
+}
+
+func TestExecuteContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var count int64
+	p := NewFixedProcess(2)
+	err := p.ExecuteContext(ctx, 1000000, func(ctx context.Context, i int) {
+		if atomic.AddInt64(&count, 1) == 1000 {
+			cancel()
+		}
+	})
+
+	if err != context.Canceled {
+		t.Errorf("Error, %v, should be %s.", err, context.Canceled)
+	}
+
+	if count >= 1000000 {
+		t.Errorf("The process should have stopped when its context was canceled.")
+	}
+}
+
+func TestExecuteContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	// Each iteration sleeps for at least 50ms on a single routine, so no more
+	// than five can start before the deadline however the routine is scheduled.
+	var started int64
+	p := NewFixedProcess(1)
+	err := p.ExecuteContext(ctx, 100, func(ctx context.Context, i int) {
+		atomic.AddInt64(&started, 1)
+		time.Sleep(50 * time.Millisecond)
+	})
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Error, %v, should be %s.", err, context.DeadlineExceeded)
+	}
+
+	if started > 5 {
+		t.Errorf("Started iterations, %d, should be at most 5.", started)
+	}
+}
+
+func TestExecuteContextSlowIteration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	// A slow first iteration shouldn't stop the iterations that can still start
+	// before the deadline.
+	var started int64
+	p := NewFixedProcess(1)
+	err := p.ExecuteContext(ctx, 1000, func(ctx context.Context, i int) {
+		if atomic.AddInt64(&started, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Error, %v, should be %s.", err, context.DeadlineExceeded)
+	}
+
+	if started < 2 {
+		t.Errorf("Started iterations, %d, should be at least 2.", started)
+	}
+}
+
+func TestExecuteContextIterationContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	contexts := make([]context.Context, 10)
+	p := NewFixedProcess(2)
+	err := p.ExecuteContext(ctx, len(contexts), func(ctx context.Context, i int) {
+		if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+			t.Errorf("Iteration deadline, %s, should be %s.", d, deadline)
+		}
+		contexts[i] = ctx
+	})
+
+	if err != nil {
+		t.Errorf("Error, %s, should be nil.", err)
+	}
+
+	for i, c := range contexts {
+		if c.Err() != context.Canceled {
+			t.Errorf("Iteration %d's context error, %v, should be %s.", i, c.Err(), context.Canceled)
+		}
+	}
+}
@@ -4,31 +4,137 @@ import (
 	"runtime"
 )
 
-// controller types represent a PID controller to control a process.
-type controller struct {
-	previousError  float64
-	totalError     float64
-	previousOutput float64
-	cpuCount       int
-	configuration  *ControllerConfiguration
-}
-
-// newController creates and resturns a new controller.
-func newController(configuration *ControllerConfiguration) *controller {
-	return &controller{
-		cpuCount:      runtime.NumCPU(),
+// PIDController types represent a PID controller. Variable processes use one
+// to control their number of routines, but a controller can drive any process
+// variable towards a setpoint, such as the size of a worker pool.
+//
+// The controller's error is the difference between the setpoint and its input
+// relative to the setpoint, so an input of zero is an error of 1 and an input
+// at the setpoint is an error of 0. PIDController types aren't safe for
+// concurrent use.
+type PIDController struct {
+	previousError      float64
+	totalError         float64
+	previousOutput     float64
+	previousDerivative float64
+	setpoint           float64
+	configuration      *ControllerConfiguration
+}
+
+// PIDState types contain the internal state of a PID controller.
+type PIDState struct {
+	// The filtered error of the last input.
+	Error float64 `json:"error"`
+
+	// The accumulated error of the integral term.
+	Integral float64 `json:"integral"`
+
+	// The filtered change in error of the derivative term.
+	Derivative float64 `json:"derivative"`
+
+	// The last output signal.
+	Output float64 `json:"output"`
+}
+
+// MARK: Initializers
+
+// NewPIDController creates and returns a new PID controller using
+// configuration that drives its input towards setpoint.
+func NewPIDController(configuration *ControllerConfiguration, setpoint float64) *PIDController {
+	if configuration == nil {
+		misuse("NewPIDController called with a nil configuration")
+	}
+	if !(setpoint > 0.0) {
+		misuse("NewPIDController called with a setpoint of %g; the setpoint must be positive", setpoint)
+	}
+
+	return &PIDController{
+		setpoint:      setpoint,
 		configuration: configuration,
 	}
 }
 
-// next calculates the next controller output signal from input.
-func (c *controller) next(input float64) (float64, float64) {
-	e := 1.0 - (input / float64(c.cpuCount))
+// newController creates and resturns a new controller whose setpoint is full
+// usage of the CPUs the runtime may execute goroutines on.
+func newController(configuration *ControllerConfiguration) *PIDController {
+	return &PIDController{
+		setpoint:      float64(runtime.GOMAXPROCS(0)),
+		configuration: configuration,
+	}
+}
+
+// MARK: Public methods
+
+// Next calculates the next controller output signal from input and returns it
+// along with the error it responded to.
+func (c *PIDController) Next(input float64) (float64, float64) {
+	return c.nextError(1.0 - (input / c.setpoint))
+}
+
+// Reset resets the controller's state.
+func (c *PIDController) Reset() {
+	c.previousError = 0.0
+	c.totalError = 0.0
+	c.previousOutput = 0.0
+	c.previousDerivative = 0.0
+}
+
+// State returns the controller's state.
+func (c *PIDController) State() PIDState {
+	return PIDState{
+		Error:      c.previousError,
+		Integral:   c.totalError,
+		Derivative: c.previousDerivative,
+		Output:     c.previousOutput,
+	}
+}
+
+// restore restores the controller's state.
+func (c *PIDController) restore(state PIDState) {
+	c.previousError = state.Error
+	c.totalError = state.Integral
+	c.previousDerivative = state.Derivative
+	c.previousOutput = state.Output
+}
+
+// Setpoint returns the value the controller drives its input towards.
+func (c *PIDController) Setpoint() float64 {
+	return c.setpoint
+}
+
+// Configuration returns a copy of the controller's configuration.
+func (c *PIDController) Configuration() *ControllerConfiguration {
+	return c.configuration.Copy()
+}
+
+// SetConfiguration replaces the controller's configuration without a bump in
+// its output. The integral term accumulated under the old gains is rescaled so
+// that, with the last error, the new gains produce the same output as the old
+// ones. The integral can't be rescaled when the new Ki is zero.
+func (c *PIDController) SetConfiguration(configuration *ControllerConfiguration) {
+	if configuration == nil {
+		misuse("PIDController.SetConfiguration called with a nil configuration")
+	}
+
+	old := c.configuration
+	if configuration.Ki != 0.0 {
+		u := old.Kp*c.previousError + old.Ki*c.totalError + old.Kd*c.previousDerivative
+		c.totalError = (u - configuration.Kp*c.previousError - configuration.Kd*c.previousDerivative) / configuration.Ki
+	}
+	c.configuration = configuration
+}
+
+// MARK: Private methods
+
+// nextError calculates the next controller output signal from the error
+// between the setpoint and the input, relative to the setpoint.
+func (c *PIDController) nextError(e float64) (float64, float64) {
 	e = c.configuration.ErrorResponse * e + (1.0 - c.configuration.ErrorResponse) * c.previousError
 
 	i := c.totalError + e
 
-	d := e - c.previousError
+	f := c.configuration.DerivativeFilter
+	d := (1.0 - f) * (e - c.previousError) + f * c.previousDerivative
 
 	u := c.configuration.Kp*e + c.configuration.Ki*i + c.configuration.Kd*d
 	u = c.configuration.OutputResponse * u + (c.configuration.OutputResponse - 1) * c.previousOutput
@@ -36,13 +142,16 @@ func (c *controller) next(input float64) (float64, float64) {
 	c.previousError = e
 	c.totalError = i
 	c.previousOutput = u
+	c.previousDerivative = d
 
 	return u, e
 }
 
-// reset resets the controller's variables.
-func (c *controller) reset() {
-	c.previousError = 0.0
-	c.totalError = 0.0
-	c.cpuCount = runtime.NumCPU()
+// hold primes the controller's integral term so that, with no error, its
+// output continues at u.
+func (c *PIDController) hold(u float64) {
+	if c.configuration.Ki != 0.0 {
+		c.totalError = u / c.configuration.Ki
+	}
+	c.previousOutput = u
 }
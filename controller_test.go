@@ -0,0 +1,98 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestControllerDerivativeFilter(t *testing.T) {
+	configuration := NewControllerConfiguration(0.0, 0.0, 1.0, 1.0, 1.0)
+	configuration.DerivativeFilter = 0.75
+
+	c := NewPIDController(configuration, 1.0)
+
+	// A step in the error from 0 to 1 is spread over several samples.
+	u, _ := c.Next(0.0)
+	if math.Abs(u-0.25) > 1e-9 {
+		t.Errorf("Output, %f, should be 0.25.", u)
+	}
+
+	u, _ = c.Next(0.0)
+	if math.Abs(u-0.1875) > 1e-9 {
+		t.Errorf("Output, %f, should be 0.1875.", u)
+	}
+
+	c.Reset()
+	if c.previousDerivative != 0.0 {
+		t.Errorf("Resetting the controller should clear the filtered derivative.")
+	}
+}
+
+func TestControllerUnfilteredDerivative(t *testing.T) {
+	c := NewPIDController(NewControllerConfiguration(0.0, 0.0, 1.0, 1.0, 1.0), 1.0)
+
+	if u, _ := c.Next(0.0); math.Abs(u-1.0) > 1e-9 {
+		t.Errorf("Output, %f, should be 1.", u)
+	}
+
+	if u, _ := c.Next(0.0); math.Abs(u) > 1e-9 {
+		t.Errorf("Output, %f, should be 0.", u)
+	}
+}
+
+func TestControllerBumplessTransfer(t *testing.T) {
+	c := NewPIDController(NewControllerConfiguration(1.0, 0.5, 0.5, 1.0, 1.0), 4.0)
+
+	for _, usage := range []float64{0.0, 1.0, 2.0, 2.5} {
+		c.Next(usage)
+	}
+
+	before := c.configuration.Kp*c.previousError + c.configuration.Ki*c.totalError + c.configuration.Kd*c.previousDerivative
+	c.SetConfiguration(NewControllerConfiguration(4.0, 2.0, 0.0, 1.0, 1.0))
+	after := c.configuration.Kp*c.previousError + c.configuration.Ki*c.totalError + c.configuration.Kd*c.previousDerivative
+
+	if math.Abs(after-before) > 1e-9 {
+		t.Errorf("Output, %f, should be unchanged from %f after changing the gains.", after, before)
+	}
+
+	// With the error unchanged, the next output continues from the same level.
+	u, _ := c.Next(2.5)
+	if math.Abs(u-(before+c.configuration.Ki*c.previousError)) > 1e-9 {
+		t.Errorf("Output, %f, should continue from %f.", u, before)
+	}
+}
+
+func TestPIDControllerState(t *testing.T) {
+	c := NewPIDController(NewControllerConfiguration(1.0, 1.0, 1.0, 1.0, 1.0), 10.0)
+
+	u, e := c.Next(5.0)
+	state := c.State()
+	if state.Error != e || state.Output != u || state.Integral != 0.5 || state.Derivative != 0.5 {
+		t.Errorf("State, %+v, should record the error 0.5 and output %f.", state, u)
+	}
+
+	if math.Abs(u-1.5) > 1e-9 {
+		t.Errorf("Output, %f, should be 1.5.", u)
+	}
+
+	c.Reset()
+	if c.State() != (PIDState{}) {
+		t.Errorf("State, %+v, should be cleared by Reset.", c.State())
+	}
+
+	if c.Setpoint() != 10.0 {
+		t.Errorf("Setpoint, %f, should be 10.", c.Setpoint())
+	}
+}
+
+func TestMisusePIDController(t *testing.T) {
+	expectMisuse(t, "nil configuration", func() {
+		NewPIDController(nil, 1.0)
+	})
+
+	expectMisuse(t, "setpoint must be positive", func() {
+		NewPIDController(NewControllerConfiguration(1.0, 0.0, 0.0, 1.0, 1.0), 0.0)
+	})
+}
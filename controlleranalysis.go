@@ -0,0 +1,245 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"runtime"
+)
+
+// PlantResponse types contain a sampled response of a workload to the number
+// of goroutines executing it, such as the routines and usage of a variable
+// process' controller history.
+type PlantResponse struct {
+	// The number of goroutines at each sample.
+	Routines []float64
+
+	// The CPU usage measured at each sample.
+	Usage []float64
+}
+
+// StabilityAnalysis types describe the stability of a controller
+// configuration's feedback loop around an identified plant.
+type StabilityAnalysis struct {
+	// The pole of the identified first-order plant. Values closer to 1 respond
+	// more slowly.
+	PlantPole float64
+
+	// The steady-state change in CPU usage caused by adding one goroutine. If
+	// the plant is not stable, this is the change after a single interval.
+	PlantGain float64
+
+	// The factor by which the loop gain can increase before the loop becomes
+	// unstable. Positive infinity if the loop's phase never reaches -180°.
+	GainMargin float64
+
+	// The additional phase lag, in degrees, the loop can tolerate before
+	// becoming unstable. Positive infinity if the loop's gain never reaches 1.
+	PhaseMargin float64
+
+	// The frequency, in radians per optimization interval, at which the loop's
+	// gain is 1.
+	CrossoverFrequency float64
+
+	// Whether or not the closed loop is stable.
+	Stable bool
+
+	// Descriptions of properties of the loop that are likely to cause
+	// oscillation.
+	Warnings []string
+}
+
+// The phase margin, in degrees, below which a loop is considered oscillatory.
+const minimumPhaseMargin = 30.0
+
+// The gain margin below which a loop is considered oscillatory.
+const minimumGainMargin = 2.0
+
+// The number of frequencies sampled when computing stability margins.
+const analysisFrequencyCount = 4096
+
+// MARK: Public functions
+
+// AnalyzeControllerConfiguration identifies a first-order plant from response
+// and computes the stability margins of the feedback loop formed by the plant
+// and a controller using configuration. The loop's setpoint is full usage of
+// cpuCount CPUs. If cpuCount is less than one, runtime.GOMAXPROCS(0) is used.
+func AnalyzeControllerConfiguration(configuration *ControllerConfiguration, response PlantResponse, cpuCount int) (*StabilityAnalysis, error) {
+	if cpuCount < 1 {
+		cpuCount = runtime.GOMAXPROCS(0)
+	}
+
+	pole, gain, err := identifyPlant(response)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &StabilityAnalysis{
+		PlantPole:   pole,
+		PlantGain:   gain,
+		GainMargin:  math.Inf(1),
+		PhaseMargin: math.Inf(1),
+	}
+
+	if math.Abs(pole) < 1.0 {
+		a.PlantGain = gain / (1.0 - pole)
+	}
+
+	loop := func(w float64) complex128 {
+		return loopResponse(configuration, pole, gain, float64(cpuCount), w)
+	}
+
+	a.computeMargins(loop)
+	a.Stable = math.Abs(pole) < 1.0 && a.GainMargin > 1.0 && a.PhaseMargin > 0.0
+	a.computeWarnings(configuration)
+
+	return a, nil
+}
+
+// MARK: Private methods
+
+// computeMargins sweeps the loop's frequency response and records the gain and
+// phase margins at the first crossovers.
+func (a *StabilityAnalysis) computeMargins(loop func(w float64) complex128) {
+	minimum := math.Log(1e-4)
+	maximum := math.Log(math.Pi)
+
+	var previousMagnitude, previousPhase float64
+	phaseOffset := 0.0
+
+	for n := 0; n < analysisFrequencyCount; n++ {
+		w := math.Exp(minimum + (maximum-minimum)*float64(n)/float64(analysisFrequencyCount-1))
+		l := loop(w)
+		magnitude := cmplx.Abs(l)
+		phase := cmplx.Phase(l)*180.0/math.Pi + phaseOffset
+
+		if n > 0 {
+			for phase-previousPhase > 180.0 {
+				phase -= 360.0
+				phaseOffset -= 360.0
+			}
+			for phase-previousPhase < -180.0 {
+				phase += 360.0
+				phaseOffset += 360.0
+			}
+
+			if math.IsInf(a.PhaseMargin, 1) && previousMagnitude >= 1.0 && magnitude < 1.0 {
+				a.PhaseMargin = 180.0 + phase
+				a.CrossoverFrequency = w
+			}
+
+			if math.IsInf(a.GainMargin, 1) && previousPhase > -180.0 && phase <= -180.0 {
+				a.GainMargin = 1.0 / magnitude
+			}
+		}
+
+		previousMagnitude = magnitude
+		previousPhase = phase
+	}
+}
+
+// computeWarnings records warnings about the loop's margins and the
+// configuration.
+func (a *StabilityAnalysis) computeWarnings(configuration *ControllerConfiguration) {
+	if math.Abs(a.PlantPole) >= 1.0 {
+		a.Warnings = append(a.Warnings, fmt.Sprintf("the identified plant pole, %.3f, is not stable; the response may not contain enough variation", a.PlantPole))
+	}
+
+	if a.PlantGain <= 0.0 {
+		a.Warnings = append(a.Warnings, "adding goroutines does not increase CPU usage, so the controller will saturate at its maximum routines")
+	}
+
+	if a.PhaseMargin < minimumPhaseMargin {
+		a.Warnings = append(a.Warnings, fmt.Sprintf("the phase margin, %.1f°, is below %.0f°; the routine count will oscillate", a.PhaseMargin, minimumPhaseMargin))
+	}
+
+	if a.GainMargin < minimumGainMargin {
+		a.Warnings = append(a.Warnings, fmt.Sprintf("the gain margin, %.2f, is below %.0f; reduce Kp or Kd", a.GainMargin, minimumGainMargin))
+	}
+
+	if configuration.Ki == 0.0 {
+		a.Warnings = append(a.Warnings, "Ki is zero, so the loop will settle with a steady-state error")
+	}
+}
+
+// MARK: Private functions
+
+// identifyPlant fits the model y[k] = a*y[k-1] + b*u[k-1] + c to response with
+// least squares and returns a and b.
+func identifyPlant(response PlantResponse) (float64, float64, error) {
+	n := len(response.Usage)
+	if len(response.Routines) < n {
+		n = len(response.Routines)
+	}
+
+	if n < 4 {
+		return 0.0, 0.0, errors.New("parallel: a plant response needs at least 4 samples")
+	}
+
+	// Accumulate the normal equations for the regressors (y[k-1], u[k-1], 1).
+	var m [3][4]float64
+	for k := 1; k < n; k++ {
+		x := [3]float64{response.Usage[k-1], response.Routines[k-1], 1.0}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				m[i][j] += x[i] * x[j]
+			}
+			m[i][3] += x[i] * response.Usage[k]
+		}
+	}
+
+	// Solve with Gauss-Jordan elimination and partial pivoting.
+	for c := 0; c < 3; c++ {
+		pivot := c
+		for r := c + 1; r < 3; r++ {
+			if math.Abs(m[r][c]) > math.Abs(m[pivot][c]) {
+				pivot = r
+			}
+		}
+
+		if math.Abs(m[pivot][c]) < 1e-12 {
+			return 0.0, 0.0, errors.New("parallel: the plant response does not vary enough to identify a plant")
+		}
+
+		m[c], m[pivot] = m[pivot], m[c]
+		for r := 0; r < 3; r++ {
+			if r == c {
+				continue
+			}
+
+			f := m[r][c] / m[c][c]
+			for j := c; j < 4; j++ {
+				m[r][j] -= f * m[c][j]
+			}
+		}
+	}
+
+	return m[0][3] / m[0][0], m[1][3] / m[1][1], nil
+}
+
+// loopResponse returns the open-loop frequency response of a controller using
+// configuration around the plant b*z^-1/(1 - a*z^-1) at w radians per sample.
+func loopResponse(configuration *ControllerConfiguration, a float64, b float64, cpuCount float64, w float64) complex128 {
+	zInverse := cmplx.Exp(complex(0.0, -w))
+
+	// The error is normalized by the CPU count and low-pass filtered by the
+	// error response before reaching the PID terms.
+	alpha := complex(configuration.ErrorResponse, 0.0)
+	errorFilter := alpha / (1.0 - (1.0-alpha)*zInverse)
+
+	// The change in error is low-pass filtered by the derivative filter.
+	f := complex(configuration.DerivativeFilter, 0.0)
+	derivative := (1.0 - f) * (1.0 - zInverse) / (1.0 - f*zInverse)
+
+	pid := complex(configuration.Kp, 0.0) +
+		complex(configuration.Ki, 0.0)/(1.0-zInverse) +
+		complex(configuration.Kd, 0.0)*derivative
+
+	beta := complex(configuration.OutputResponse, 0.0)
+	outputFilter := beta / (1.0 - (beta-1.0)*zInverse)
+
+	plant := complex(b, 0.0) * zInverse / (1.0 - complex(a, 0.0)*zInverse)
+
+	return errorFilter * pid * outputFilter * plant / complex(cpuCount, 0.0)
+}
@@ -0,0 +1,86 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestAnalyzeControllerConfigurationIdentification(t *testing.T) {
+	response := testPlantResponse(0.5, 0.8, 200)
+	c := NewControllerConfiguration(0.5, 0.1, 0.0, 1.0, 1.0)
+
+	a, err := AnalyzeControllerConfiguration(c, response, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if math.Abs(a.PlantPole-0.5) > 1e-6 {
+		t.Errorf("Plant pole, %f, should be 0.5.", a.PlantPole)
+	}
+
+	if math.Abs(a.PlantGain-1.6) > 1e-6 {
+		t.Errorf("Plant gain, %f, should be 1.6.", a.PlantGain)
+	}
+}
+
+func TestAnalyzeControllerConfigurationStable(t *testing.T) {
+	response := testPlantResponse(0.5, 0.8, 200)
+	c := NewControllerConfiguration(0.5, 0.5, 0.0, 1.0, 1.0)
+
+	a, err := AnalyzeControllerConfiguration(c, response, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !a.Stable || len(a.Warnings) > 0 {
+		t.Errorf("The loop should be stable without warnings: %+v", a)
+	}
+}
+
+func TestAnalyzeControllerConfigurationUnstable(t *testing.T) {
+	response := testPlantResponse(0.5, 0.8, 200)
+	c := NewControllerConfiguration(40.0, 10.0, 5.0, 1.0, 1.0)
+
+	a, err := AnalyzeControllerConfiguration(c, response, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if a.Stable || len(a.Warnings) == 0 {
+		t.Errorf("The loop should be unstable with warnings: %+v", a)
+	}
+}
+
+func TestAnalyzeControllerConfigurationConstantResponse(t *testing.T) {
+	response := PlantResponse{
+		Routines: []float64{1, 1, 1, 1, 1},
+		Usage:    []float64{1, 1, 1, 1, 1},
+	}
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	if _, err := AnalyzeControllerConfiguration(c, response, 4); err == nil {
+		t.Errorf("A constant response should return an error.")
+	}
+}
+
+// MARK: Helpers
+
+// testPlantResponse returns the response of the plant y[k] = a*y[k-1] +
+// b*u[k-1] to a varying number of routines.
+func testPlantResponse(a float64, b float64, n int) PlantResponse {
+	response := PlantResponse{
+		Routines: make([]float64, n),
+		Usage:    make([]float64, n),
+	}
+
+	for k := 0; k < n; k++ {
+		response.Routines[k] = float64(1 + (k/7)%5 + (k/3)%2)
+		if k > 0 {
+			response.Usage[k] = a*response.Usage[k-1] + b*response.Routines[k-1]
+		}
+	}
+
+	return response
+}
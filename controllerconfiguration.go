@@ -1,5 +1,13 @@
 package parallel
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+	"time"
+)
+
 // ControllerConfiguration types contain the information necessary to calculate
 // values from a PID controller.
 type ControllerConfiguration struct {
@@ -17,6 +25,76 @@ type ControllerConfiguration struct {
 
 	// The output signal response.
 	OutputResponse float64
+
+	// The smoothing of the derivative term, between 0 and 1. The change in
+	// error is low-pass filtered by the derivative filter before it is scaled
+	// by Kd, so noisy CPU usage samples don't produce jittery routine counts.
+	// Zero disables the filter.
+	DerivativeFilter float64
+
+	// The smoothing of the CPU usage signal, between 0 and 1. Each usage
+	// sample is averaged with the previous ones by an exponential moving
+	// average that gives the previous average this weight, so short spikes,
+	// such as a garbage collection or another program briefly using the CPUs,
+	// don't whipsaw the routine count. Zero disables the smoothing.
+	UsageSmoothing float64
+
+	// The largest number of routines a single optimization may add or remove.
+	// Large controller outputs otherwise start or stop many goroutines at
+	// once, thrashing the scheduler. Zero places no limit on the change.
+	MaxRoutinesDeltaPerTick int
+
+	// The number of routines the controller's output must differ from the
+	// current number of routines by before routines are added or removed.
+	// Without a deadband, the routine count oscillates by one around the
+	// setpoint at every optimization. Zero disables the deadband.
+	Deadband float64
+
+	// The number of bytes of heap in use above which routines are removed,
+	// even if the CPUs aren't saturated. While the heap is over the limit, the
+	// number of routines is scaled down in proportion to the excess. Zero
+	// disables the limit.
+	MemoryLimit uint64
+
+	// The fraction of the memory limit, between 0 and 1, at which no more
+	// routines are added. If the configuration has no memory limit, the
+	// fraction is of the runtime's soft memory limit, GOMEMLIMIT. Zero
+	// disables the high water mark.
+	MemoryHighWater float64
+
+	// The 95th percentile of goroutine scheduling latencies, the time
+	// goroutines wait to run once they're runnable, above which no more
+	// routines are added. Latencies that grow as routines are added show
+	// that the CPUs are oversubscribed and more routines won't help. Zero
+	// disables the limit. It needs Go 1.17 or later.
+	SchedulingLatencyLimit time.Duration
+
+	// The number of iterations per second a variable process using the
+	// throughput optimizer drives its executions towards.
+	ThroughputTarget float64
+
+	// The 95th percentile of operation latencies a variable process using the
+	// latency optimizer keeps its executions below.
+	LatencyTarget time.Duration
+}
+
+// controllerConfigurationJSON types are the JSON representation of a
+// controller configuration.
+type controllerConfigurationJSON struct {
+	Kp                      float64 `json:"kp"`
+	Ki                      float64 `json:"ki"`
+	Kd                      float64 `json:"kd"`
+	ErrorResponse           float64 `json:"errorResponse"`
+	OutputResponse          float64 `json:"outputResponse"`
+	DerivativeFilter        float64 `json:"derivativeFilter,omitempty"`
+	UsageSmoothing          float64 `json:"usageSmoothing,omitempty"`
+	MaxRoutinesDeltaPerTick int     `json:"maxRoutinesDeltaPerTick,omitempty"`
+	Deadband                float64 `json:"deadband,omitempty"`
+	MemoryLimit             uint64  `json:"memoryLimit,omitempty"`
+	MemoryHighWater         float64 `json:"memoryHighWater,omitempty"`
+	SchedulingLatencyLimit  string  `json:"schedulingLatencyLimit,omitempty"`
+	ThroughputTarget        float64 `json:"throughputTarget,omitempty"`
+	LatencyTarget           string  `json:"latencyTarget,omitempty"`
 }
 
 // NewControllerConfiguration creates and returns a new controller
@@ -31,15 +109,56 @@ func NewControllerConfiguration(kp float64, ki float64, kd float64, errorRespons
 	}
 }
 
+// NewConservativeControllerConfiguration creates and returns a controller
+// configuration that changes the number of routines slowly and smoothly. Its
+// gains are scaled by the number of CPUs.
+func NewConservativeControllerConfiguration() *ControllerConfiguration {
+	cpus := float64(runtime.NumCPU())
+	c := NewControllerConfiguration(0.5*cpus, 0.05*cpus, 0.0, 0.25, 1.0)
+	c.DerivativeFilter = 0.8
+	c.MaxRoutinesDeltaPerTick = maxInt(1, runtime.NumCPU()/4)
+	c.Deadband = 1.0
+	return c
+}
+
+// NewBalancedControllerConfiguration creates and returns a controller
+// configuration that suits most workloads. Its gains are scaled by the number
+// of CPUs.
+func NewBalancedControllerConfiguration() *ControllerConfiguration {
+	cpus := float64(runtime.NumCPU())
+	c := NewControllerConfiguration(1.0*cpus, 0.1*cpus, 0.1*cpus, 0.5, 1.0)
+	c.DerivativeFilter = 0.5
+	c.Deadband = 0.5
+	return c
+}
+
+// NewAggressiveControllerConfiguration creates and returns a controller
+// configuration that saturates the CPUs as quickly as possible at the cost of
+// overshooting. Its gains are scaled by the number of CPUs.
+func NewAggressiveControllerConfiguration() *ControllerConfiguration {
+	cpus := float64(runtime.NumCPU())
+	return NewControllerConfiguration(2.0*cpus, 0.25*cpus, 0.25*cpus, 1.0, 1.0)
+}
+
 // newControllerConfigurationFromConfiguration creates and returns a new
 // controller configuration from another configuration.
 func newControllerConfigurationFromConfiguration(configuration *ControllerConfiguration) *ControllerConfiguration {
 	return &ControllerConfiguration{
-		Kp:             configuration.Kp,
-		Ki:             configuration.Ki,
-		Kd:             configuration.Kd,
-		ErrorResponse:  configuration.ErrorResponse,
-		OutputResponse: configuration.OutputResponse,
+		Kp:               configuration.Kp,
+		Ki:               configuration.Ki,
+		Kd:               configuration.Kd,
+		ErrorResponse:    configuration.ErrorResponse,
+		OutputResponse:   configuration.OutputResponse,
+		DerivativeFilter: configuration.DerivativeFilter,
+		UsageSmoothing:   configuration.UsageSmoothing,
+
+		MaxRoutinesDeltaPerTick: configuration.MaxRoutinesDeltaPerTick,
+		Deadband:                configuration.Deadband,
+		MemoryLimit:             configuration.MemoryLimit,
+		MemoryHighWater:         configuration.MemoryHighWater,
+		SchedulingLatencyLimit:  configuration.SchedulingLatencyLimit,
+		ThroughputTarget:        configuration.ThroughputTarget,
+		LatencyTarget:           configuration.LatencyTarget,
 	}
 }
 
@@ -47,3 +166,136 @@ func newControllerConfigurationFromConfiguration(configuration *ControllerConfig
 func (c *ControllerConfiguration) Copy() *ControllerConfiguration {
 	return newControllerConfigurationFromConfiguration(c)
 }
+
+// Validate returns an error describing the first of the configuration's values
+// that is out of range, or nil if the configuration is valid.
+func (c *ControllerConfiguration) Validate() error {
+	for _, gain := range []struct {
+		name  string
+		value float64
+	}{
+		{"Kp", c.Kp},
+		{"Ki", c.Ki},
+		{"Kd", c.Kd},
+	} {
+		if !(gain.value >= 0.0) || math.IsInf(gain.value, 0) {
+			return fmt.Errorf("parallel: invalid controller configuration: %s is %g; gains must be finite and not negative", gain.name, gain.value)
+		}
+	}
+
+	if !(c.ErrorResponse > 0.0 && c.ErrorResponse <= 1.0) {
+		return fmt.Errorf("parallel: invalid controller configuration: ErrorResponse is %g; it must be greater than 0 and at most 1", c.ErrorResponse)
+	}
+
+	if !(c.OutputResponse > 0.0 && c.OutputResponse <= 1.0) {
+		return fmt.Errorf("parallel: invalid controller configuration: OutputResponse is %g; it must be greater than 0 and at most 1", c.OutputResponse)
+	}
+
+	if !(c.DerivativeFilter >= 0.0 && c.DerivativeFilter < 1.0) {
+		return fmt.Errorf("parallel: invalid controller configuration: DerivativeFilter is %g; it must be at least 0 and less than 1", c.DerivativeFilter)
+	}
+
+	if !(c.UsageSmoothing >= 0.0 && c.UsageSmoothing < 1.0) {
+		return fmt.Errorf("parallel: invalid controller configuration: UsageSmoothing is %g; it must be at least 0 and less than 1", c.UsageSmoothing)
+	}
+
+	if c.MaxRoutinesDeltaPerTick < 0 {
+		return fmt.Errorf("parallel: invalid controller configuration: MaxRoutinesDeltaPerTick is %d; it must not be negative", c.MaxRoutinesDeltaPerTick)
+	}
+
+	if !(c.Deadband >= 0.0) || math.IsInf(c.Deadband, 0) {
+		return fmt.Errorf("parallel: invalid controller configuration: Deadband is %g; it must be finite and not negative", c.Deadband)
+	}
+
+	if !(c.MemoryHighWater >= 0.0 && c.MemoryHighWater <= 1.0) {
+		return fmt.Errorf("parallel: invalid controller configuration: MemoryHighWater is %g; it must be between 0 and 1", c.MemoryHighWater)
+	}
+
+	if c.SchedulingLatencyLimit < 0 {
+		return fmt.Errorf("parallel: invalid controller configuration: SchedulingLatencyLimit is %s; it must not be negative", c.SchedulingLatencyLimit)
+	}
+
+	if !(c.ThroughputTarget >= 0.0) || math.IsInf(c.ThroughputTarget, 0) {
+		return fmt.Errorf("parallel: invalid controller configuration: ThroughputTarget is %g; it must be finite and not negative", c.ThroughputTarget)
+	}
+
+	if c.LatencyTarget < 0 {
+		return fmt.Errorf("parallel: invalid controller configuration: LatencyTarget is %s; it must not be negative", c.LatencyTarget)
+	}
+
+	return nil
+}
+
+// MarshalJSON returns the configuration encoded as JSON. The latency target and
+// scheduling latency limit are encoded as duration strings such as "20ms".
+func (c ControllerConfiguration) MarshalJSON() ([]byte, error) {
+	v := controllerConfigurationJSON{
+		Kp:                      c.Kp,
+		Ki:                      c.Ki,
+		Kd:                      c.Kd,
+		ErrorResponse:           c.ErrorResponse,
+		OutputResponse:          c.OutputResponse,
+		DerivativeFilter:        c.DerivativeFilter,
+		UsageSmoothing:          c.UsageSmoothing,
+		MaxRoutinesDeltaPerTick: c.MaxRoutinesDeltaPerTick,
+		Deadband:                c.Deadband,
+		MemoryLimit:             c.MemoryLimit,
+		MemoryHighWater:         c.MemoryHighWater,
+		ThroughputTarget:        c.ThroughputTarget,
+	}
+	if c.SchedulingLatencyLimit != 0 {
+		v.SchedulingLatencyLimit = c.SchedulingLatencyLimit.String()
+	}
+	if c.LatencyTarget != 0 {
+		v.LatencyTarget = c.LatencyTarget.String()
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the configuration from JSON and validates it, so that
+// configurations loaded from files can be used without further checks.
+func (c *ControllerConfiguration) UnmarshalJSON(data []byte) error {
+	var v controllerConfigurationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	decoded := ControllerConfiguration{
+		Kp:                      v.Kp,
+		Ki:                      v.Ki,
+		Kd:                      v.Kd,
+		ErrorResponse:           v.ErrorResponse,
+		OutputResponse:          v.OutputResponse,
+		DerivativeFilter:        v.DerivativeFilter,
+		UsageSmoothing:          v.UsageSmoothing,
+		MaxRoutinesDeltaPerTick: v.MaxRoutinesDeltaPerTick,
+		Deadband:                v.Deadband,
+		MemoryLimit:             v.MemoryLimit,
+		MemoryHighWater:         v.MemoryHighWater,
+		ThroughputTarget:        v.ThroughputTarget,
+	}
+
+	if v.SchedulingLatencyLimit != "" {
+		limit, err := time.ParseDuration(v.SchedulingLatencyLimit)
+		if err != nil {
+			return fmt.Errorf("parallel: invalid controller configuration: %v", err)
+		}
+		decoded.SchedulingLatencyLimit = limit
+	}
+
+	if v.LatencyTarget != "" {
+		latency, err := time.ParseDuration(v.LatencyTarget)
+		if err != nil {
+			return fmt.Errorf("parallel: invalid controller configuration: %v", err)
+		}
+		decoded.LatencyTarget = latency
+	}
+
+	if err := decoded.Validate(); err != nil {
+		return err
+	}
+
+	*c = decoded
+	return nil
+}
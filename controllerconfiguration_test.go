@@ -0,0 +1,121 @@
+package parallel
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestControllerConfigurationPresets(t *testing.T) {
+	conservative := NewConservativeControllerConfiguration()
+	balanced := NewBalancedControllerConfiguration()
+	aggressive := NewAggressiveControllerConfiguration()
+
+	if !(conservative.Kp < balanced.Kp && balanced.Kp < aggressive.Kp) {
+		t.Errorf("Proportional gains, %f, %f and %f, should increase with aggressiveness.", conservative.Kp, balanced.Kp, aggressive.Kp)
+	}
+
+	if !(conservative.ErrorResponse < balanced.ErrorResponse && balanced.ErrorResponse <= aggressive.ErrorResponse) {
+		t.Errorf("Error responses should increase with aggressiveness.")
+	}
+
+	for _, c := range []*ControllerConfiguration{conservative, balanced, aggressive} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Preset should be valid: %v", err)
+		}
+
+		p := NewVariableProcess(time.Millisecond, 1, 8, c, false)
+
+		v := make([]int, 500)
+		p.Execute(len(v), func(i int) {
+			time.Sleep(100 * time.Microsecond)
+			v[i]++
+		})
+
+		for i, value := range v {
+			if value != 1 {
+				t.Errorf("Index %d was executed %d times with configuration %+v.", i, value, *c)
+				break
+			}
+		}
+	}
+}
+
+func TestControllerConfigurationJSON(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.5, 1.0, 0.1, 1.0)
+	c.DerivativeFilter = 0.8
+	c.UsageSmoothing = 0.5
+	c.MaxRoutinesDeltaPerTick = 4
+	c.Deadband = 1.5
+	c.MemoryLimit = 1 << 30
+	c.ThroughputTarget = 100.0
+	c.LatencyTarget = 20 * time.Millisecond
+	c.SchedulingLatencyLimit = time.Millisecond
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Error marshaling configuration: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"latencyTarget":"20ms"`) {
+		t.Errorf("JSON, %s, should encode the latency target as a duration string.", data)
+	}
+
+	decoded := &ControllerConfiguration{}
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Error unmarshaling configuration: %v", err)
+	}
+
+	if *decoded != *c {
+		t.Errorf("Decoded configuration, %+v, should equal %+v.", *decoded, *c)
+	}
+}
+
+func TestControllerConfigurationUnmarshalInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"kp": 1, "ki": 0, "kd": 0, "errorResponse": 1.5, "outputResponse": 1}`,
+		`{"kp": 1, "ki": 0, "kd": 0, "errorResponse": 1, "outputResponse": 1, "latencyTarget": "soon"}`,
+	} {
+		c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+		if err := json.Unmarshal([]byte(data), c); err == nil {
+			t.Errorf("Unmarshaling %s should fail.", data)
+		}
+
+		if c.Kp != 2.0 {
+			t.Errorf("An invalid configuration should not modify the receiver.")
+		}
+	}
+}
+
+func TestControllerConfigurationValidate(t *testing.T) {
+	if err := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0).Validate(); err != nil {
+		t.Errorf("Configuration should be valid: %v", err)
+	}
+
+	for _, invalid := range []func(c *ControllerConfiguration){
+		func(c *ControllerConfiguration) { c.Kp = -1.0 },
+		func(c *ControllerConfiguration) { c.Ki = math.NaN() },
+		func(c *ControllerConfiguration) { c.ErrorResponse = 1.5 },
+		func(c *ControllerConfiguration) { c.ErrorResponse = 0.0 },
+		func(c *ControllerConfiguration) { c.OutputResponse = -0.5 },
+		func(c *ControllerConfiguration) { c.DerivativeFilter = 1.0 },
+		func(c *ControllerConfiguration) { c.UsageSmoothing = -0.5 },
+		func(c *ControllerConfiguration) { c.MaxRoutinesDeltaPerTick = -1 },
+		func(c *ControllerConfiguration) { c.Deadband = -1.0 },
+		func(c *ControllerConfiguration) { c.ThroughputTarget = math.Inf(1) },
+		func(c *ControllerConfiguration) { c.LatencyTarget = -time.Second },
+		func(c *ControllerConfiguration) { c.SchedulingLatencyLimit = -time.Second },
+	} {
+		c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+		invalid(c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("Configuration, %+v, should be invalid.", *c)
+		} else if !strings.HasPrefix(err.Error(), "parallel: ") {
+			t.Errorf("Error, %q, should be prefixed with the package name.", err)
+		}
+	}
+}
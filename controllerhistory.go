@@ -0,0 +1,64 @@
+package parallel
+
+import "sync"
+
+// controllerHistory types record the controller samples of a variable process'
+// executions so that they can be inspected and added to its summary.
+type controllerHistory struct {
+	// A mutex to protect the samples.
+	mutex sync.Mutex
+
+	// The recorded samples, oldest first. Up to twice the maximum length is
+	// kept so that discarding old samples doesn't copy on every sample.
+	samples []ControllerSample
+
+	// The number of samples kept, or zero if every sample is kept.
+	maximumLength int
+}
+
+// MARK: Initializers
+
+// newControllerHistory creates and returns a new, empty controller history.
+func newControllerHistory() *controllerHistory {
+	return &controllerHistory{}
+}
+
+// MARK: Public methods
+
+// RecordSample appends sample to the history.
+func (h *controllerHistory) RecordSample(sample ControllerSample) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.samples = append(h.samples, sample)
+	if h.maximumLength > 0 && len(h.samples) >= 2*h.maximumLength {
+		h.samples = append(h.samples[:0], h.samples[len(h.samples)-h.maximumLength:]...)
+	}
+}
+
+// Flush does nothing, since samples are recorded as they arrive.
+func (h *controllerHistory) Flush() {}
+
+// MARK: Private methods
+
+// begin clears the history and sets the number of samples it keeps, or makes
+// it unbounded if maximumLength is zero.
+func (h *controllerHistory) begin(maximumLength int) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.samples = h.samples[:0]
+	h.maximumLength = maximumLength
+}
+
+// snapshot returns a copy of the most recent samples, oldest first.
+func (h *controllerHistory) snapshot() []ControllerSample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	samples := h.samples
+	if h.maximumLength > 0 && len(samples) > h.maximumLength {
+		samples = samples[len(samples)-h.maximumLength:]
+	}
+	return append([]ControllerSample(nil), samples...)
+}
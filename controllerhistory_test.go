@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestControllerHistoryLength(t *testing.T) {
+	h := newControllerHistory()
+	h.begin(3)
+	for i := 0; i < 10; i++ {
+		h.RecordSample(ControllerSample{Routines: float64(i)})
+	}
+
+	samples := h.snapshot()
+	if len(samples) != 3 {
+		t.Fatalf("History length, %d, should be 3.", len(samples))
+	}
+	for i, sample := range samples {
+		if sample.Routines != float64(7+i) {
+			t.Errorf("Sample %d has routines %f, not %d.", i, sample.Routines, 7+i)
+		}
+	}
+
+	h.begin(0)
+	if samples = h.snapshot(); len(samples) != 0 {
+		t.Errorf("History length, %d, should be 0 after beginning.", len(samples))
+	}
+}
+
+func TestVariableProcessProbeSignalLength(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, true)
+	p.SetProbeSignalLength(5)
+	if p.ProbeSignalLength() != 5 {
+		t.Errorf("Probe signal length, %d, should be 5.", p.ProbeSignalLength())
+	}
+
+	p.Execute(200, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	if n := len(p.ControllerHistory()); n == 0 || n > 5 {
+		t.Errorf("Controller history length, %d, should be between 1 and 5.", n)
+	}
+	if n := len(p.Summary().ControllerHistory); n > 5 {
+		t.Errorf("Summary controller history length, %d, should be at most 5.", n)
+	}
+
+	p.SetProbeSignalLength(0)
+	if p.ProbeSignalLength() != 0 {
+		t.Errorf("Probe signal length, %d, should be 0.", p.ProbeSignalLength())
+	}
+}
+
+func TestProbeSignalLengthWithoutProbes(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetProbeSignalLength(5)
+	p.Execute(10, func(i int) {})
+
+	if history := p.ControllerHistory(); history != nil {
+		t.Errorf("A process that doesn't probe its controller shouldn't have a history, %v.", history)
+	}
+}
@@ -0,0 +1,215 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DAGProcess types execute a graph of tasks in parallel while respecting the
+// dependencies between them.
+//
+// Each task is scheduled as soon as all of its dependencies have finished,
+// without waiting for unrelated tasks. The graph is executed on the process the
+// DAG process was initialized with, so a VariableProcess optimizes the number
+// of routines used for the graph.
+type DAGProcess struct {
+	// The process used to execute the graph.
+	process Process
+
+	// The registered tasks keyed by name.
+	tasks map[string]*dagTask
+
+	// The task names in the order they were registered.
+	names []string
+
+	// Whether or not the process has been stopped.
+	stopped int32
+
+	// A mutex to protect halt.
+	haltMutex sync.Mutex
+
+	// Closed to release the routines waiting for ready tasks when the process
+	// is stopped. Nil while the process isn't executing.
+	halt chan struct{}
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// dagTask types represent a single task in a DAG process.
+type dagTask struct {
+	name         string
+	dependencies []string
+	task         Task
+
+	// The tasks that depend on this task and the number of this task's
+	// dependencies that haven't finished during an execution.
+	dependents []*dagTask
+	waiting    int32
+}
+
+// MARK: Initializers
+
+// NewDAGProcess creates and returns a new DAG process that executes tasks on
+// the given process.
+func NewDAGProcess(process Process) *DAGProcess {
+	return &DAGProcess{
+		process:    process,
+		tasks:      make(map[string]*dagTask),
+		throughput: newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+}
+
+// MARK: Public methods
+
+// AddTask registers a task with the given name that may only begin after each
+// of its dependencies have finished. Dependencies may be registered after the
+// task that depends on them.
+func (p *DAGProcess) AddTask(name string, dependencies []string, task Task) error {
+	if task == nil {
+		misuse("DAGProcess.AddTask called with a nil task for %q", name)
+	}
+	if _, ok := p.tasks[name]; ok {
+		return fmt.Errorf("parallel: task %q is already registered", name)
+	}
+
+	p.tasks[name] = &dagTask{
+		name:         name,
+		dependencies: dependencies,
+		task:         task,
+	}
+	p.names = append(p.names, name)
+	return nil
+}
+
+// Execute executes every registered task. An error is returned without
+// executing any tasks if a dependency is missing or the graph contains a
+// cycle.
+func (p *DAGProcess) Execute() error {
+	roots, err := p.prepare()
+	if err != nil {
+		return err
+	}
+
+	ready := make(chan *dagTask, len(p.names))
+	for _, task := range roots {
+		ready <- task
+	}
+
+	halt := make(chan struct{})
+	p.haltMutex.Lock()
+	p.halt = halt
+	atomic.StoreInt32(&p.stopped, 0)
+	p.haltMutex.Unlock()
+
+	// Every iteration runs the next ready task. Each task becomes ready exactly
+	// once, so iterations only wait while the tasks they'd run depend on tasks
+	// that are still executing.
+	p.process.Execute(len(p.names), func(i int) {
+		var task *dagTask
+		select {
+		case task = <-ready:
+		case <-halt:
+			return
+		}
+
+		if atomic.LoadInt32(&p.stopped) == 1 {
+			return
+		}
+
+		task.task()
+		p.throughput.Add(1)
+
+		for _, dependent := range task.dependents {
+			if atomic.AddInt32(&dependent.waiting, -1) == 0 {
+				ready <- dependent
+			}
+		}
+	})
+
+	p.haltMutex.Lock()
+	p.halt = nil
+	p.haltMutex.Unlock()
+	return nil
+}
+
+// Stop stops the DAG process after all of the current tasks have finished
+// executing. Tasks that depend on unfinished tasks are not executed.
+func (p *DAGProcess) Stop() {
+	p.haltMutex.Lock()
+	atomic.StoreInt32(&p.stopped, 1)
+	if p.halt != nil {
+		close(p.halt)
+		p.halt = nil
+	}
+	p.haltMutex.Unlock()
+
+	p.process.Stop()
+}
+
+// Throughput returns the meter measuring the number of tasks the process
+// completes per second.
+func (p *DAGProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private methods
+
+// prepare links every registered task to its dependents, resets the number of
+// dependencies each task waits for, and returns the tasks without
+// dependencies.
+func (p *DAGProcess) prepare() ([]*dagTask, error) {
+	for _, name := range p.names {
+		task := p.tasks[name]
+		task.dependents = nil
+		task.waiting = 0
+	}
+
+	for _, name := range p.names {
+		task := p.tasks[name]
+		for _, dependency := range task.dependencies {
+			d, ok := p.tasks[dependency]
+			if !ok {
+				return nil, fmt.Errorf("parallel: task %q depends on unregistered task %q", name, dependency)
+			}
+
+			task.waiting++
+			d.dependents = append(d.dependents, task)
+		}
+	}
+
+	var roots []*dagTask
+	for _, name := range p.names {
+		if task := p.tasks[name]; task.waiting == 0 {
+			roots = append(roots, task)
+		}
+	}
+
+	// Walk the graph in topological order to find cycles before any task is
+	// executed.
+	inDegree := make(map[*dagTask]int32, len(p.tasks))
+	current := roots
+	count := 0
+	for len(current) > 0 {
+		count += len(current)
+
+		var next []*dagTask
+		for _, task := range current {
+			for _, dependent := range task.dependents {
+				inDegree[dependent]++
+				if inDegree[dependent] == dependent.waiting {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+
+	if count != len(p.tasks) {
+		return nil, errors.New("parallel: task graph contains a cycle")
+	}
+
+	return roots, nil
+}
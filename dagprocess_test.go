@@ -0,0 +1,140 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestDAGProcessOrder(t *testing.T) {
+	var mutex sync.Mutex
+	finished := make(map[string]bool)
+
+	p := NewDAGProcess(NewFixedProcess(4))
+	add := func(name string, dependencies ...string) {
+		err := p.AddTask(name, dependencies, func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			for _, dependency := range dependencies {
+				if !finished[dependency] {
+					t.Errorf("Task %s started before its dependency, %s, finished.", name, dependency)
+				}
+			}
+			finished[name] = true
+		})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	add("d", "b", "c")
+	add("a")
+	add("b", "a")
+	add("c", "a")
+	add("e")
+
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(finished) != 5 {
+		t.Errorf("Finished task count, %d, should be 5.", len(finished))
+	}
+}
+
+func TestDAGProcessVariable(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewDAGProcess(NewVariableProcess(10*time.Millisecond, 1, 4, c, false))
+
+	count := safeInt{}
+	p.AddTask("a", nil, func() { count.add(1) })
+	p.AddTask("b", []string{"a"}, func() { count.add(1) })
+
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if count.get() != 2 {
+		t.Errorf("Finished task count, %d, should be 2.", count.get())
+	}
+}
+
+func TestDAGProcessCycle(t *testing.T) {
+	p := NewDAGProcess(NewFixedProcess(2))
+	p.AddTask("a", []string{"b"}, func() {})
+	p.AddTask("b", []string{"a"}, func() {})
+
+	if err := p.Execute(); err == nil {
+		t.Errorf("A cyclic graph should return an error.")
+	}
+}
+
+func TestDAGProcessMissingDependency(t *testing.T) {
+	p := NewDAGProcess(NewFixedProcess(2))
+	p.AddTask("a", []string{"b"}, func() {})
+
+	if err := p.Execute(); err == nil {
+		t.Errorf("A missing dependency should return an error.")
+	}
+}
+
+func TestDAGProcessDuplicateTask(t *testing.T) {
+	p := NewDAGProcess(NewFixedProcess(2))
+	p.AddTask("a", nil, func() {})
+
+	if err := p.AddTask("a", nil, func() {}); err == nil {
+		t.Errorf("A duplicate task should return an error.")
+	}
+}
+
+func TestDAGProcessReadyTasks(t *testing.T) {
+	var mutex sync.Mutex
+	var order []string
+
+	finish := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	// c only depends on b, so it should run while a is still executing.
+	p := NewDAGProcess(NewFixedProcess(2))
+	p.AddTask("a", nil, func() {
+		time.Sleep(100 * time.Millisecond)
+		finish("a")
+	})
+	p.AddTask("b", nil, func() { finish("b") })
+	p.AddTask("c", []string{"b"}, func() { finish("c") })
+
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(order) != 3 || order[2] != "a" {
+		t.Errorf("Task order, %v, should end with a.", order)
+	}
+}
+
+func TestDAGProcessStop(t *testing.T) {
+	p := NewDAGProcess(NewFixedProcess(4))
+
+	count := safeInt{}
+	p.AddTask("a", nil, func() {
+		count.add(1)
+		p.Stop()
+	})
+	p.AddTask("b", []string{"a"}, func() { count.add(1) })
+	p.AddTask("c", []string{"b"}, func() { count.add(1) })
+
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if count.get() != 1 {
+		t.Errorf("Finished task count, %d, should be 1.", count.get())
+	}
+}
@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"math"
+	"time"
+)
+
+// deadlineEstimator types calculate the number of routines a variable process
+// needs to finish its remaining iterations by a deadline.
+type deadlineEstimator struct {
+	// The time the execution should finish by.
+	deadline time.Time
+
+	// The time and number of started iterations at the last estimate.
+	lastTime    time.Time
+	lastStarted int
+
+	// The smoothed number of iterations a single routine completes per second.
+	rate float64
+}
+
+// MARK: Private methods
+
+// reset prepares the estimator for an execution that starts at now.
+func (d *deadlineEstimator) reset(now time.Time) {
+	d.lastTime = now
+	d.lastStarted = 0
+	d.rate = 0.0
+}
+
+// next returns the number of routines needed to finish the remaining
+// iterations by the deadline, and the relative shortfall of the current rate
+// from the required rate. started is the number of iterations that have been
+// started and routines is the number of routines that started them.
+//
+// Until a rate has been measured the current number of routines is returned.
+// Once the deadline has passed, math.Inf(1) is returned so that the process
+// runs as many routines as it may.
+func (d *deadlineEstimator) next(now time.Time, started int, remaining int, routines int) (float64, float64) {
+	dt := now.Sub(d.lastTime).Seconds()
+	if dt > 0.0 && routines > 0 {
+		rate := float64(started-d.lastStarted) / dt / float64(routines)
+		if d.rate == 0.0 {
+			d.rate = rate
+		} else {
+			d.rate = 0.5*d.rate + 0.5*rate
+		}
+	}
+	d.lastTime = now
+	d.lastStarted = started
+
+	left := d.deadline.Sub(now).Seconds()
+	if left <= 0.0 {
+		return math.Inf(1), 1.0
+	}
+
+	if d.rate == 0.0 || remaining <= 0 {
+		return float64(routines), 0.0
+	}
+
+	required := float64(remaining) / left
+	e := (required - d.rate*float64(routines)) / required
+	return required / d.rate, e
+}
@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestDeadlineEstimator(t *testing.T) {
+	now := time.Now()
+	d := &deadlineEstimator{deadline: now.Add(10 * time.Second)}
+	d.reset(now)
+
+	// 2 routines complete 100 iterations per second each, leaving 9000
+	// iterations for the remaining 9 seconds.
+	now = now.Add(time.Second)
+	u, e := d.next(now, 200, 9000, 2)
+	if math.Abs(u-10.0) > 1e-9 {
+		t.Errorf("Routines, %f, should be 10.", u)
+	}
+
+	if math.Abs(e-0.8) > 1e-9 {
+		t.Errorf("Error, %f, should be 0.8.", e)
+	}
+
+	now = now.Add(10 * time.Second)
+	if u, _ = d.next(now, 1000, 8200, 10); !math.IsInf(u, 1) {
+		t.Errorf("Routines, %f, should be infinite after the deadline.", u)
+	}
+}
+
+func TestVariableProcessDeadline(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(10*time.Millisecond, 1, 16, c, false)
+
+	start := time.Now()
+	p.ExecuteDeadline(start.Add(500*time.Millisecond), 400, func(i int) {
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	// A single routine would take 2 seconds.
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Execution took %s, but should have scaled to meet its deadline.", elapsed)
+	}
+
+	if progress := p.Progress(); progress.Completed != 400 {
+		t.Errorf("Completed iterations, %d, should be 400.", progress.Completed)
+	}
+}
@@ -0,0 +1,235 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugHistoryLength is the number of optimizations a debug handler keeps for
+// each variable process.
+const debugHistoryLength = 120
+
+// DebugHandler types serve the live state of registered processes, in the
+// manner of net/http/pprof, so operators can inspect a running job without
+// external monitoring. Each process' stats are rendered, and for variable
+// processes the handler also renders the controller's state and the routine
+// counts of recent optimizations.
+//
+// The handler renders HTML unless the request's format query parameter is
+// json or it accepts application/json.
+type DebugHandler struct {
+	// A mutex to protect the registered processes.
+	mutex sync.Mutex
+
+	// The registered processes keyed by name.
+	entries map[string]*debugEntry
+}
+
+// debugEntry types are processes registered with a debug handler.
+type debugEntry struct {
+	process Process
+
+	// The recorder of a variable process' optimizations, or nil.
+	recorder *debugRecorder
+}
+
+// debugRecorder types record the recent optimizations of a variable process.
+type debugRecorder struct {
+	mutex   sync.Mutex
+	samples []debugSample
+}
+
+// debugSample types are the controller's signals at an optimization.
+type debugSample struct {
+	Time time.Time `json:"time"`
+	ControllerSample
+}
+
+// debugProcess types describe the state of a registered process.
+type debugProcess struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Stats      ProcessStats  `json:"stats"`
+	Controller *PIDState     `json:"controller,omitempty"`
+	History    []debugSample `json:"history,omitempty"`
+}
+
+// debugTemplate renders the state of a debug handler's processes.
+var debugTemplate = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"sparkline": debugSparkline,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>/debug/parallel</title></head>
+<body>
+{{range .}}<h2>{{.Name}} ({{.Type}})</h2>
+<table>
+<tr><td>Routines</td><td>{{.Stats.Routines}}</td></tr>
+<tr><td>Progress</td><td>{{.Stats.Completed}} / {{.Stats.Iterations}}</td></tr>
+<tr><td>Elapsed</td><td>{{.Stats.Elapsed}}</td></tr>
+{{with .Controller}}<tr><td>Error</td><td>{{printf "%.4f" .Error}}</td></tr>
+<tr><td>Integral</td><td>{{printf "%.4f" .Integral}}</td></tr>
+<tr><td>Derivative</td><td>{{printf "%.4f" .Derivative}}</td></tr>
+<tr><td>Output</td><td>{{printf "%.4f" .Output}}</td></tr>
+{{end}}</table>
+{{if .History}}<p>Routines over the last {{len .History}} optimizations</p>
+<svg width="480" height="80" viewBox="0 0 480 80"><polyline fill="none" stroke="black" points="{{sparkline .History}}"/></svg>
+{{end}}{{else}}<p>No processes are registered.</p>
+{{end}}</body>
+</html>
+`))
+
+// MARK: Initializers
+
+// NewDebugHandler creates and returns a new debug handler without any
+// registered processes.
+func NewDebugHandler() *DebugHandler {
+	return &DebugHandler{
+		entries: make(map[string]*debugEntry),
+	}
+}
+
+// MARK: Public methods
+
+// Register registers process under name. Variable processes are sent a
+// listener that records their optimizations until they're unregistered.
+func (h *DebugHandler) Register(name string, process Process) {
+	if process == nil {
+		misuse("DebugHandler.Register called with a nil process")
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.entries[name]; ok {
+		misuse("DebugHandler.Register called with the name %q, which is already registered", name)
+	}
+
+	entry := &debugEntry{process: process}
+	if p, ok := process.(*VariableProcess); ok {
+		entry.recorder = &debugRecorder{}
+		p.AddListener(entry.recorder)
+	}
+	h.entries[name] = entry
+}
+
+// Unregister unregisters the process registered under name and returns
+// whether or not one was registered.
+func (h *DebugHandler) Unregister(name string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	entry, ok := h.entries[name]
+	if !ok {
+		return false
+	}
+
+	if entry.recorder != nil {
+		entry.process.(*VariableProcess).RemoveListener(entry.recorder)
+	}
+	delete(h.entries, name)
+	return true
+}
+
+// ServeHTTP renders the state of the registered processes.
+func (h *DebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	processes := h.processes()
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Processes []debugProcess `json:"processes"`
+		}{processes})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := debugTemplate.Execute(w, processes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// OnOptimize records the optimization's sample.
+func (r *debugRecorder) OnOptimize(sample ControllerSample) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.samples) == debugHistoryLength {
+		r.samples = append(r.samples[:0], r.samples[1:]...)
+	}
+	r.samples = append(r.samples, debugSample{Time: time.Now(), ControllerSample: sample})
+}
+
+// OnRoutineAdded does nothing.
+func (r *debugRecorder) OnRoutineAdded(n int) {}
+
+// OnRoutineRemoved does nothing.
+func (r *debugRecorder) OnRoutineRemoved(n int) {}
+
+// OnComplete does nothing.
+func (r *debugRecorder) OnComplete(report *RunSummary) {}
+
+// MARK: Private methods
+
+// processes returns the state of the registered processes sorted by name.
+func (h *DebugHandler) processes() []debugProcess {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	processes := make([]debugProcess, 0, len(h.entries))
+	for name, entry := range h.entries {
+		state := debugProcess{
+			Name:  name,
+			Type:  strings.TrimPrefix(fmt.Sprintf("%T", entry.process), "*parallel."),
+			Stats: entry.process.Stats(),
+		}
+		if p, ok := entry.process.(*VariableProcess); ok {
+			controller := p.ControllerState()
+			state.Controller = &controller
+		}
+		if entry.recorder != nil {
+			state.History = entry.recorder.history()
+		}
+		processes = append(processes, state)
+	}
+
+	sort.Slice(processes, func(i int, j int) bool {
+		return processes[i].Name < processes[j].Name
+	})
+	return processes
+}
+
+// history returns a copy of the recorded samples.
+func (r *debugRecorder) history() []debugSample {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]debugSample(nil), r.samples...)
+}
+
+// MARK: Private functions
+
+// debugSparkline returns the points of a 480x80 polyline plotting the routine
+// counts of samples.
+func debugSparkline(samples []debugSample) string {
+	peak := 1.0
+	for _, s := range samples {
+		if s.Routines > peak {
+			peak = s.Routines
+		}
+	}
+
+	points := make([]string, len(samples))
+	for i, s := range samples {
+		x := 0.0
+		if len(samples) > 1 {
+			x = 480.0 * float64(i) / float64(len(samples)-1)
+		}
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, 80.0-76.0*s.Routines/peak)
+	}
+	return strings.Join(points, " ")
+}
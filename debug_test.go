@@ -0,0 +1,101 @@
+package parallel
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestDebugHandlerJSON(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	variable := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	fixed := NewFixedProcess(2)
+
+	h := NewDebugHandler()
+	h.Register("variable", variable)
+	h.Register("fixed", fixed)
+
+	variable.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+	fixed.Execute(10, func(i int) {})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/parallel?format=json", nil))
+
+	var state struct {
+		Processes []debugProcess `json:"processes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(state.Processes) != 2 {
+		t.Fatalf("Processes, %d, should be 2.", len(state.Processes))
+	}
+
+	f, v := state.Processes[0], state.Processes[1]
+	if f.Name != "fixed" || f.Type != "FixedProcess" || v.Name != "variable" || v.Type != "VariableProcess" {
+		t.Errorf("The processes, %s (%s) and %s (%s), should be sorted by name.", f.Name, f.Type, v.Name, v.Type)
+	}
+	if f.Stats.Completed != 10 || f.Controller != nil || len(f.History) != 0 {
+		t.Errorf("The fixed process' state, %+v, should have completed 10 iterations without a controller.", f)
+	}
+	if v.Stats.Completed != 100 || v.Controller == nil {
+		t.Errorf("The variable process' state, %+v, should have completed 100 iterations with a controller.", v)
+	}
+	if len(v.History) == 0 || len(v.History) > debugHistoryLength {
+		t.Errorf("The variable process' history length, %d, should be between 1 and %d.", len(v.History), debugHistoryLength)
+	}
+}
+
+func TestDebugHandlerHTML(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	h := NewDebugHandler()
+	h.Register("<thumbnails>", p)
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/parallel", nil))
+
+	body := w.Body.String()
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Content type, %s, should be HTML.", w.Header().Get("Content-Type"))
+	}
+	for _, s := range []string{"&lt;thumbnails&gt; (VariableProcess)", "Integral", "<polyline"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("The page should contain %q.", s)
+		}
+	}
+
+	if !h.Unregister("<thumbnails>") {
+		t.Errorf("The process should have been registered.")
+	}
+	if len(p.listeners.get()) != 0 {
+		t.Errorf("Unregistering a variable process should remove its listener.")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/parallel", nil))
+	if !strings.Contains(w.Body.String(), "No processes are registered.") {
+		t.Errorf("The page should say that no processes are registered.")
+	}
+}
+
+func TestMisuseDebugHandlerRegister(t *testing.T) {
+	h := NewDebugHandler()
+	h.Register("fixed", NewFixedProcess(1))
+	expectMisuse(t, "already registered", func() {
+		h.Register("fixed", NewFixedProcess(1))
+	})
+	expectMisuse(t, "nil process", func() {
+		h.Register("nil", nil)
+	})
+}
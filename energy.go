@@ -0,0 +1,86 @@
+package parallel
+
+import "math"
+
+// energyTolerance is the relative change in energy per iteration between
+// optimizations that an energy optimizer considers noise rather than a
+// regression.
+const energyTolerance = 0.05
+
+// energyReporter types report the energy used by the machine between
+// successive calls to their energy method.
+type energyReporter interface {
+	energy() (float64, bool)
+	reset()
+}
+
+// energyOptimizer types choose the number of routines that minimizes the
+// energy used per iteration. The optimizer climbs one routine at a time in
+// one direction and turns around when the energy per iteration regresses.
+type energyOptimizer struct {
+	// The number of routines the optimizer calls for.
+	routines float64
+
+	// The direction the routines are moving in, either 1 or -1.
+	direction float64
+
+	// The number of iterations that had begun at the last optimization.
+	started int
+
+	// The joules per iteration measured at the last optimization, or zero
+	// if there is no measurement to compare to.
+	cost float64
+}
+
+// MARK: Initializers
+
+// newEnergyReporter creates and returns a new reporter of the machine's
+// energy usage, or nil if the machine's energy can't be read.
+func newEnergyReporter() energyReporter {
+	if r := newRAPLReporter(); r != nil {
+		return r
+	}
+	return nil
+}
+
+// MARK: Private methods
+
+// reset resets the optimizer to call for the specified number of routines,
+// counting iterations from started.
+func (o *energyOptimizer) reset(routines int, started int) {
+	o.routines = float64(routines)
+	o.direction = 1.0
+	o.started = started
+	o.cost = 0.0
+}
+
+// next returns the number of routines to use given that joules were used
+// while the iterations that had begun by started ran, along with the relative
+// change in joules per iteration since the last optimization.
+func (o *energyOptimizer) next(joules float64, started int, maxRoutines int) (float64, float64) {
+	iterations := started - o.started
+	if iterations <= 0 {
+		return o.routines, 0.0
+	}
+	o.started = started
+
+	cost := joules / float64(iterations)
+	change := 0.0
+	if o.cost > 0.0 {
+		change = (cost - o.cost) / o.cost
+	}
+
+	if change > energyTolerance {
+		o.direction = -o.direction
+	}
+	o.cost = cost
+
+	upper := math.Max(1.0, float64(maxRoutines))
+	o.routines += o.direction
+	if o.routines < 1.0 || o.routines > upper {
+		o.direction = -o.direction
+		o.routines = math.Min(math.Max(o.routines, 1.0), upper)
+	}
+
+	return o.routines, change
+}
@@ -0,0 +1,122 @@
+package parallel
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// raplRoot is the directory the kernel's powercap zones are listed in.
+const raplRoot = "/sys/class/powercap"
+
+// raplReporter types report the energy used by the machine's packages from
+// the energy counters of Intel's Running Average Power Limit interface, which
+// AMD processors provide as well.
+type raplReporter struct {
+	zones []raplZone
+}
+
+// raplZone types are the energy counters of a single package.
+type raplZone struct {
+	// The zone's directory.
+	path string
+
+	// The value the counter wraps around at, in microjoules.
+	maxRange uint64
+
+	// The counter's value at the last measurement, in microjoules.
+	last uint64
+}
+
+// MARK: Initializers
+
+// newRAPLReporter creates and returns a new RAPL reporter, or nil if no
+// package's energy counter can be read. Reading the counters usually needs
+// root privileges.
+func newRAPLReporter() *raplReporter {
+	paths, _ := filepath.Glob(filepath.Join(raplRoot, "intel-rapl:*"))
+
+	r := &raplReporter{}
+	for _, path := range paths {
+		// Subzones such as intel-rapl:0:0 are part of their package.
+		if strings.Count(filepath.Base(path), ":") != 1 {
+			continue
+		}
+
+		maxRange, ok := readMicrojoules(filepath.Join(path, "max_energy_range_uj"))
+		if !ok {
+			continue
+		}
+
+		last, ok := readMicrojoules(filepath.Join(path, "energy_uj"))
+		if !ok {
+			continue
+		}
+
+		r.zones = append(r.zones, raplZone{
+			path:     path,
+			maxRange: maxRange,
+			last:     last,
+		})
+	}
+
+	if len(r.zones) == 0 {
+		return nil
+	}
+	return r
+}
+
+// MARK: Private methods
+
+// energy returns the joules used by the machine's packages since the last
+// call to energy or reset.
+func (r *raplReporter) energy() (float64, bool) {
+	var total uint64
+	for i := range r.zones {
+		zone := &r.zones[i]
+		value, ok := readMicrojoules(filepath.Join(zone.path, "energy_uj"))
+		if !ok {
+			return 0.0, false
+		}
+
+		total += raplDelta(zone.last, value, zone.maxRange)
+		zone.last = value
+	}
+
+	return float64(total) / 1e6, true
+}
+
+// reset restarts the reporter's measurements.
+func (r *raplReporter) reset() {
+	for i := range r.zones {
+		if value, ok := readMicrojoules(filepath.Join(r.zones[i].path, "energy_uj")); ok {
+			r.zones[i].last = value
+		}
+	}
+}
+
+// MARK: Private functions
+
+// readMicrojoules returns the number in the file at path.
+func readMicrojoules(path string) (uint64, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// raplDelta returns the microjoules a counter that wraps around at maxRange
+// counted going from last to value.
+func raplDelta(last uint64, value uint64, maxRange uint64) uint64 {
+	if value >= last {
+		return value - last
+	}
+	return maxRange - last + value
+}
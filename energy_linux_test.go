@@ -0,0 +1,41 @@
+package parallel
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestRAPLDelta(t *testing.T) {
+	if d := raplDelta(100, 250, 1000); d != 150 {
+		t.Errorf("Delta, %d, should be 150.", d)
+	}
+
+	if d := raplDelta(900, 50, 1000); d != 150 {
+		t.Errorf("Delta across a wrap around, %d, should be 150.", d)
+	}
+}
+
+func TestReadMicrojoules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Error creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "energy_uj")
+	if err = ioutil.WriteFile(path, []byte("123456\n"), 0644); err != nil {
+		t.Fatalf("Error writing %s: %v", path, err)
+	}
+
+	if value, ok := readMicrojoules(path); !ok || value != 123456 {
+		t.Errorf("Microjoules, %d (%t), should be 123456.", value, ok)
+	}
+
+	if _, ok := readMicrojoules(filepath.Join(dir, "missing")); ok {
+		t.Errorf("A missing file shouldn't be read.")
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package parallel
+
+// raplReporter types are only available on Linux.
+type raplReporter struct{}
+
+// MARK: Initializers
+
+// newRAPLReporter returns nil, since the RAPL counters are only read on Linux.
+func newRAPLReporter() *raplReporter {
+	return nil
+}
+
+// MARK: Private methods
+
+// energy reports that no energy was measured.
+func (r *raplReporter) energy() (float64, bool) {
+	return 0.0, false
+}
+
+// reset does nothing.
+func (r *raplReporter) reset() {}
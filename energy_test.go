@@ -0,0 +1,72 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestEnergyOptimizerFindsMinimum(t *testing.T) {
+	var o energyOptimizer
+	o.reset(1, 0)
+
+	// The energy per iteration is lowest with 4 routines.
+	started := 0
+	for i := 0; i < 40; i++ {
+		cost := 1.0 + math.Pow(o.routines-4.0, 2.0)
+		started += 100
+		o.next(cost*100.0, started, 16)
+	}
+
+	if math.Abs(o.routines-4.0) > 1.0 {
+		t.Errorf("Routines, %f, should settle around 4.", o.routines)
+	}
+}
+
+func TestEnergyOptimizerBounds(t *testing.T) {
+	var o energyOptimizer
+	o.reset(2, 0)
+
+	// Constant costs never regress, so the routines climb to the maximum and
+	// turn around there.
+	for i := 1; i <= 10; i++ {
+		u, _ := o.next(10.0, i*10, 4)
+		if u < 1.0 || u > 4.0 {
+			t.Errorf("Routines, %f, should be between 1 and 4.", u)
+		}
+	}
+}
+
+func TestEnergyOptimizerHolds(t *testing.T) {
+	var o energyOptimizer
+	o.reset(3, 0)
+
+	if u, _ := o.next(10.0, 0, 8); u != 3.0 {
+		t.Errorf("Routines, %f, should hold at 3 without new iterations.", u)
+	}
+}
+
+func TestVariableProcessEnergy(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, c, false)
+	p.SetOptimizer(OptimizerEnergy)
+
+	v := make([]int, 200)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Configuration.Optimizer != "energy" {
+		t.Errorf("Summary optimizer, %s, should be energy.", s.Configuration.Optimizer)
+	}
+}
@@ -0,0 +1,70 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// eventBufferSize is the number of events buffered by a process' event
+// channel.
+const eventBufferSize = 64
+
+// Event types describe an optimization of a variable process.
+type Event struct {
+	// The number of the optimization in the process' current run of
+	// executions, starting at one.
+	Tick int
+
+	// The time of the optimization.
+	Time time.Time
+
+	// The CPU usage the controller responded to, its error and its output.
+	Usage  float64
+	Error  float64
+	Output float64
+
+	// The number of routines the process uses after the optimization.
+	// Routines being removed are counted until they finish their current
+	// iterations and park, and woken routines are counted once the
+	// optimization wakes them.
+	Routines int
+}
+
+// eventStream types deliver events to a buffered channel that is created the
+// first time it is requested.
+type eventStream struct {
+	mutex sync.Mutex
+	c     chan Event
+}
+
+// MARK: Private methods
+
+// channel returns the stream's channel, creating it if necessary.
+func (s *eventStream) channel() chan Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.c == nil {
+		s.c = make(chan Event, eventBufferSize)
+	}
+	return s.c
+}
+
+// send sends event if the stream's channel has been created and isn't full,
+// and returns whether or not it was sent.
+func (s *eventStream) send(event Event) bool {
+	s.mutex.Lock()
+	c := s.c
+	s.mutex.Unlock()
+
+	if c == nil {
+		return false
+	}
+
+	select {
+	case c <- event:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,63 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestVariableProcessEvents(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	events := p.Events()
+	if p.Events() != events {
+		t.Errorf("Events should return the same channel.")
+	}
+
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	received := 0
+	for received < eventBufferSize {
+		select {
+		case event := <-events:
+			received++
+			if event.Tick != received {
+				t.Errorf("Tick, %d, should be %d.", event.Tick, received)
+			}
+			if event.Routines < 1 || event.Routines > 4 {
+				t.Errorf("Event %+v should have between 1 and 4 routines.", event)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	if received == 0 {
+		t.Errorf("The process should send events while optimizing.")
+	}
+}
+
+func TestEventStreamSend(t *testing.T) {
+	var s eventStream
+	if s.send(Event{}) {
+		t.Errorf("Events shouldn't be sent before the channel is created.")
+	}
+
+	c := s.channel()
+	for i := 0; i < eventBufferSize; i++ {
+		if !s.send(Event{Tick: i + 1}) {
+			t.Fatalf("Event %d should be sent.", i+1)
+		}
+	}
+	if s.send(Event{}) {
+		t.Errorf("Events should be dropped when the channel is full.")
+	}
+	if event := <-c; event.Tick != 1 {
+		t.Errorf("Tick, %d, should be 1.", event.Tick)
+	}
+}
@@ -1,6 +1,11 @@
 package parallel
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // FixedProcess types execute a specified number of operations on a given
 // number of goroutines.
@@ -9,15 +14,90 @@ type FixedProcess struct {
 	// operations.
 	numRoutines int
 
+	// Set to 1 once the current execution has been stopped.
+	stopped int32
+
 	// The process' wait group to use when waiting for goroutines to finish their
 	// execution.
 	group sync.WaitGroup
 
-	// The number of iterations in the current execution that have begun.
-	iteration safeInt
+	// The schedule used to divide iterations between routines.
+	schedule Schedule
+
+	// The optional function returning the cost of each iteration.
+	weight WeightFunction
+
+	// The fraction of execution time the adaptive schedule allows to be spent
+	// claiming iterations.
+	overheadTarget float64
+
+	// The number of iterations the dynamic schedule claims at a time, or zero
+	// if it is chosen automatically.
+	blockSize int
+
+	// The number of bytes each routine grows its stack to before executing.
+	stackHint int
+
+	// The CPUs the process' routines are pinned to in turn, or nil.
+	cpuAffinity []int
+
+	// The goroutines prepared to run the process' routines, or nil.
+	pool *routinePool
+
+	// Whether or not GOMAXPROCS is lowered to the number of routines while
+	// the process executes.
+	adjustMaxProcs bool
+
+	// The name the process' routines are labelled with in profiles.
+	name string
+
+	// The histogram of every operation's latency, or nil if latencies aren't
+	// recorded.
+	histogram *LatencyHistogram
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+
+	// The recorder tracing the process' scheduling events, or nil.
+	trace *TraceRecorder
+
+	// The cache the adaptive schedule's claiming overhead is stored in, or
+	// nil, and the workload it is stored for.
+	calibration         *CalibrationCache
+	calibrationWorkload string
+
+	// The time without a completed iteration after which the process is
+	// stalled, or zero, and the handler called when it is.
+	stallTimeout time.Duration
+	stallHandler StallHandler
+
+	// The stats of the current or last execution's routines.
+	routineStats routineStatsRecorder
+
+	// A mutex to protect scheduler from Stop.
+	schedulerMutex sync.Mutex
+
+	// The scheduler handing out iterations in the current execution, or nil if
+	// no execution has started.
+	scheduler scheduler
+
+	// The claimed and completed iteration counts of the current execution.
+	progress *progressCounter
 
-	// The total number of iterations specified by the last call to Execute.
-	iterations int
+	// The group whose goroutine budget the process' routines draw from.
+	processGroup *ProcessGroup
+
+	// The process' membership in its group during the current execution.
+	groupMember *groupMember
+
+	// The barrier pausing the process' routines for checkpoints.
+	checkpoints *checkpointBarrier
+
+	// The record of the last execution.
+	record executionRecord
+
+	// The timer of the current or last execution.
+	timer executionTimer
 }
 
 // MARK: Initializers
@@ -25,29 +105,70 @@ type FixedProcess struct {
 // NewFixedProcess creates and returns a new parallel process with the
 // specified number of goroutines.
 func NewFixedProcess(numRoutines int) *FixedProcess {
+	checkRoutines("NewFixedProcess", "numRoutines", numRoutines)
+
 	return &FixedProcess{
-		numRoutines: numRoutines,
+		numRoutines:    numRoutines,
+		overheadTarget: defaultOverheadTarget,
+		blockSize:      1,
+		progress:       &progressCounter{},
+		throughput:     newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+		checkpoints:    newCheckpointBarrier(),
 	}
 }
 
 // MARK: Public methods
 
-// Execute executes the fixed process for the specified number of operations.
+// Execute executes the fixed process for the specified number of operations
+// using the process' schedule. Execute returns immediately if iterations is
+// zero, and never runs more routines than there are iterations.
 func (p *FixedProcess) Execute(iterations int, operation Operation) {
-	p.iterations = iterations
-	p.iteration.set(0)
-	p.group.Add(p.numRoutines)
-	for n := 0; n < p.numRoutines; n++ {
-		go p.runRoutine(operation)
-	}
+	p.ExecuteSchedule(iterations, p.schedule, operation)
+}
 
-	p.group.Wait()
+// ExecuteSchedule executes the fixed process for the specified number of
+// operations using the given schedule instead of the process' schedule.
+func (p *FixedProcess) ExecuteSchedule(iterations int, schedule Schedule, operation Operation) {
+	checkIterations("FixedProcess.Execute", iterations)
+	checkOperation("FixedProcess.Execute", operation == nil)
+	p.execute(iterations, schedule, func(routine int) Operation {
+		return operation
+	})
+}
+
+// ExecuteRoutines executes the fixed process for the specified number of
+// operations using the process' schedule, passing each operation the context
+// of the routine executing it.
+func (p *FixedProcess) ExecuteRoutines(iterations int, operation RoutineOperation) {
+	checkIterations("FixedProcess.ExecuteRoutines", iterations)
+	checkOperation("FixedProcess.ExecuteRoutines", operation == nil)
+	p.execute(iterations, p.schedule, func(routine int) Operation {
+		return routineOperation(operation, routine, &p.stopped)
+	})
+}
+
+// ExecuteContext executes the fixed process for the specified number of
+// operations, passing ctx to each operation. The process stops when ctx is done
+// and the context's error is returned.
+func (p *FixedProcess) ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error {
+	checkOperation("FixedProcess.ExecuteContext", operation == nil)
+	err := executeContext(ctx, p, iterations, operation)
+	if err != nil {
+		p.record.err = err
+	}
+	return err
 }
 
 // Stop stops the fixed process after all of the current operations have
 // finished executing.
 func (p *FixedProcess) Stop() {
-	p.iteration.set(p.iterations)
+	p.schedulerMutex.Lock()
+	defer p.schedulerMutex.Unlock()
+
+	atomic.StoreInt32(&p.stopped, 1)
+	if p.scheduler != nil {
+		p.scheduler.stop()
+	}
 }
 
 // NumRoutines returns the number of routines that the synced processes was
@@ -56,14 +177,443 @@ func (p *FixedProcess) NumRoutines() int {
 	return p.numRoutines
 }
 
+// Progress returns the progress of the current or last execution. Routines
+// reconcile their counts periodically, so the progress of a running execution
+// may lag slightly behind; the progress of a finished execution is exact.
+func (p *FixedProcess) Progress() Progress {
+	return p.progress.snapshot()
+}
+
+// CheckpointNow pauses the process' routines before they claim any more
+// iterations, waits for the iterations in flight to finish, and calls writer
+// with the exact progress of the execution before resuming. Every claimed
+// iteration has completed when writer is called, so with ScheduleDynamic the
+// completed iterations are exactly the first Completed indices. Schedules that
+// hand out large chunks wait for the routines' current chunks to finish. If the
+// process isn't executing, writer receives the progress of the last execution.
+// It must not be called from the process' operations.
+func (p *FixedProcess) CheckpointNow(writer CheckpointWriter) error {
+	checkOperation("FixedProcess.CheckpointNow", writer == nil)
+	return p.checkpoints.checkpoint(p.progress, writer)
+}
+
+// Stats returns a snapshot of the current or last execution. The completed
+// iterations may lag behind those of a running execution like its progress.
+func (p *FixedProcess) Stats() ProcessStats {
+	progress := p.Progress()
+	return ProcessStats{
+		Routines:   p.NumRoutines(),
+		Completed:  progress.Completed,
+		Iterations: progress.Iterations,
+		Elapsed:    p.timer.elapsed(),
+	}
+}
+
+// Summary returns a summary of the last execution. Errors returned by
+// ExecuteContext are included; other errors can be added with RecordError.
+func (p *FixedProcess) Summary() *RunSummary {
+	s := p.record.summary(SummaryConfiguration{
+		Process:   "FixedProcess",
+		Routines:  p.numRoutines,
+		Schedule:  p.schedule.String(),
+		StackHint: p.stackHint,
+	}, p.Progress())
+	s.Stats.Routines = p.RoutineStats()
+	return s
+}
+
+// RoutineStats returns the number of iterations each routine of the current
+// or last execution completed and how long it was busy, so the distribution
+// of work can be checked. Routines are included once they exit.
+func (p *FixedProcess) RoutineStats() []RoutineStats {
+	return p.routineStats.snapshot()
+}
+
+// Schedule returns the schedule the process uses to divide iterations between
+// its routines.
+func (p *FixedProcess) Schedule() Schedule {
+	return p.schedule
+}
+
+// SetSchedule sets the schedule the process uses to divide iterations between
+// its routines. The schedule takes effect on the next call to Execute.
+func (p *FixedProcess) SetSchedule(schedule Schedule) {
+	p.schedule = schedule
+}
+
+// SetWeight sets a function returning the relative cost of each iteration.
+// The static, work-stealing and guided schedules use it to give routines
+// blocks and chunks of equal total cost rather than equal length, which
+// balances workloads with a known skew. The weight of every iteration is
+// evaluated once before execution begins. A nil function treats iterations as
+// equally costly.
+func (p *FixedProcess) SetWeight(weight WeightFunction) {
+	p.weight = weight
+}
+
+// OverheadTarget returns the fraction of execution time the adaptive schedule
+// allows to be spent claiming iterations.
+func (p *FixedProcess) OverheadTarget() float64 {
+	return p.overheadTarget
+}
+
+// SetOverheadTarget sets the fraction of execution time the adaptive schedule
+// allows to be spent claiming iterations. Smaller targets lead to larger
+// chunks. The target must be between 0 and 1 and defaults to 0.01.
+func (p *FixedProcess) SetOverheadTarget(target float64) {
+	if !(target > 0.0 && target < 1.0) {
+		misuse("FixedProcess.SetOverheadTarget called with a target of %g; the target must be between 0 and 1", target)
+	}
+	p.overheadTarget = target
+}
+
+// BlockSize returns the number of iterations the dynamic schedule claims at a
+// time, or zero if the block size is chosen automatically.
+func (p *FixedProcess) BlockSize() int {
+	return p.blockSize
+}
+
+// SetBlockSize sets the number of iterations the dynamic schedule's routines
+// claim with each update of the shared counter. Larger blocks reduce
+// contention on the counter when operations are cheap, and smaller blocks
+// balance expensive operations between routines. Zero sizes the blocks so
+// that each routine claims about 64 of them. The default is 1.
+//
+// Stopping the process lets the blocks claimed before the last one finish, so
+// with blocks larger than 1 up to a block per routine of iterations may begin
+// after Stop is called. The block size must not be negative.
+func (p *FixedProcess) SetBlockSize(n int) {
+	if n < 0 {
+		misuse("FixedProcess.SetBlockSize called with n = %d; the block size must not be negative", n)
+	}
+	p.blockSize = n
+}
+
+// StackHint returns the number of bytes each of the process' routines grows
+// its stack to before executing.
+func (p *FixedProcess) StackHint() int {
+	return p.stackHint
+}
+
+// SetStackHint sets the number of bytes each of the process' routines grows
+// its stack to before executing its first iteration. Operations that recurse
+// deeply otherwise outgrow their routines' stacks several times, and each
+// time the runtime copies the whole stack to a new one. A hint of zero, the
+// default, leaves the stacks alone. It must not be called while the process
+// is executing.
+func (p *FixedProcess) SetStackHint(bytes int) {
+	checkStackHint("FixedProcess.SetStackHint", bytes)
+	p.stackHint = bytes
+}
+
+// Prepare starts n goroutines that park until the process executes, so that
+// latency-critical callers don't pay for starting goroutines inside the timed
+// section. Each execution runs its routines on the prepared goroutines that
+// are idle and starts new goroutines for the rest, and the prepared
+// goroutines park again once their routines return. Calling Prepare again
+// replaces the prepared goroutines, and Prepare(0) exits them. It must not be
+// called while the process is executing.
+func (p *FixedProcess) Prepare(n int) {
+	checkPrepare("FixedProcess.Prepare", n)
+	if p.pool == nil {
+		p.pool = &routinePool{}
+	}
+	p.pool.resize(n)
+}
+
+// CPUAffinity returns the CPUs the process' routines are pinned to, or nil if
+// they aren't pinned.
+func (p *FixedProcess) CPUAffinity() []int {
+	return p.cpuAffinity
+}
+
+// SetCPUAffinity pins each of the process' routines to one of cpus, assigning
+// them in turn. A pinned routine locks itself to a thread restricted to its
+// CPU, which keeps its cache warm and its latency predictable at the cost of
+// the runtime's freedom to move it. A nil or empty slice, the default, leaves
+// the routines unpinned. Routines are only pinned on Linux. It must not be
+// called while the process is executing.
+func (p *FixedProcess) SetCPUAffinity(cpus []int) {
+	checkCPUAffinity("FixedProcess.SetCPUAffinity", cpus)
+	p.cpuAffinity = append([]int(nil), cpus...)
+}
+
+// AdjustMaxProcs returns whether or not GOMAXPROCS is lowered to the number of
+// the process' routines while it executes.
+func (p *FixedProcess) AdjustMaxProcs() bool {
+	return p.adjustMaxProcs
+}
+
+// SetAdjustMaxProcs sets whether or not GOMAXPROCS is lowered to the number of
+// the process' routines while it executes, and restored afterwards. Routines
+// of CPU-bound operations beyond GOMAXPROCS only add scheduler overhead, and
+// so do Ps beyond the number of routines. Processes adjusting GOMAXPROCS at
+// the same time share it, and it is never raised above its value before the
+// first of them began. It must not be called while the process is executing.
+func (p *FixedProcess) SetAdjustMaxProcs(enabled bool) {
+	p.adjustMaxProcs = enabled
+}
+
+// Throughput returns the meter measuring the number of iterations the process
+// completes per second.
+func (p *FixedProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// RecordLatencies returns whether or not the process records the latency of
+// each operation.
+func (p *FixedProcess) RecordLatencies() bool {
+	return p.histogram != nil
+}
+
+// SetRecordLatencies sets whether or not the process records the latency of
+// each of its operations in a histogram, which Latencies returns. Timing each
+// operation adds a small overhead, so latencies aren't recorded by default.
+// It must not be called while the process is executing.
+func (p *FixedProcess) SetRecordLatencies(enabled bool) {
+	if !enabled {
+		p.histogram = nil
+	} else if p.histogram == nil {
+		p.histogram = newLatencyHistogram()
+	}
+}
+
+// Latencies returns a snapshot of the histogram of the current or last
+// execution's operation latencies, or nil if the process doesn't record them.
+func (p *FixedProcess) Latencies() *LatencyHistogram {
+	if p.histogram == nil {
+		return nil
+	}
+	return p.histogram.snapshot()
+}
+
+// TraceRecorder returns the recorder tracing the process' scheduling events,
+// or nil if they aren't traced.
+func (p *FixedProcess) TraceRecorder() *TraceRecorder {
+	return p.trace
+}
+
+// SetTraceRecorder sets the recorder tracing the process' scheduling events.
+// Every chunk of iterations claimed by the process' routines is recorded.
+// Recording adds a small overhead to every claim. A nil recorder stops
+// tracing. It must not be called while the process is executing.
+func (p *FixedProcess) SetTraceRecorder(recorder *TraceRecorder) {
+	p.trace = recorder
+}
+
+// CalibrationCache returns the cache the process stores its adaptive
+// schedule's claiming overhead in, or nil, and the workload it is stored for.
+func (p *FixedProcess) CalibrationCache() (*CalibrationCache, string) {
+	return p.calibration, p.calibrationWorkload
+}
+
+// SetCalibrationCache sets the cache the process stores the claiming overhead
+// measured by its adaptive schedule in for workload. The overhead is stored
+// each time an execution with the adaptive schedule finishes, and seeds the
+// schedule's measurements in later executions so that it settles on a chunk
+// size sooner. Errors writing the cache are added to the process' summary. A
+// nil cache, the default, stores nothing. It must not be called while the
+// process is executing.
+func (p *FixedProcess) SetCalibrationCache(cache *CalibrationCache, workload string) {
+	p.calibration = cache
+	p.calibrationWorkload = workload
+}
+
+// StallTimeout returns the time without a completed iteration after which the
+// process' stall handler is called, or zero if the watchdog is disabled.
+func (p *FixedProcess) StallTimeout() time.Duration {
+	return p.stallTimeout
+}
+
+// SetStallTimeout sets the watchdog of the process' executions. If no
+// iteration completes for timeout while the process is executing, handler is
+// called with the process' stats so that a deadlocked or blocked operation can
+// be diagnosed, for example by logging the stacks returned by runtime.Stack.
+// The handler is called once per stall, from its own goroutine. A zero
+// timeout, the default, disables the watchdog. It must not be called while the
+// process is executing.
+func (p *FixedProcess) SetStallTimeout(timeout time.Duration, handler StallHandler) {
+	checkStallTimeout("FixedProcess.SetStallTimeout", timeout, handler)
+	p.stallTimeout = timeout
+	p.stallHandler = handler
+}
+
+// Name returns the name the process' routines are labelled with in profiles.
+func (p *FixedProcess) Name() string {
+	return p.name
+}
+
+// SetName sets the name the process' routines are labelled with in profiles.
+// The routines of a named process carry the pprof labels parallel.process,
+// the name, and parallel.routine, the routine's index, so that CPU profiles
+// attribute their samples to the process. An empty name, the default, leaves
+// the labels alone. It must not be called while the process is executing.
+func (p *FixedProcess) SetName(name string) {
+	p.name = name
+}
+
+// ProcessGroup returns the group whose goroutine budget the process draws
+// from, or nil if the process isn't a member of a group.
+func (p *FixedProcess) ProcessGroup() *ProcessGroup {
+	return p.processGroup
+}
+
+// SetProcessGroup makes the process a member of group, so that its routines
+// draw from the group's goroutine budget. When the budget, or the process'
+// fair share of it, is exhausted the process executes on fewer routines than
+// it was initialized with, but always at least one. A nil group removes the
+// process from its group. It must not be called while the process is
+// executing.
+func (p *FixedProcess) SetProcessGroup(group *ProcessGroup) {
+	p.processGroup = group
+}
+
 // MARK: Private methods
 
-func (p *FixedProcess) runRoutine(operation Operation) {
+// execute executes the fixed process for the specified number of operations
+// using the given schedule. Each routine calls the operation returned by
+// operations for its index.
+func (p *FixedProcess) execute(iterations int, schedule Schedule, operations func(routine int) Operation) {
+	atomic.StoreInt32(&p.stopped, 0)
+	p.record.begin(p.stackHint > 0)
+	defer p.record.end()
+	p.timer.start()
+	defer p.timer.stop()
+
+	if iterations == 0 {
+		p.progress.reset(0)
+		return
+	}
+
+	p.progress.reset(iterations)
+	p.routineStats.reset()
+	if p.histogram != nil {
+		p.histogram.reset()
+	}
+	numRoutines := minInt(p.numRoutines, iterations)
+	if p.processGroup != nil {
+		p.groupMember = p.processGroup.join()
+		defer p.processGroup.leave(p.groupMember)
+
+		numRoutines = p.processGroup.acquireAtLeastOne(p.groupMember, numRoutines)
+	}
+	p.record.observeRoutines(numRoutines)
+	if p.adjustMaxProcs {
+		defer globalMaxProcs.release(globalMaxProcs.acquire(numRoutines))
+	}
+	costs := newIterationCosts(iterations, p.weight)
+	p.setScheduler(newScheduler(schedule, iterations, numRoutines, costs, p.overheadTarget, p.cachedClaimOverhead(), p.blockSize))
+	watchdog := startWatchdog(p.stallTimeout, p.stallHandler, p.throughput, p.Stats)
+	defer watchdog.stop()
+	p.group.Add(numRoutines)
+	for n := 0; n < numRoutines; n++ {
+		routine := n
+		p.pool.run(func() {
+			p.runRoutine(routine, operations(routine))
+		})
+	}
+
+	p.group.Wait()
+
+	if err := p.saveCalibration(); err != nil && p.record.err == nil {
+		p.record.err = err
+	}
+}
+
+// setScheduler publishes the scheduler of the current execution, stopping it
+// if the process was stopped after the execution began.
+func (p *FixedProcess) setScheduler(s scheduler) {
+	p.schedulerMutex.Lock()
+	defer p.schedulerMutex.Unlock()
+
+	p.scheduler = s
+	if atomic.LoadInt32(&p.stopped) == 1 {
+		s.stop()
+	}
+}
+
+// wasStopped returns whether or not the last execution was stopped.
+func (p *FixedProcess) wasStopped() bool {
+	return atomic.LoadInt32(&p.stopped) == 1
+}
+
+// cachedClaimOverhead returns the claiming overhead stored in the process'
+// calibration cache, or zero.
+func (p *FixedProcess) cachedClaimOverhead() time.Duration {
+	if p.calibration == nil {
+		return 0
+	}
+
+	entry, _ := p.calibration.Get(p.calibrationWorkload)
+	return entry.ClaimOverhead
+}
+
+// saveCalibration stores the claiming overhead measured by the current
+// execution's adaptive schedule in the process' calibration cache, keeping the
+// rest of the workload's entry.
+func (p *FixedProcess) saveCalibration() error {
+	s, ok := p.scheduler.(*adaptiveScheduler)
+	if p.calibration == nil || !ok {
+		return nil
+	}
+
+	overhead := s.claimOverhead()
+	if overhead <= 0 {
+		return nil
+	}
+
+	entry, _ := p.calibration.Get(p.calibrationWorkload)
+	entry.Workload = p.calibrationWorkload
+	entry.ClaimOverhead = overhead
+	entry.Updated = time.Time{}
+	return p.calibration.Set(entry)
+}
+
+// runRoutine runs the routine-th routine, executing the iterations handed out
+// by the process' scheduler.
+func (p *FixedProcess) runRoutine(routine int, operation Operation) {
+	progress := routineProgress{counter: p.progress}
 	defer p.group.Done()
+	stats := RoutineStats{Routine: routine}
+	defer p.routineStats.record(&stats, time.Now())
+	p.checkpoints.enter()
+	defer p.checkpoints.leave()
+	defer progress.flush()
+	if p.processGroup != nil {
+		defer p.processGroup.release(p.groupMember, 1)
+	}
+	growStack(p.stackHint)
+	labelRoutine(context.Background(), p.name, routine)
+	if cpus := pinnedCPU(p.cpuAffinity, routine); cpus != nil {
+		defer bindToCPUs(cpus)()
+	}
+
+	for {
+		p.checkpoints.wait(&progress)
+		start, end, ok := p.scheduler.next(routine)
+		if !ok {
+			return
+		}
+		if p.trace != nil {
+			p.trace.claim(time.Now(), routine, start, end)
+		}
 
-	i := p.iteration.get()
-	for i < p.iterations {
-		operation(i)
-		i = p.iteration.add(1)
+		progress.claim(end - start)
+		for i := start; i < end; i++ {
+			if p.scheduler.stopped(i) {
+				progress.claim(i - end)
+				return
+			}
+			if p.histogram != nil {
+				start := time.Now()
+				operation(i)
+				p.histogram.record(time.Since(start))
+			} else {
+				operation(i)
+			}
+			progress.complete()
+			stats.Iterations++
+			p.throughput.Add(1)
+		}
 	}
 }
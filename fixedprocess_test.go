@@ -2,7 +2,10 @@ package parallel
 
 import (
 	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // MARK: Tests
@@ -63,6 +66,287 @@ func TestStopFixedProcess(t *testing.T) {
 	}
 }
 
+func TestStopFixedProcessConcurrently(t *testing.T) {
+	p := NewFixedProcess(2)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				p.Stop()
+			}
+		}
+	}()
+
+	for n := 0; n < 100; n++ {
+		p.Execute(1000, func(i int) {})
+	}
+	close(done)
+	<-stopped
+}
+
+func TestFixedProcessWorkStealingCompleteness(t *testing.T) {
+	v := make([]int, 100003)
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleWorkStealing)
+	p.Execute(len(v), func(i int) {
+		if i < len(v)/4 && i%1000 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestStopFixedProcessWorkStealing(t *testing.T) {
+	var count int64
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleWorkStealing)
+	p.Execute(1000000, func(i int) {
+		if atomic.AddInt64(&count, 1) == 1000 {
+			p.Stop()
+		}
+	})
+
+	if count > 1004 {
+		t.Errorf("Executed operation count, %d, should not continue after stopping.", count)
+	}
+}
+
+func TestFixedProcessGuidedCompleteness(t *testing.T) {
+	v := make([]int, 100003)
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(len(v), ScheduleGuided, func(i int) {
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if p.Schedule() != ScheduleDynamic {
+		t.Errorf("ExecuteSchedule should not change the process' schedule.")
+	}
+}
+
+func TestStopFixedProcessGuided(t *testing.T) {
+	var count int64
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(1000000, ScheduleGuided, func(i int) {
+		if atomic.AddInt64(&count, 1) == 1000 {
+			p.Stop()
+		}
+	})
+
+	if count > 1004 {
+		t.Errorf("Executed operation count, %d, should not continue after stopping.", count)
+	}
+}
+
+func TestFixedProcessStaticCompleteness(t *testing.T) {
+	v := make([]int, 100003)
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(len(v), ScheduleStatic, func(i int) {
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestStopFixedProcessStatic(t *testing.T) {
+	var count int64
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(1000000, ScheduleStatic, func(i int) {
+		if atomic.AddInt64(&count, 1) == 1000 {
+			p.Stop()
+		}
+	})
+
+	if count > 1004 {
+		t.Errorf("Executed operation count, %d, should not continue after stopping.", count)
+	}
+}
+
+func TestFixedProcessAdaptiveCompleteness(t *testing.T) {
+	v := make([]int, 100003)
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(len(v), ScheduleAdaptive, func(i int) {
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestStopFixedProcessAdaptive(t *testing.T) {
+	var count int64
+	p := NewFixedProcess(4)
+	p.ExecuteSchedule(1000000, ScheduleAdaptive, func(i int) {
+		if atomic.AddInt64(&count, 1) == 1000 {
+			p.Stop()
+		}
+	})
+
+	if count > 1004 {
+		t.Errorf("Executed operation count, %d, should not continue after stopping.", count)
+	}
+}
+
+func TestAdaptiveChunkSize(t *testing.T) {
+	if n := adaptiveChunkSize(time.Millisecond, 100*time.Nanosecond, 0.01); n != 1 {
+		t.Errorf("Chunk size, %d, should be 1 for slow iterations.", n)
+	}
+
+	if n := adaptiveChunkSize(10*time.Nanosecond, 100*time.Nanosecond, 0.01); n != 1000 {
+		t.Errorf("Chunk size, %d, should be 1000 for fast iterations.", n)
+	}
+}
+
+func TestDynamicBlockSize(t *testing.T) {
+	if n := dynamicBlockSize(1000000, 2); n != 7812 {
+		t.Errorf("Block size, %d, should be 7812.", n)
+	}
+
+	if n := dynamicBlockSize(100, 4); n != 1 {
+		t.Errorf("Block size, %d, should be 1 for small executions.", n)
+	}
+}
+
+func TestDynamicSchedulerBlocks(t *testing.T) {
+	s := newDynamicScheduler(10, 2, 4)
+	for _, expected := range [][2]int{{0, 4}, {4, 8}, {8, 10}} {
+		start, end, ok := s.next(0)
+		if !ok || start != expected[0] || end != expected[1] {
+			t.Errorf("Block, [%d, %d), should be [%d, %d).", start, end, expected[0], expected[1])
+		}
+	}
+
+	if _, _, ok := s.next(1); ok {
+		t.Error("No blocks should remain.")
+	}
+}
+
+func TestFixedProcessBlockSizeCompleteness(t *testing.T) {
+	v := make([]int, 100003)
+	p := NewFixedProcess(4)
+	p.SetBlockSize(7)
+	p.Execute(len(v), func(i int) {
+		v[i]++
+	})
+
+	if p.BlockSize() != 7 {
+		t.Errorf("Block size, %d, should be 7.", p.BlockSize())
+	}
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestStopFixedProcessBlocks(t *testing.T) {
+	v := make([]int, 100000)
+	p := NewFixedProcess(4)
+	p.SetBlockSize(100)
+	p.Execute(len(v), func(i int) {
+		if i == len(v)/2 {
+			p.Stop()
+		}
+		v[i] = 1
+	})
+
+	last := 0
+	for i, value := range v {
+		if value == 1 {
+			last = i
+		}
+	}
+
+	for i := 0; i < last; i++ {
+		if v[i] != 1 {
+			t.Errorf("Index %d wasn't executed, so the executed indices aren't a prefix.", i)
+			break
+		}
+	}
+
+	if last >= len(v)/2+4*100 {
+		t.Errorf("Index %d was executed after stopping.", last)
+	}
+}
+
+func TestStopFixedProcessDefaultBlockSize(t *testing.T) {
+	var stopped int32
+	var begun int64
+	p := NewFixedProcess(4)
+	p.Execute(4000000, func(i int) {
+		if atomic.LoadInt32(&stopped) == 1 {
+			atomic.AddInt64(&begun, 1)
+		}
+		if i == 3000000 {
+			p.Stop()
+			atomic.StoreInt32(&stopped, 1)
+		}
+	})
+
+	if p.BlockSize() != 1 {
+		t.Errorf("Block size, %d, should default to 1.", p.BlockSize())
+	}
+
+	// Each of the other routines may have claimed an iteration before Stop
+	// that it begins afterwards.
+	if begun > 3 {
+		t.Errorf("%d iterations began after the process was stopped.", begun)
+	}
+}
+
+func TestFixedProcessZeroIterations(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.Execute(0, func(i int) {
+		t.Errorf("No operations should be executed.")
+	})
+}
+
+func TestFixedProcessTinyRun(t *testing.T) {
+	for _, schedule := range []Schedule{ScheduleDynamic, ScheduleWorkStealing, ScheduleGuided, ScheduleStatic, ScheduleAdaptive} {
+		v := make([]int, 3)
+		p := NewFixedProcess(8)
+		p.ExecuteSchedule(len(v), schedule, func(i int) {
+			v[i]++
+		})
+
+		for i, value := range v {
+			if value != 1 {
+				t.Errorf("Index %d was executed %d times with schedule %d.", i, value, schedule)
+			}
+		}
+	}
+}
+
 // MARK: Benchmarks
 
 func BenchmarkFixedProcess_01(b *testing.B) {
@@ -86,3 +370,66 @@ func BenchmarkFixedProcess_02(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkFixedProcessWorkStealing(b *testing.B) {
+	v := make([]float64, 1000000)
+	p := NewFixedProcess(2)
+	p.SetSchedule(ScheduleWorkStealing)
+
+	for n := 0; n < b.N; n++ {
+		p.Execute(len(v), func(i int) {
+			v[i] = math.Sqrt(float64(i))
+		})
+	}
+}
+
+func BenchmarkFixedProcessGuided(b *testing.B) {
+	v := make([]float64, 1000000)
+	p := NewFixedProcess(2)
+
+	for n := 0; n < b.N; n++ {
+		p.ExecuteSchedule(len(v), ScheduleGuided, func(i int) {
+			v[i] = math.Sqrt(float64(i))
+		})
+	}
+}
+
+func BenchmarkFixedProcessAdaptive(b *testing.B) {
+	v := make([]float64, 1000000)
+	p := NewFixedProcess(2)
+
+	for n := 0; n < b.N; n++ {
+		p.ExecuteSchedule(len(v), ScheduleAdaptive, func(i int) {
+			v[i] = math.Sqrt(float64(i))
+		})
+	}
+}
+
+func BenchmarkFixedProcessStatic(b *testing.B) {
+	v := make([]float64, 1000000)
+	p := NewFixedProcess(2)
+
+	for n := 0; n < b.N; n++ {
+		p.ExecuteSchedule(len(v), ScheduleStatic, func(i int) {
+			v[i] = math.Sqrt(float64(i))
+		})
+	}
+}
+
+func BenchmarkManualSplit(b *testing.B) {
+	v := make([]float64, 1000000)
+
+	for n := 0; n < b.N; n++ {
+		var group sync.WaitGroup
+		group.Add(2)
+		for r := 0; r < 2; r++ {
+			go func(start int, end int) {
+				defer group.Done()
+				for i := start; i < end; i++ {
+					v[i] = math.Sqrt(float64(i))
+				}
+			}(r*len(v)/2, (r+1)*len(v)/2)
+		}
+		group.Wait()
+	}
+}
@@ -0,0 +1,219 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// ForkJoinProcess types execute a tree of tasks on a fixed number of
+// goroutines. Tasks may spawn child tasks that are executed by the same
+// goroutines, and the process waits for the whole tree to finish, which allows
+// recursive divide-and-conquer algorithms to be expressed.
+type ForkJoinProcess struct {
+	// The number of goroutines the process uses to execute tasks.
+	numRoutines int
+
+	// The process' wait group to use when waiting for goroutines to finish their
+	// execution.
+	group sync.WaitGroup
+
+	// A mutex to protect the queue and the process' state.
+	mutex sync.Mutex
+
+	// A condition signaled when tasks are added or the tree finishes.
+	cond *sync.Cond
+
+	// The tasks waiting to be executed. Tasks are executed in last-in first-out
+	// order so that recursive trees are executed depth-first.
+	queue []Task
+
+	// The number of tasks that have been spawned but have not finished.
+	pending int
+
+	// Whether or not the process has been stopped.
+	stopped bool
+
+	// The number of tasks that have finished in the current execution.
+	completed int
+
+	// The timer of the current or last execution.
+	timer executionTimer
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// MARK: Initializers
+
+// NewForkJoinProcess creates and returns a new fork-join process with the
+// specified number of goroutines.
+func NewForkJoinProcess(numRoutines int) *ForkJoinProcess {
+	checkRoutines("NewForkJoinProcess", "numRoutines", numRoutines)
+
+	p := &ForkJoinProcess{
+		numRoutines: numRoutines,
+		throughput:  newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+	p.cond = sync.NewCond(&p.mutex)
+	return p
+}
+
+// MARK: Public methods
+
+// Execute executes the fork-join process for the specified number of
+// operations. The iteration space is recursively split into child tasks.
+func (p *ForkJoinProcess) Execute(iterations int, operation Operation) {
+	checkIterations("ForkJoinProcess.Execute", iterations)
+	checkOperation("ForkJoinProcess.Execute", operation == nil)
+
+	var split func(start int, end int) Task
+	split = func(start int, end int) Task {
+		return func() {
+			for end-start > 1 {
+				middle := start + (end-start)/2
+				p.Spawn(split(middle, end))
+				end = middle
+			}
+			operation(start)
+		}
+	}
+
+	if iterations > 0 {
+		p.executeTask(split(0, iterations), minInt(p.numRoutines, iterations))
+	}
+}
+
+// ExecuteContext executes the fork-join process for the specified number of
+// operations, passing ctx to each operation. The process stops when ctx is done
+// and the context's error is returned.
+func (p *ForkJoinProcess) ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error {
+	checkOperation("ForkJoinProcess.ExecuteContext", operation == nil)
+	return executeContext(ctx, p, iterations, operation)
+}
+
+// ExecuteTask executes task and every task spawned by it, returning after the
+// whole tree has finished.
+func (p *ForkJoinProcess) ExecuteTask(task Task) {
+	checkOperation("ForkJoinProcess.ExecuteTask", task == nil)
+	p.executeTask(task, p.numRoutines)
+}
+
+// Spawn submits a child task to the process. It should be called from within a
+// task while the process is executing.
+func (p *ForkJoinProcess) Spawn(task Task) {
+	checkOperation("ForkJoinProcess.Spawn", task == nil)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	p.pending++
+	p.queue = append(p.queue, task)
+	p.cond.Signal()
+}
+
+// Stop stops the fork-join process after all of the current tasks have
+// finished executing. Tasks that have not begun are discarded.
+func (p *ForkJoinProcess) Stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stopped = true
+	p.queue = nil
+	p.cond.Broadcast()
+}
+
+// NumRoutines returns the number of routines that the fork-join process was
+// initialized with.
+func (p *ForkJoinProcess) NumRoutines() int {
+	return p.numRoutines
+}
+
+// Stats returns a snapshot of the current or last execution. Iterations are
+// tasks, and the total counts the tasks that have been spawned so far.
+func (p *ForkJoinProcess) Stats() ProcessStats {
+	p.mutex.Lock()
+	completed, pending := p.completed, p.pending
+	p.mutex.Unlock()
+
+	return ProcessStats{
+		Routines:   p.NumRoutines(),
+		Completed:  int64(completed),
+		Iterations: int64(completed + pending),
+		Elapsed:    p.timer.elapsed(),
+	}
+}
+
+// Throughput returns the meter measuring the number of tasks the process
+// completes per second. Each iteration of Execute is a task.
+func (p *ForkJoinProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private methods
+
+// wasStopped returns whether or not the last execution was stopped.
+func (p *ForkJoinProcess) wasStopped() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.stopped
+}
+
+// executeTask executes task and every task spawned by it on the specified
+// number of routines.
+func (p *ForkJoinProcess) executeTask(task Task, numRoutines int) {
+	p.mutex.Lock()
+	p.stopped = false
+	p.queue = p.queue[:0]
+	p.pending = 0
+	p.completed = 0
+	p.mutex.Unlock()
+
+	p.timer.start()
+	defer p.timer.stop()
+
+	p.Spawn(task)
+
+	p.group.Add(numRoutines)
+	for n := 0; n < numRoutines; n++ {
+		go p.runRoutine()
+	}
+
+	p.group.Wait()
+}
+
+// runRoutine executes queued tasks until the tree finishes or the process is
+// stopped.
+func (p *ForkJoinProcess) runRoutine() {
+	defer p.group.Done()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for {
+		for len(p.queue) == 0 && p.pending > 0 && !p.stopped {
+			p.cond.Wait()
+		}
+
+		if p.stopped || p.pending == 0 {
+			return
+		}
+
+		task := p.queue[len(p.queue)-1]
+		p.queue = p.queue[:len(p.queue)-1]
+
+		p.mutex.Unlock()
+		task()
+		p.throughput.Add(1)
+		p.mutex.Lock()
+
+		p.pending--
+		p.completed++
+		if p.pending == 0 {
+			p.cond.Broadcast()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestForkJoinProcessCompleteness(t *testing.T) {
+	v := make([]float64, 1000000)
+	p := NewForkJoinProcess(4)
+	p.Execute(len(v), func(i int) {
+		v[i] = float64(i + 1)
+	})
+
+	for i, value := range v {
+		if float64(i+1) != value {
+			t.Errorf("Value, %f, should be equal to %f.", value, float64(i+1))
+			break
+		}
+	}
+}
+
+func TestForkJoinProcessRecursiveSum(t *testing.T) {
+	v := make([]int64, 100000)
+	for i := range v {
+		v[i] = int64(i)
+	}
+
+	var sum int64
+	p := NewForkJoinProcess(4)
+
+	var add func(s []int64) Task
+	add = func(s []int64) Task {
+		return func() {
+			if len(s) <= 100 {
+				var partial int64
+				for _, value := range s {
+					partial += value
+				}
+				atomic.AddInt64(&sum, partial)
+				return
+			}
+
+			p.Spawn(add(s[:len(s)/2]))
+			p.Spawn(add(s[len(s)/2:]))
+		}
+	}
+
+	p.ExecuteTask(add(v))
+
+	expected := int64(len(v)) * int64(len(v)-1) / 2
+	if sum != expected {
+		t.Errorf("Sum, %d, should be equal to %d.", sum, expected)
+	}
+}
+
+func TestStopForkJoinProcess(t *testing.T) {
+	var count int64
+	p := NewForkJoinProcess(2)
+
+	var spawn func() Task
+	spawn = func() Task {
+		return func() {
+			if atomic.AddInt64(&count, 1) == 1000 {
+				p.Stop()
+			}
+			p.Spawn(spawn())
+			p.Spawn(spawn())
+		}
+	}
+
+	p.ExecuteTask(spawn())
+
+	if atomic.LoadInt64(&count) > 1002 {
+		t.Errorf("Task count, %d, should not continue after stopping.", count)
+	}
+}
@@ -0,0 +1,261 @@
+package parallel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ResultOperation types represent a single operation that produces a result.
+// Responders should perform the i-th operation and return its result.
+type ResultOperation func(i int) []byte
+
+// ResultConsumer types receive the result of the i-th operation.
+type ResultConsumer func(i int, result []byte)
+
+// ForkProcess types shard iterations across multiple OS processes running the
+// current binary, for operations that can't run on multiple goroutines, such
+// as calls into C libraries that aren't thread safe.
+//
+// Calling Execute in the parent process starts worker processes by executing
+// the current binary again with the same arguments and an environment variable
+// describing the range of iterations the worker should execute. When a worker
+// reaches the call to Execute of the fork process with the same name, it
+// executes its range sequentially, writes the results to the parent over a
+// pipe and exits. Code that runs before that call is therefore executed by
+// every worker, and should be guarded with IsForkWorker where that matters.
+//
+// Workers receive the pipe to the parent as an inherited file descriptor, which
+// Windows doesn't support. On Windows, Execute returns an error without
+// executing any iterations.
+type ForkProcess struct {
+	// The arguments passed to worker processes. The current process' arguments
+	// are used if nil.
+	Args []string
+
+	// The name identifying the process to its workers.
+	name string
+
+	// The number of worker processes.
+	numProcesses int
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// The environment variable describing a worker's assignment as
+// "name:start:end".
+const forkWorkerEnvironmentKey = "PARALLEL_FORK_WORKER"
+
+// The largest result a worker may send, so that a corrupt length can't make
+// the parent allocate an unbounded buffer.
+const maxForkResultLength = 1 << 30
+
+// The largest number of missing iterations listed in the error of a worker
+// whose results are incomplete.
+const maxReportedMissingResults = 10
+
+// MARK: Initializers
+
+// NewForkProcess creates and returns a new fork process with the given name
+// that shards iterations across numProcesses worker processes. The name must
+// be unique among the fork processes in the program and must not contain a
+// colon.
+func NewForkProcess(name string, numProcesses int) *ForkProcess {
+	checkRoutines("NewForkProcess", "numProcesses", numProcesses)
+	if strings.Contains(name, ":") {
+		misuse("NewForkProcess called with the name %q; names must not contain a colon", name)
+	}
+
+	return &ForkProcess{
+		name:         name,
+		numProcesses: numProcesses,
+		throughput:   newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+}
+
+// MARK: Public functions
+
+// IsForkWorker returns whether or not the current OS process is a worker
+// started by a fork process.
+func IsForkWorker() bool {
+	return os.Getenv(forkWorkerEnvironmentKey) != ""
+}
+
+// MARK: Public methods
+
+// Execute executes the operation for the specified number of iterations across
+// the process' workers, passing each result to consume. Results are consumed
+// one at a time, but in no particular order.
+//
+// In a worker process started for this fork process, Execute executes the
+// worker's range of iterations and exits the worker. In a worker started for a
+// different fork process, Execute executes every iteration in the current
+// process without starting workers.
+func (p *ForkProcess) Execute(iterations int, operation ResultOperation, consume ResultConsumer) error {
+	checkIterations("ForkProcess.Execute", iterations)
+	checkOperation("ForkProcess.Execute", operation == nil || consume == nil)
+
+	if assignment := os.Getenv(forkWorkerEnvironmentKey); assignment != "" {
+		name, start, end, err := parseForkAssignment(assignment)
+		if err != nil {
+			return err
+		}
+
+		if name == p.name {
+			err = runForkWorker(start, end, operation)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+
+		for i := 0; i < iterations; i++ {
+			consume(i, operation(i))
+			p.throughput.Add(1)
+		}
+		return nil
+	}
+
+	if iterations == 0 {
+		return nil
+	}
+
+	return p.runWorkers(iterations, consume)
+}
+
+// NumProcesses returns the number of worker processes the fork process uses.
+func (p *ForkProcess) NumProcesses() int {
+	return p.numProcesses
+}
+
+// Throughput returns the meter measuring the number of results the process
+// consumes per second.
+func (p *ForkProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private functions
+
+// parseForkAssignment parses a worker assignment of the form
+// "name:start:end".
+func parseForkAssignment(assignment string) (string, int, int, error) {
+	parts := strings.Split(assignment, ":")
+	if len(parts) != 3 {
+		return "", 0, 0, fmt.Errorf("parallel: invalid fork worker assignment %q", assignment)
+	}
+
+	start, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("parallel: invalid fork worker assignment %q", assignment)
+	}
+
+	end, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("parallel: invalid fork worker assignment %q", assignment)
+	}
+
+	return parts[0], start, end, nil
+}
+
+// runForkWorker executes the iterations [start, end) and writes their results
+// to the pipe inherited from the parent process.
+func runForkWorker(start int, end int, operation ResultOperation) error {
+	pipe := os.NewFile(3, "parallel-results")
+	if pipe == nil {
+		return fmt.Errorf("parallel: fork worker has no result pipe")
+	}
+	defer pipe.Close()
+
+	w := bufio.NewWriter(pipe)
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	for i := start; i < end; i++ {
+		result := operation(i)
+
+		n := binary.PutUvarint(header, uint64(i))
+		n += binary.PutUvarint(header[n:], uint64(len(result)))
+		if _, err := w.Write(header[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// readForkResults reads results written by runForkWorker and passes them to
+// consume. An error is returned if a result for [start, end) is repeated, and
+// the missing iterations are reported if the results are incomplete.
+func readForkResults(r io.Reader, start int, end int, consume ResultConsumer) error {
+	reader := bufio.NewReader(r)
+	received := make([]uint64, (end-start+63)/64)
+	count := 0
+
+	for {
+		index, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return err
+		} else if length > maxForkResultLength {
+			return fmt.Errorf("result for iteration %d is %d bytes long, more than the maximum of %d", index, length, maxForkResultLength)
+		}
+
+		result := make([]byte, length)
+		if _, err = io.ReadFull(reader, result); err != nil {
+			return err
+		}
+
+		if int(index) < start || int(index) >= end {
+			return fmt.Errorf("result for iteration %d is outside of the worker's range", index)
+		}
+
+		offset := int(index) - start
+		word, bit := offset/64, uint64(1)<<uint(offset%64)
+		if received[word]&bit != 0 {
+			return fmt.Errorf("result for iteration %d was received more than once", index)
+		}
+		received[word] |= bit
+
+		consume(int(index), result)
+		count++
+	}
+
+	if count != end-start {
+		return fmt.Errorf("received %d of %d results; missing iterations %s", count, end-start, missingForkResults(received, start, end))
+	}
+
+	return nil
+}
+
+// missingForkResults returns a list of the first iterations in [start, end)
+// whose bits aren't set in received.
+func missingForkResults(received []uint64, start int, end int) string {
+	var missing []string
+	for i := start; i < end; i++ {
+		offset := i - start
+		if received[offset/64]&(uint64(1)<<uint(offset%64)) != 0 {
+			continue
+		}
+
+		if len(missing) == maxReportedMissingResults {
+			missing = append(missing, "...")
+			break
+		}
+		missing = append(missing, strconv.Itoa(i))
+	}
+
+	return strings.Join(missing, ", ")
+}
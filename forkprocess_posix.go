@@ -0,0 +1,94 @@
+//go:build !windows
+// +build !windows
+
+package parallel
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// MARK: Private methods
+
+// runWorkers starts the process' workers, consumes their results and waits for
+// them to exit.
+func (p *ForkProcess) runWorkers(iterations int, consume ResultConsumer) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := p.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	numProcesses := minInt(p.numProcesses, iterations)
+	errs := make([]error, numProcesses)
+
+	var consumeMutex sync.Mutex
+	var group sync.WaitGroup
+	group.Add(numProcesses)
+
+	for n := 0; n < numProcesses; n++ {
+		start := iterations * n / numProcesses
+		end := iterations * (n + 1) / numProcesses
+
+		go func(n int) {
+			defer group.Done()
+			errs[n] = p.runWorker(executable, args, start, end, func(i int, result []byte) {
+				consumeMutex.Lock()
+				defer consumeMutex.Unlock()
+				consume(i, result)
+				p.throughput.Add(1)
+			})
+		}(n)
+	}
+
+	group.Wait()
+
+	for n, err := range errs {
+		if err != nil {
+			return fmt.Errorf("parallel: fork worker %d failed: %s", n, err)
+		}
+	}
+
+	return nil
+}
+
+// runWorker runs a single worker for the iterations [start, end) and consumes
+// its results.
+func (p *ForkProcess) runWorker(executable string, args []string, start int, end int, consume ResultConsumer) error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s:%d:%d", forkWorkerEnvironmentKey, p.name, start, end))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{writer}
+
+	err = cmd.Start()
+	writer.Close()
+	if err != nil {
+		return err
+	}
+
+	readErr := readForkResults(reader, start, end, consume)
+	if readErr != nil {
+		// The worker may still be writing. Closing the pipe makes its writes
+		// fail instead of blocking on a full pipe while the parent waits.
+		reader.Close()
+	}
+
+	if err = cmd.Wait(); err != nil {
+		return err
+	}
+
+	return readErr
+}
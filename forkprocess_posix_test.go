@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package parallel
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestForkProcess(t *testing.T) {
+	results := make([]int, 100)
+	p := NewForkProcess("test", 3)
+	p.Args = []string{"-test.run=^TestForkProcess$"}
+
+	err := p.Execute(len(results), func(i int) []byte {
+		return []byte(strconv.Itoa(i * i))
+	}, func(i int, result []byte) {
+		results[i], _ = strconv.Atoi(string(result))
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i, value := range results {
+		if value != i*i {
+			t.Errorf("Result, %d, should be equal to %d.", value, i*i)
+			break
+		}
+	}
+}
+
+func TestForkProcessWorkerFailure(t *testing.T) {
+	p := NewForkProcess("failure", 2)
+	p.Args = []string{"-test.run=^TestForkProcessWorkerFailure$"}
+
+	err := p.Execute(10, func(i int) []byte {
+		if IsForkWorker() && i == 7 {
+			os.Exit(2)
+		}
+		return nil
+	}, func(i int, result []byte) {})
+
+	if err == nil {
+		t.Errorf("A failing worker should return an error.")
+	}
+}
@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestParseForkAssignment(t *testing.T) {
+	name, start, end, err := parseForkAssignment("test:10:20")
+	if err != nil || name != "test" || start != 10 || end != 20 {
+		t.Errorf("Assignment should parse as test, 10, 20, not %s, %d, %d (%v).", name, start, end, err)
+	}
+
+	if _, _, _, err = parseForkAssignment("test:10"); err == nil {
+		t.Errorf("An incomplete assignment should return an error.")
+	}
+}
+
+func TestReadForkResultsCorruptLength(t *testing.T) {
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, 0)
+	n += binary.PutUvarint(header[n:], 1<<62)
+
+	err := readForkResults(bytes.NewReader(header[:n]), 0, 1, func(i int, result []byte) {
+		t.Error("A result with a corrupt length shouldn't be consumed.")
+	})
+	if err == nil {
+		t.Error("A result longer than the maximum should return an error.")
+	}
+}
+
+func TestReadForkResultsOutOfRange(t *testing.T) {
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, 5)
+	n += binary.PutUvarint(header[n:], 0)
+
+	if err := readForkResults(bytes.NewReader(header[:n]), 0, 2, func(i int, result []byte) {}); err == nil {
+		t.Error("A result outside of the worker's range should return an error.")
+	}
+}
+
+func TestReadForkResultsDuplicate(t *testing.T) {
+	var buffer bytes.Buffer
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	for _, index := range []uint64{0, 1, 1} {
+		n := binary.PutUvarint(header, index)
+		n += binary.PutUvarint(header[n:], 0)
+		buffer.Write(header[:n])
+	}
+
+	consumed := make(map[int]int)
+	err := readForkResults(&buffer, 0, 3, func(i int, result []byte) {
+		consumed[i]++
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("A repeated result should return an error, not %v.", err)
+	}
+	if consumed[1] != 1 {
+		t.Errorf("A repeated result was consumed %d times.", consumed[1])
+	}
+}
+
+func TestReadForkResultsMissing(t *testing.T) {
+	var buffer bytes.Buffer
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	for _, index := range []uint64{10, 12} {
+		n := binary.PutUvarint(header, index)
+		n += binary.PutUvarint(header[n:], 0)
+		buffer.Write(header[:n])
+	}
+
+	err := readForkResults(&buffer, 10, 14, func(i int, result []byte) {})
+	if err == nil || !strings.Contains(err.Error(), "missing iterations 11, 13") {
+		t.Errorf("Incomplete results should report the missing iterations, not %v.", err)
+	}
+}
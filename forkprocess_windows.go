@@ -0,0 +1,11 @@
+package parallel
+
+import "errors"
+
+// MARK: Private methods
+
+// runWorkers returns an error because workers can't inherit the pipe to the
+// parent process on Windows.
+func (p *ForkProcess) runWorkers(iterations int, consume ResultConsumer) error {
+	return errors.New("parallel: ForkProcess is not supported on Windows")
+}
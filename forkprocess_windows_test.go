@@ -0,0 +1,16 @@
+package parallel
+
+import "testing"
+
+// MARK: Tests
+
+func TestForkProcessUnsupported(t *testing.T) {
+	p := NewForkProcess("test", 2)
+	err := p.Execute(10, func(i int) []byte {
+		return nil
+	}, func(i int, result []byte) {})
+
+	if err == nil {
+		t.Errorf("Executing a fork process on Windows should return an error.")
+	}
+}
@@ -0,0 +1,82 @@
+package parallel
+
+import "time"
+
+// gcReporter types report the time the garbage collector paused the program
+// and the number of CPUs it used between successive calls to their usage
+// method.
+type gcReporter struct {
+	lastTime  time.Time
+	lastPause uint64
+	lastCPU   float64
+}
+
+// gcSample types contain the garbage collector's totals at a point in time.
+type gcSample struct {
+	time time.Time
+
+	// The total time the program has been paused for, in nanoseconds.
+	pause uint64
+
+	// The total CPU seconds spent collecting garbage, if the runtime reports
+	// them, and the fraction of the program's available CPU time the
+	// collector has used since the program started.
+	cpu         float64
+	cpuReported bool
+	cpuFraction float64
+}
+
+// MARK: Initializers
+
+// newGCReporter creates and returns a new garbage collection reporter.
+func newGCReporter() *gcReporter {
+	r := &gcReporter{}
+	r.reset()
+	return r
+}
+
+// MARK: Private methods
+
+// usage returns the time the garbage collector paused the program and the
+// number of CPUs it used since the last call to usage or reset.
+func (r *gcReporter) usage() (time.Duration, float64) {
+	s, _ := readGCSample(true)
+	pause := time.Duration(s.pause - r.lastPause)
+	cpus := s.cpusSince(r.lastTime, r.lastCPU)
+
+	r.lastTime = s.time
+	r.lastPause = s.pause
+	r.lastCPU = s.cpu
+	return pause, cpus
+}
+
+// reset restarts the reporter's measurements.
+func (r *gcReporter) reset() {
+	s, _ := readGCSample(true)
+	r.lastTime = s.time
+	r.lastPause = s.pause
+	r.lastCPU = s.cpu
+}
+
+// cpusSince returns the number of CPUs the garbage collector used since a
+// sample taken at start with cpu total CPU seconds. If the runtime doesn't
+// report the collector's CPU seconds, the average since the program started is
+// returned instead.
+func (s gcSample) cpusSince(start time.Time, cpu float64) float64 {
+	if !s.cpuReported {
+		return s.cpuFraction * float64(globalMaxProcs.procs())
+	}
+
+	elapsed := s.time.Sub(start).Seconds()
+	if elapsed <= 0.0 {
+		return 0.0
+	}
+	return (s.cpu - cpu) / elapsed
+}
+
+// cpuFractionSince returns the fraction of the program's available CPU time
+// the garbage collector used since the sample start. The available CPU time is
+// that of GOMAXPROCS before any process adjusting it lowered it.
+func (s gcSample) cpuFractionSince(start gcSample) float64 {
+	return s.cpusSince(start.time, start.cpu) / float64(globalMaxProcs.procs())
+}
@@ -0,0 +1,58 @@
+//go:build go1.20
+// +build go1.20
+
+package parallel
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// The runtime metrics of the garbage collector's pauses and CPU time.
+const (
+	metricsGCPauses = "/gc/pauses:seconds"
+	metricsGCCPU    = "/cpu/classes/gc/total:cpu-seconds"
+)
+
+// MARK: Private functions
+
+// readGCSample returns the garbage collector's current totals. Reading the
+// runtime's metrics doesn't stop the world, so the collector is always
+// measured and memStats is ignored.
+func readGCSample(memStats bool) (gcSample, bool) {
+	samples := []metrics.Sample{{Name: metricsGCPauses}, {Name: metricsGCCPU}}
+	metrics.Read(samples)
+
+	s := gcSample{time: time.Now()}
+	if samples[0].Value.Kind() == metrics.KindFloat64Histogram {
+		s.pause = uint64(histogramTotal(samples[0].Value.Float64Histogram()) * 1e9)
+	}
+	if samples[1].Value.Kind() == metrics.KindFloat64 {
+		s.cpu = samples[1].Value.Float64()
+		s.cpuReported = true
+	}
+	return s, true
+}
+
+// histogramTotal returns the approximate sum of the values counted by h. Each
+// value is taken to be the midpoint of its bucket, or the bucket's finite
+// boundary if the other is infinite.
+func histogramTotal(h *metrics.Float64Histogram) float64 {
+	total := 0.0
+	for i, count := range h.Counts {
+		if count == 0 {
+			continue
+		}
+
+		lower, upper := h.Buckets[i], h.Buckets[i+1]
+		value := (lower + upper) / 2.0
+		if math.IsInf(lower, -1) {
+			value = upper
+		} else if math.IsInf(upper, 1) {
+			value = lower
+		}
+		total += float64(count) * value
+	}
+	return total
+}
@@ -0,0 +1,30 @@
+//go:build !go1.20
+// +build !go1.20
+
+package parallel
+
+import (
+	"runtime"
+	"time"
+)
+
+// MARK: Private functions
+
+// readGCSample returns the garbage collector's current totals if memStats is
+// true. Before Go 1.20 the runtime only reports them through ReadMemStats,
+// which stops the world, so they aren't measured otherwise. The CPU seconds
+// spent collecting garbage aren't reported, so the sample contains the
+// collector's CPU fraction since the program started instead.
+func readGCSample(memStats bool) (gcSample, bool) {
+	if !memStats {
+		return gcSample{}, false
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return gcSample{
+		time:        time.Now(),
+		pause:       stats.PauseTotalNs,
+		cpuFraction: stats.GCCPUFraction,
+	}, true
+}
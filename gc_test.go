@@ -0,0 +1,60 @@
+package parallel
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestGCReporterUsage(t *testing.T) {
+	r := newGCReporter()
+	for i := 0; i < 3; i++ {
+		gcSink = make([]byte, 1<<20)
+		runtime.GC()
+	}
+
+	pause, cpus := r.usage()
+	if pause <= 0 {
+		t.Errorf("Pause, %s, should be positive after collecting garbage.", pause)
+	}
+
+	if cpus < 0.0 || cpus > float64(runtime.GOMAXPROCS(0)) {
+		t.Errorf("GC CPUs, %f, should be between 0 and GOMAXPROCS.", cpus)
+	}
+}
+
+func TestVariableProcessSubtractGCUsage(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetSubtractGCUsage(true)
+
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		buffer := make([]byte, 64<<10)
+		buffer[i%len(buffer)] = 1
+		v[i] += int(buffer[i%len(buffer)])
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	s := p.Summary()
+	if s.Stats.GCCPUFraction < 0.0 || s.Stats.GCCPUFraction > 1.0 {
+		t.Errorf("GC CPU fraction, %f, should be between 0 and 1.", s.Stats.GCCPUFraction)
+	}
+
+	if s.Stats.GCPause < 0 {
+		t.Errorf("GC pause, %s, should not be negative.", s.Stats.GCPause)
+	}
+}
+
+// MARK: Helpers
+
+// gcSink keeps allocations in the tests from being optimized away.
+var gcSink []byte
@@ -0,0 +1,124 @@
+package parallel
+
+// GridOperation types represent a single operation on a two dimensional grid.
+// Responders should perform the operation for the cell at (x, y).
+type GridOperation func(x int, y int)
+
+// Grid3DOperation types represent a single operation on a three dimensional
+// grid. Responders should perform the operation for the cell at (x, y, z).
+type Grid3DOperation func(x int, y int, z int)
+
+// The default width and height of the tiles used by ExecuteGrid.
+const defaultGridTileSize = 32
+
+// The default width, height and depth of the tiles used by ExecuteGrid3D.
+const defaultGrid3DTileSize = 8
+
+// MARK: Public functions
+
+// ExecuteGrid executes the operation once for every cell of a width by height
+// grid on process.
+//
+// The grid is divided into square tiles that are executed in parallel. Cells
+// within a tile are visited in row-major order so that neighbouring cells are
+// processed by the same routine.
+func ExecuteGrid(process Process, width int, height int, operation GridOperation) {
+	ExecuteGridTiled(process, width, height, defaultGridTileSize, defaultGridTileSize, operation)
+}
+
+// ExecuteGridTiled executes the operation once for every cell of a width by
+// height grid on process, dividing the grid into tiles of tileWidth by
+// tileHeight cells.
+func ExecuteGridTiled(process Process, width int, height int, tileWidth int, tileHeight int, operation GridOperation) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	tileWidth = clampTileSize(tileWidth, width)
+	tileHeight = clampTileSize(tileHeight, height)
+	tilesX := (width + tileWidth - 1) / tileWidth
+	tilesY := (height + tileHeight - 1) / tileHeight
+
+	process.Execute(tilesX*tilesY, func(i int) {
+		startX := (i % tilesX) * tileWidth
+		startY := (i / tilesX) * tileHeight
+		endX := minInt(startX+tileWidth, width)
+		endY := minInt(startY+tileHeight, height)
+
+		for y := startY; y < endY; y++ {
+			for x := startX; x < endX; x++ {
+				operation(x, y)
+			}
+		}
+	})
+}
+
+// ExecuteGrid3D executes the operation once for every cell of a width by
+// height by depth grid on process.
+//
+// The grid is divided into cubic tiles that are executed in parallel. Cells
+// within a tile are visited with x varying fastest and z slowest.
+func ExecuteGrid3D(process Process, width int, height int, depth int, operation Grid3DOperation) {
+	ExecuteGrid3DTiled(process, width, height, depth, defaultGrid3DTileSize, defaultGrid3DTileSize, defaultGrid3DTileSize, operation)
+}
+
+// ExecuteGrid3DTiled executes the operation once for every cell of a width by
+// height by depth grid on process, dividing the grid into tiles of tileWidth
+// by tileHeight by tileDepth cells.
+func ExecuteGrid3DTiled(process Process, width int, height int, depth int, tileWidth int, tileHeight int, tileDepth int, operation Grid3DOperation) {
+	if width <= 0 || height <= 0 || depth <= 0 {
+		return
+	}
+
+	tileWidth = clampTileSize(tileWidth, width)
+	tileHeight = clampTileSize(tileHeight, height)
+	tileDepth = clampTileSize(tileDepth, depth)
+	tilesX := (width + tileWidth - 1) / tileWidth
+	tilesY := (height + tileHeight - 1) / tileHeight
+	tilesZ := (depth + tileDepth - 1) / tileDepth
+
+	process.Execute(tilesX*tilesY*tilesZ, func(i int) {
+		startX := (i % tilesX) * tileWidth
+		startY := ((i / tilesX) % tilesY) * tileHeight
+		startZ := (i / (tilesX * tilesY)) * tileDepth
+		endX := minInt(startX+tileWidth, width)
+		endY := minInt(startY+tileHeight, height)
+		endZ := minInt(startZ+tileDepth, depth)
+
+		for z := startZ; z < endZ; z++ {
+			for y := startY; y < endY; y++ {
+				for x := startX; x < endX; x++ {
+					operation(x, y, z)
+				}
+			}
+		}
+	})
+}
+
+// MARK: Private functions
+
+// clampTileSize returns size limited to the range [1, length].
+func clampTileSize(size int, length int) int {
+	if size < 1 {
+		return 1
+	} else if size > length {
+		return length
+	}
+	return size
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
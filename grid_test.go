@@ -0,0 +1,65 @@
+package parallel
+
+import "testing"
+
+// MARK: Tests
+
+func TestExecuteGridCompleteness(t *testing.T) {
+	width, height := 101, 67
+	v := make([]int, width*height)
+	ExecuteGrid(NewFixedProcess(4), width, height, func(x int, y int) {
+		v[y*width+x]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Cell (%d, %d) was visited %d times.", i%width, i/width, value)
+			break
+		}
+	}
+}
+
+func TestExecuteGridTiledCompleteness(t *testing.T) {
+	width, height := 10, 7
+	v := make([]int, width*height)
+	ExecuteGridTiled(NewFixedProcess(2), width, height, 3, 4, func(x int, y int) {
+		v[y*width+x]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Cell (%d, %d) was visited %d times.", i%width, i/width, value)
+			break
+		}
+	}
+}
+
+func TestExecuteGrid3DCompleteness(t *testing.T) {
+	width, height, depth := 17, 9, 11
+	v := make([]int, width*height*depth)
+	ExecuteGrid3D(NewFixedProcess(4), width, height, depth, func(x int, y int, z int) {
+		v[(z*height+y)*width+x]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Cell %d was visited %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestExecuteGrid3DTiledCompleteness(t *testing.T) {
+	width, height, depth := 10, 7, 5
+	v := make([]int, width*height*depth)
+	ExecuteGrid3DTiled(NewFixedProcess(2), width, height, depth, 3, 4, 2, func(x int, y int, z int) {
+		v[(z*height+y)*width+x]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Cell %d was visited %d times.", i, value)
+			break
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package parallel
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// The layout of a latency histogram's buckets. Latencies below
+// 2*histogramSubBuckets nanoseconds have a bucket each. Above that, every
+// power of two is divided into histogramSubBuckets buckets, so a latency is
+// reported to within about 3% of its value.
+const (
+	histogramSubBucketBits = 5
+	histogramSubBuckets    = 1 << histogramSubBucketBits
+	histogramBuckets       = 2*histogramSubBuckets + (63-histogramSubBucketBits-1)*histogramSubBuckets
+)
+
+// LatencyHistogram types count the latencies of a process' operations in
+// logarithmic buckets, in the manner of an HDR histogram. Recording is lock
+// free and takes a constant amount of memory no matter how many latencies are
+// recorded.
+type LatencyHistogram struct {
+	buckets [histogramBuckets]uint64
+	count   uint64
+	sum     uint64
+	min     int64
+	max     int64
+}
+
+// LatencyPercentiles types hold the median, 95th and 99th percentiles of a
+// histogram's latencies.
+type LatencyPercentiles struct {
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+}
+
+// MARK: Initializers
+
+// newLatencyHistogram creates and returns a new, empty latency histogram.
+func newLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{min: math.MaxInt64}
+}
+
+// MARK: Public methods
+
+// Count returns the number of recorded latencies.
+func (h *LatencyHistogram) Count() int {
+	return int(atomic.LoadUint64(&h.count))
+}
+
+// Min returns the smallest recorded latency, or zero if none were recorded.
+func (h *LatencyHistogram) Min() time.Duration {
+	if h.Count() == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&h.min))
+}
+
+// Max returns the largest recorded latency.
+func (h *LatencyHistogram) Max() time.Duration {
+	return time.Duration(atomic.LoadInt64(&h.max))
+}
+
+// Mean returns the mean of the recorded latencies, or zero if none were
+// recorded.
+func (h *LatencyHistogram) Mean() time.Duration {
+	count := atomic.LoadUint64(&h.count)
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadUint64(&h.sum) / count)
+}
+
+// Percentile returns the q-th quantile of the recorded latencies, where q is
+// between 0 and 1. The latency is the largest one that falls in the same
+// bucket as the quantile, capped at the largest recorded latency. It returns
+// zero if no latencies were recorded.
+func (h *LatencyHistogram) Percentile(q float64) time.Duration {
+	if !(q >= 0.0 && q <= 1.0) {
+		misuse("LatencyHistogram.Percentile called with %g; the quantile must be between 0 and 1", q)
+	}
+
+	count := atomic.LoadUint64(&h.count)
+	if count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := uint64(0)
+	for i := range h.buckets {
+		seen += atomic.LoadUint64(&h.buckets[i])
+		if seen >= rank {
+			return minDuration(time.Duration(histogramBucketMax(i)), h.Max())
+		}
+	}
+	return h.Max()
+}
+
+// Percentiles returns the median, 95th and 99th percentiles of the recorded
+// latencies.
+func (h *LatencyHistogram) Percentiles() LatencyPercentiles {
+	return LatencyPercentiles{
+		P50: h.Percentile(0.5),
+		P95: h.Percentile(0.95),
+		P99: h.Percentile(0.99),
+	}
+}
+
+// MARK: Private methods
+
+// record records the latency of an operation. Negative latencies are recorded
+// as zero.
+func (h *LatencyHistogram) record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	v := int64(latency)
+
+	atomic.AddUint64(&h.buckets[histogramBucket(uint64(v))], 1)
+	atomic.AddUint64(&h.sum, uint64(v))
+	for min := atomic.LoadInt64(&h.min); v < min; min = atomic.LoadInt64(&h.min) {
+		if atomic.CompareAndSwapInt64(&h.min, min, v) {
+			break
+		}
+	}
+	for max := atomic.LoadInt64(&h.max); v > max; max = atomic.LoadInt64(&h.max) {
+		if atomic.CompareAndSwapInt64(&h.max, max, v) {
+			break
+		}
+	}
+	atomic.AddUint64(&h.count, 1)
+}
+
+// reset discards the recorded latencies. It must not be called while
+// latencies are being recorded.
+func (h *LatencyHistogram) reset() {
+	for i := range h.buckets {
+		atomic.StoreUint64(&h.buckets[i], 0)
+	}
+	atomic.StoreUint64(&h.count, 0)
+	atomic.StoreUint64(&h.sum, 0)
+	atomic.StoreInt64(&h.min, math.MaxInt64)
+	atomic.StoreInt64(&h.max, 0)
+}
+
+// snapshot returns a copy of the histogram.
+func (h *LatencyHistogram) snapshot() *LatencyHistogram {
+	s := &LatencyHistogram{
+		count: atomic.LoadUint64(&h.count),
+		sum:   atomic.LoadUint64(&h.sum),
+		min:   atomic.LoadInt64(&h.min),
+		max:   atomic.LoadInt64(&h.max),
+	}
+	for i := range h.buckets {
+		s.buckets[i] = atomic.LoadUint64(&h.buckets[i])
+	}
+	return s
+}
+
+// MARK: Private functions
+
+// histogramBucket returns the index of the bucket counting latencies of v
+// nanoseconds.
+func histogramBucket(v uint64) int {
+	if v < 2*histogramSubBuckets {
+		return int(v)
+	}
+	shift := uint(bits.Len64(v)) - histogramSubBucketBits - 1
+	return 2*histogramSubBuckets + int(shift-1)*histogramSubBuckets + int(v>>shift) - histogramSubBuckets
+}
+
+// histogramBucketMax returns the largest latency, in nanoseconds, counted by
+// the bucket at index i.
+func histogramBucketMax(i int) uint64 {
+	if i < 2*histogramSubBuckets {
+		return uint64(i)
+	}
+	i -= 2 * histogramSubBuckets
+	shift := uint(i/histogramSubBuckets) + 1
+	m := uint64(i%histogramSubBuckets + histogramSubBuckets)
+	return (m+1)<<shift - 1
+}
+
+// minDuration returns the smaller of a and b.
+func minDuration(a time.Duration, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestHistogramBuckets(t *testing.T) {
+	values := []uint64{0, 1, 63, 64, 65, 127, 128, 1000, 123456789, math.MaxInt64}
+	for _, v := range values {
+		i := histogramBucket(v)
+		if i < 0 || i >= histogramBuckets {
+			t.Fatalf("Bucket %d of %d should be between 0 and %d.", i, v, histogramBuckets)
+		}
+
+		max := histogramBucketMax(i)
+		if max < v {
+			t.Errorf("The maximum of the bucket of %d, %d, should be at least %d.", v, max, v)
+		}
+		if float64(max-v) > float64(v)/histogramSubBuckets {
+			t.Errorf("The maximum of the bucket of %d, %d, should be within %d.", v, max, v/histogramSubBuckets)
+		}
+		if i > 0 && histogramBucketMax(i-1) >= v {
+			t.Errorf("The bucket before the bucket of %d shouldn't contain it.", v)
+		}
+	}
+}
+
+func TestLatencyHistogramPercentiles(t *testing.T) {
+	h := newLatencyHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+
+	if h.Count() != 1000 {
+		t.Errorf("Count, %d, should be 1000.", h.Count())
+	}
+	if h.Min() != time.Microsecond {
+		t.Errorf("Min, %s, should be 1µs.", h.Min())
+	}
+	if h.Max() != time.Millisecond {
+		t.Errorf("Max, %s, should be 1ms.", h.Max())
+	}
+	if h.Mean() != 500500*time.Nanosecond {
+		t.Errorf("Mean, %s, should be 500.5µs.", h.Mean())
+	}
+
+	p := h.Percentiles()
+	for _, c := range []struct {
+		latency  time.Duration
+		expected time.Duration
+	}{
+		{p.P50, 500 * time.Microsecond},
+		{p.P95, 950 * time.Microsecond},
+		{p.P99, 990 * time.Microsecond},
+	} {
+		if c.latency < c.expected || float64(c.latency-c.expected) > float64(c.expected)/histogramSubBuckets {
+			t.Errorf("Percentile %s should be within 3%% above %s.", c.latency, c.expected)
+		}
+	}
+
+	if h.Percentile(1.0) != time.Millisecond {
+		t.Errorf("The 100th percentile, %s, should be the maximum.", h.Percentile(1.0))
+	}
+
+	s := h.snapshot()
+	h.reset()
+	if h.Count() != 0 || h.Percentile(0.5) != 0 || h.Min() != 0 || h.Max() != 0 {
+		t.Errorf("A reset histogram should be empty.")
+	}
+	if s.Count() != 1000 {
+		t.Errorf("The snapshot's count, %d, should be 1000.", s.Count())
+	}
+}
+
+func TestFixedProcessLatencies(t *testing.T) {
+	p := NewFixedProcess(2)
+	if p.Latencies() != nil {
+		t.Errorf("Latencies shouldn't be recorded by default.")
+	}
+
+	p.SetRecordLatencies(true)
+	p.Execute(20, func(i int) {
+		time.Sleep(time.Millisecond)
+	})
+
+	h := p.Latencies()
+	if h.Count() != 20 {
+		t.Errorf("Count, %d, should be 20.", h.Count())
+	}
+	if h.Min() < time.Millisecond {
+		t.Errorf("Min, %s, should be at least 1ms.", h.Min())
+	}
+
+	p.Execute(5, func(i int) {})
+	if p.Latencies().Count() != 5 {
+		t.Errorf("Count, %d, should be 5 after the next execution.", p.Latencies().Count())
+	}
+}
+
+func TestVariableProcessLatencies(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetRecordLatencies(true)
+	if !p.RecordLatencies() {
+		t.Errorf("The process should record latencies.")
+	}
+
+	p.Execute(20, func(i int) {
+		time.Sleep(time.Millisecond)
+	})
+
+	h := p.Latencies()
+	if h.Count() != 20 {
+		t.Errorf("Count, %d, should be 20.", h.Count())
+	}
+	if h.Percentile(0.5) < time.Millisecond {
+		t.Errorf("The median, %s, should be at least 1ms.", h.Percentile(0.5))
+	}
+
+	p.SetRecordLatencies(false)
+	if p.Latencies() != nil {
+		t.Errorf("Latencies shouldn't be returned once recording is disabled.")
+	}
+}
@@ -0,0 +1,68 @@
+package parallel
+
+import "sync/atomic"
+
+// IndexIterator types return the next index to execute and whether or not an
+// index was returned. Iterators are only called from a single goroutine.
+type IndexIterator func() (int, bool)
+
+// The number of indices ExecuteIterator reads from an iterator at a time.
+const iteratorBlockSize = 4096
+
+// stopReporter types report whether or not their last execution was stopped.
+type stopReporter interface {
+	wasStopped() bool
+}
+
+// MARK: Public functions
+
+// ExecuteIndices executes the operation once for each of the given indices on
+// process. Indices may be sparse, unordered or repeated.
+func ExecuteIndices(process Process, indices []int, operation Operation) {
+	process.Execute(len(indices), func(i int) {
+		operation(indices[i])
+	})
+}
+
+// ExecuteIterator executes the operation once for each index returned by next
+// on process.
+//
+// Indices are read from the iterator in blocks, so the iterator may produce
+// more indices than fit in memory. If the process is stopped, no further
+// indices are read.
+func ExecuteIterator(process Process, next IndexIterator, operation Operation) {
+	block := make([]int, 0, iteratorBlockSize)
+	for {
+		block = block[:0]
+		for len(block) < iteratorBlockSize {
+			index, ok := next()
+			if !ok {
+				break
+			}
+			block = append(block, index)
+		}
+
+		if len(block) == 0 {
+			return
+		}
+
+		var executed int64
+		ExecuteIndices(process, block, func(i int) {
+			operation(i)
+			atomic.AddInt64(&executed, 1)
+		})
+
+		if int(executed) < len(block) || processStopped(process) || len(block) < iteratorBlockSize {
+			return
+		}
+	}
+}
+
+// MARK: Private functions
+
+// processStopped returns whether or not process' last execution was stopped.
+// Processes that don't report it are never considered stopped.
+func processStopped(process Process) bool {
+	s, ok := process.(stopReporter)
+	return ok && s.wasStopped()
+}
@@ -0,0 +1,102 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestExecuteIndices(t *testing.T) {
+	v := make([]int, 100)
+	indices := []int{3, 97, 42, 0, 18}
+
+	ExecuteIndices(NewFixedProcess(2), indices, func(i int) {
+		v[i]++
+	})
+
+	count := 0
+	for _, value := range v {
+		count += value
+	}
+
+	if count != len(indices) {
+		t.Errorf("Executed operation count, %d, should be %d.", count, len(indices))
+	}
+
+	for _, i := range indices {
+		if v[i] != 1 {
+			t.Errorf("Index %d was executed %d times.", i, v[i])
+		}
+	}
+}
+
+func TestExecuteIterator(t *testing.T) {
+	v := make([]int, 3*iteratorBlockSize)
+	n := 0
+	next := func() (int, bool) {
+		for n < len(v) {
+			i := n
+			n++
+			if i%3 != 0 {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	ExecuteIterator(NewFixedProcess(4), next, func(i int) {
+		v[i]++
+	})
+
+	for i, value := range v {
+		if (i%3 == 0 && value != 0) || (i%3 != 0 && value != 1) {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestStopExecuteIterator(t *testing.T) {
+	var count int64
+	n := 0
+	next := func() (int, bool) {
+		n++
+		return n, true
+	}
+
+	p := NewFixedProcess(1)
+	ExecuteIterator(p, next, func(i int) {
+		if atomic.AddInt64(&count, 1) == 10 {
+			p.Stop()
+		}
+	})
+
+	if count != 10 {
+		t.Errorf("Executed operation count, %d, should be 10.", count)
+	}
+}
+
+func TestStopExecuteIteratorAtBlockEnd(t *testing.T) {
+	var count int64
+	n := 0
+	next := func() (int, bool) {
+		if n == 5000 {
+			return 0, false
+		}
+		n++
+		return n - 1, true
+	}
+
+	p := NewFixedProcess(1)
+	ExecuteIterator(p, next, func(i int) {
+		atomic.AddInt64(&count, 1)
+		if i == iteratorBlockSize-1 {
+			p.Stop()
+		}
+	})
+
+	if count != iteratorBlockSize {
+		t.Errorf("Executed operation count, %d, should be %d.", count, iteratorBlockSize)
+	}
+}
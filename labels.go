@@ -0,0 +1,27 @@
+package parallel
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+// The keys of the pprof labels given to the routines of named processes.
+const (
+	processLabel = "parallel.process"
+	routineLabel = "parallel.routine"
+)
+
+// MARK: Private functions
+
+// labelRoutine sets the pprof labels of the calling goroutine to those of ctx
+// along with the process' name and the routine's index. Goroutines of unnamed
+// processes keep the labels they inherited from the goroutine that started
+// them.
+func labelRoutine(ctx context.Context, name string, routine int) {
+	if name == "" {
+		return
+	}
+	labels := pprof.Labels(processLabel, name, routineLabel, strconv.Itoa(routine))
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, labels))
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessLabels(t *testing.T) {
+	p := NewFixedProcess(2)
+	p.SetName("fixed-labels")
+	if p.Name() != "fixed-labels" {
+		t.Errorf("Name, %q, should be fixed-labels.", p.Name())
+	}
+
+	profile := profileDuring(2, func(operation Operation) {
+		p.Execute(2, operation)
+	})
+
+	for _, label := range []string{`"parallel.process":"fixed-labels"`, `"parallel.routine":"0"`, `"parallel.routine":"1"`} {
+		if !strings.Contains(profile, label) {
+			t.Errorf("The goroutine profile should contain the label %s.", label)
+		}
+	}
+}
+
+func TestVariableProcessLabels(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Second, 1, 2, c, false)
+	p.SetName("variable-labels")
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("caller", "test"))
+	profile := profileDuring(1, func(operation Operation) {
+		p.ExecuteContext(ctx, 1, func(ctx context.Context, i int) {
+			operation(i)
+		})
+	})
+
+	for _, label := range []string{`"parallel.process":"variable-labels"`, `"parallel.routine":"0"`, `"caller":"test"`} {
+		if !strings.Contains(profile, label) {
+			t.Errorf("The goroutine profile should contain the label %s.", label)
+		}
+	}
+}
+
+func TestUnnamedProcessLabels(t *testing.T) {
+	p := NewFixedProcess(1)
+	profile := profileDuring(1, func(operation Operation) {
+		p.Execute(1, operation)
+	})
+
+	if strings.Contains(profile, processLabel) {
+		t.Errorf("The routines of unnamed processes shouldn't be labelled.")
+	}
+}
+
+// MARK: Helpers
+
+// profileDuring calls execute with an operation that blocks until a goroutine
+// profile has been taken while n iterations are running, and returns the
+// profile.
+func profileDuring(n int, execute func(operation Operation)) string {
+	running := make(chan struct{}, n)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		execute(func(i int) {
+			running <- struct{}{}
+			<-release
+		})
+	}()
+
+	for i := 0; i < n; i++ {
+		<-running
+	}
+
+	var buffer bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buffer, 1)
+	close(release)
+	<-done
+	return buffer.String()
+}
@@ -0,0 +1,123 @@
+package parallel
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySampleLimit is the maximum number of operation latencies a latency
+// recorder keeps between optimizations.
+const latencySampleLimit = 1024
+
+// latencyComfort is the fraction of the latency target below which a latency
+// optimizer adds routines.
+const latencyComfort = 0.8
+
+// latencyRecorder types sample the latencies of the operations executed
+// between optimizations. Once the recorder is full, samples are replaced at
+// random so that the recorder holds a uniform sample of every latency.
+type latencyRecorder struct {
+	mutex sync.Mutex
+
+	// The sampled latencies.
+	samples []time.Duration
+
+	// The number of latencies recorded since the recorder was last reset.
+	count int
+
+	// The state of the recorder's random number generator.
+	state uint64
+}
+
+// latencyOptimizer types choose a number of routines that keeps the 95th
+// percentile of operation latencies below a target. Routines are removed in
+// proportion to the excess when latency is above the target, and added one at
+// a time when it is comfortably below.
+type latencyOptimizer struct {
+	// The number of routines the optimizer calls for.
+	routines float64
+}
+
+// MARK: Initializers
+
+// newLatencyRecorder creates and returns a new latency recorder.
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{
+		samples: make([]time.Duration, 0, latencySampleLimit),
+		state:   uint64(time.Now().UnixNano()) | 1,
+	}
+}
+
+// MARK: Private methods
+
+// record records the latency of an operation.
+func (r *latencyRecorder) record(latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.count++
+	if len(r.samples) < latencySampleLimit {
+		r.samples = append(r.samples, latency)
+		return
+	}
+
+	r.state ^= r.state << 13
+	r.state ^= r.state >> 7
+	r.state ^= r.state << 17
+	if i := int(r.state % uint64(r.count)); i < latencySampleLimit {
+		r.samples[i] = latency
+	}
+}
+
+// percentile returns the q-th quantile of the latencies recorded since the
+// last call and resets the recorder. It returns false if no latencies were
+// recorded.
+func (r *latencyRecorder) percentile(q float64) (time.Duration, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.samples) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(r.samples, func(i int, j int) bool {
+		return r.samples[i] < r.samples[j]
+	})
+	i := int(math.Ceil(q*float64(len(r.samples)))) - 1
+	latency := r.samples[maxInt(i, 0)]
+
+	r.samples = r.samples[:0]
+	r.count = 0
+	return latency, true
+}
+
+// reset discards the recorded latencies.
+func (r *latencyRecorder) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.samples = r.samples[:0]
+	r.count = 0
+}
+
+// reset resets the optimizer to call for the specified number of routines.
+func (o *latencyOptimizer) reset(routines int) {
+	o.routines = float64(routines)
+}
+
+// next returns the number of routines to use given the 95th percentile of
+// recent latencies, along with the error between the target and the latency
+// relative to the target.
+func (o *latencyOptimizer) next(p95 time.Duration, target time.Duration, maxRoutines int) (float64, float64) {
+	ratio := float64(p95) / float64(target)
+	if ratio > 1.0 {
+		o.routines = math.Max(1.0, math.Floor(o.routines/ratio))
+	} else if ratio < latencyComfort {
+		o.routines++
+	}
+
+	o.routines = math.Min(o.routines, math.Max(1.0, float64(maxRoutines)))
+	return o.routines, 1.0 - ratio
+}
@@ -0,0 +1,96 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestLatencyRecorderPercentile(t *testing.T) {
+	r := newLatencyRecorder()
+	for i := 1; i <= 100; i++ {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+
+	if p95, ok := r.percentile(0.95); !ok || p95 != 95*time.Millisecond {
+		t.Errorf("95th percentile, %s, should be 95ms.", p95)
+	}
+
+	if _, ok := r.percentile(0.95); ok {
+		t.Errorf("The recorder should be reset after taking a percentile.")
+	}
+}
+
+func TestLatencyRecorderLimit(t *testing.T) {
+	r := newLatencyRecorder()
+	for i := 0; i < 4*latencySampleLimit; i++ {
+		r.record(time.Duration(i))
+	}
+
+	if len(r.samples) != latencySampleLimit {
+		t.Errorf("Sample count, %d, should be limited to %d.", len(r.samples), latencySampleLimit)
+	}
+
+	// A uniform sample of every latency should include later latencies.
+	if p95, _ := r.percentile(0.95); p95 < time.Duration(latencySampleLimit) {
+		t.Errorf("95th percentile, %d, should include replaced samples.", p95)
+	}
+}
+
+func TestLatencyOptimizer(t *testing.T) {
+	var o latencyOptimizer
+	o.reset(8)
+
+	if u, _ := o.next(20*time.Millisecond, 10*time.Millisecond, 16); u != 4.0 {
+		t.Errorf("Routines, %f, should be halved when latency is twice the target.", u)
+	}
+
+	if u, _ := o.next(9*time.Millisecond, 10*time.Millisecond, 16); u != 4.0 {
+		t.Errorf("Routines, %f, should be held just below the target.", u)
+	}
+
+	if u, _ := o.next(5*time.Millisecond, 10*time.Millisecond, 16); u != 5.0 {
+		t.Errorf("Routines, %f, should increase well below the target.", u)
+	}
+}
+
+func TestVariableProcessLatencyTarget(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	c.LatencyTarget = 3 * time.Millisecond
+	p := NewVariableProcess(10*time.Millisecond, 1, 32, c, false)
+	p.SetOptimizer(OptimizerLatency)
+
+	// Operations contend on a shared resource, so their latency grows with the
+	// number of routines.
+	var resource sync.Mutex
+	v := make([]int, 300)
+	p.Execute(len(v), func(i int) {
+		resource.Lock()
+		time.Sleep(time.Millisecond)
+		resource.Unlock()
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Stats.PeakRoutines > 8 {
+		t.Errorf("Peak routines, %d, should stay near the latency target.", s.Stats.PeakRoutines)
+	}
+}
+
+func TestMisuseLatencyTarget(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetOptimizer(OptimizerLatency)
+
+	expectMisuse(t, "target must be positive", func() {
+		p.Execute(10, func(i int) {})
+	})
+}
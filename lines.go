@@ -0,0 +1,263 @@
+package parallel
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// The number of chunks per CPU that may be read but not yet written.
+const lineChunksPerCPU = 4
+
+// LineOperation types process a chunk of lines read from a file. Responders
+// should process the lines of the chunk-th chunk and return the lines that
+// should be written to the output.
+type LineOperation func(chunk int, lines []string) []string
+
+// lineChunk types hold a chunk of lines and its index in the input.
+type lineChunk struct {
+	index int
+	lines []string
+}
+
+// lineStream types feed the chunks read from an input to the routines of a
+// process and write the chunks' output.
+type lineStream struct {
+	// The chunks that have been read and not yet processed. Closed once the
+	// input has been read.
+	chunks chan lineChunk
+
+	// Holds a value for every chunk that has been read and not yet written, so
+	// that the input is never read too far ahead of the output.
+	slots chan struct{}
+
+	// Closed to stop reading and processing chunks.
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// Closed once the reader has returned, and the error it returned.
+	readDone chan struct{}
+	readErr  error
+
+	// The writer the output is written to and whether chunks are written in
+	// the order they were read.
+	w       io.Writer
+	ordered bool
+
+	// A mutex to protect the fields below.
+	mutex sync.Mutex
+
+	// The output of the chunks that finished before the next chunk to write,
+	// if the output is ordered.
+	pending map[int][]string
+	next    int
+
+	// The first error returned by the writer.
+	writeErr error
+}
+
+// MARK: Public functions
+
+// ExecuteFile opens the file at path and processes its lines with
+// ExecuteLines.
+func ExecuteFile(process Process, path string, w io.Writer, linesPerChunk int, ordered bool, operation LineOperation) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return ExecuteLines(process, f, w, linesPerChunk, ordered, operation)
+}
+
+// ExecuteLines reads r line by line, groups the lines into chunks of
+// linesPerChunk lines and processes the chunks in parallel on process.
+//
+// The lines returned by the operation are written to w, one per line. If
+// ordered is true, chunks are written in the order they were read, otherwise
+// they are written as soon as they finish processing. A nil writer discards
+// the output.
+//
+// Chunks are streamed to the process' routines as they are read, so a slow
+// chunk only delays the routine processing it. The input is read at most a few
+// chunks per CPU ahead of the output, so large files are never held in memory
+// all at once.
+func ExecuteLines(process Process, r io.Reader, w io.Writer, linesPerChunk int, ordered bool, operation LineOperation) error {
+	if linesPerChunk < 1 {
+		linesPerChunk = 1
+	}
+
+	s := newLineStream(w, ordered, lineChunksPerCPU*runtime.NumCPU())
+	go s.read(bufio.NewReader(r), linesPerChunk)
+
+	// Every iteration processes chunks until the input is exhausted, so the
+	// process can run as many routines as there are iterations.
+	workers := maxInt(process.NumRoutines(), runtime.NumCPU())
+	process.Execute(workers, func(i int) {
+		s.work(process, operation)
+	})
+
+	s.stop()
+	<-s.readDone
+
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	return s.readErr
+}
+
+// MARK: Private functions
+
+// newLineStream creates and returns a new line stream that reads at most
+// window chunks ahead of the output.
+func newLineStream(w io.Writer, ordered bool, window int) *lineStream {
+	s := &lineStream{
+		chunks:   make(chan lineChunk, window),
+		slots:    make(chan struct{}, window),
+		done:     make(chan struct{}),
+		readDone: make(chan struct{}),
+		w:        w,
+		ordered:  ordered,
+	}
+
+	if ordered {
+		s.pending = make(map[int][]string)
+	}
+	return s
+}
+
+// readChunk reads up to linesPerChunk lines from reader.
+func readChunk(reader *bufio.Reader, linesPerChunk int) ([]string, error) {
+	lines := make([]string, 0, linesPerChunk)
+	for len(lines) < linesPerChunk {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+
+		if err != nil {
+			return lines, err
+		}
+	}
+
+	return lines, nil
+}
+
+// writeLines writes lines to w, terminating each with a newline.
+func writeLines(w io.Writer, lines []string) error {
+	if w == nil {
+		return nil
+	}
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MARK: Private methods
+
+// read reads chunks of linesPerChunk lines from reader until the input is
+// exhausted or the stream is stopped.
+func (s *lineStream) read(reader *bufio.Reader, linesPerChunk int) {
+	defer close(s.readDone)
+	defer close(s.chunks)
+
+	for index := 0; ; index++ {
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.done:
+			return
+		}
+
+		lines, err := readChunk(reader, linesPerChunk)
+		if len(lines) > 0 {
+			select {
+			case s.chunks <- lineChunk{index: index, lines: lines}:
+			case <-s.done:
+				return
+			}
+		}
+
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			s.readErr = err
+			return
+		}
+	}
+}
+
+// work processes chunks with operation until the input is exhausted or the
+// stream or process is stopped.
+func (s *lineStream) work(process Process, operation LineOperation) {
+	for {
+		select {
+		case chunk, ok := <-s.chunks:
+			if !ok {
+				return
+			}
+
+			if !s.write(chunk.index, operation(chunk.index, chunk.lines)) {
+				process.Stop()
+				return
+			}
+
+			if processStopped(process) {
+				s.stop()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// write writes the output of the index-th chunk, or holds on to it until the
+// chunks before it have been written if the output is ordered. It returns
+// false if the output couldn't be written.
+func (s *lineStream) write(index int, output []string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.writeErr != nil {
+		return false
+	}
+
+	if !s.ordered {
+		<-s.slots
+		s.writeErr = writeLines(s.w, output)
+	} else {
+		s.pending[index] = output
+		for s.writeErr == nil {
+			output, ok := s.pending[s.next]
+			if !ok {
+				break
+			}
+
+			delete(s.pending, s.next)
+			s.next++
+			<-s.slots
+			s.writeErr = writeLines(s.w, output)
+		}
+	}
+
+	if s.writeErr != nil {
+		s.stop()
+		return false
+	}
+	return true
+}
+
+// stop stops reading and processing chunks.
+func (s *lineStream) stop() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
@@ -0,0 +1,166 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestExecuteLinesOrdered(t *testing.T) {
+	input, expected := testLines(10000)
+
+	var output bytes.Buffer
+	p := NewFixedProcess(4)
+	err := ExecuteLines(p, strings.NewReader(input), &output, 7, true, func(chunk int, lines []string) []string {
+		for i := range lines {
+			lines[i] = strings.ToUpper(lines[i])
+		}
+		return lines
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if output.String() != expected {
+		t.Errorf("Ordered output should match the input order.")
+	}
+}
+
+func TestExecuteLinesUnordered(t *testing.T) {
+	input, expected := testLines(10000)
+
+	var output bytes.Buffer
+	p := NewFixedProcess(4)
+	err := ExecuteLines(p, strings.NewReader(input), &output, 7, false, func(chunk int, lines []string) []string {
+		for i := range lines {
+			lines[i] = strings.ToUpper(lines[i])
+		}
+		return lines
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	outputLines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expectedLines := strings.Split(strings.TrimSpace(expected), "\n")
+	sort.Strings(outputLines)
+	sort.Strings(expectedLines)
+
+	if strings.Join(outputLines, "\n") != strings.Join(expectedLines, "\n") {
+		t.Errorf("Unordered output should contain every input line exactly once.")
+	}
+}
+
+func TestExecuteLinesSlowChunk(t *testing.T) {
+	input, _ := testLines(1000)
+
+	// The first chunk waits for many later chunks, which only finish if chunks
+	// are streamed to the routines that are free.
+	var processed int64
+	var waited time.Duration
+	p := NewFixedProcess(2)
+	err := ExecuteLines(p, strings.NewReader(input), nil, 1, false, func(chunk int, lines []string) []string {
+		if chunk == 0 {
+			start := time.Now()
+			for atomic.LoadInt64(&processed) < 100 && time.Since(start) < 5*time.Second {
+				time.Sleep(time.Millisecond)
+			}
+			waited = time.Since(start)
+		}
+
+		atomic.AddInt64(&processed, 1)
+		return lines
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if waited >= 5*time.Second {
+		t.Errorf("Chunks should keep being processed while a slow chunk executes.")
+	}
+
+	if processed != 1000 {
+		t.Errorf("Processed chunk count, %d, should be 1000.", processed)
+	}
+}
+
+func TestExecuteLinesWriteError(t *testing.T) {
+	input, _ := testLines(1000)
+
+	p := NewFixedProcess(4)
+	err := ExecuteLines(p, strings.NewReader(input), failingWriter{}, 3, true, func(chunk int, lines []string) []string {
+		return lines
+	})
+
+	if err != errTestWrite {
+		t.Errorf("Error, %v, should be %s.", err, errTestWrite)
+	}
+}
+
+func TestExecuteFile(t *testing.T) {
+	input, _ := testLines(100)
+
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "input.txt")
+	if err = ioutil.WriteFile(path, []byte(strings.TrimSuffix(input, "\n")), 0644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	count := safeInt{}
+	p := NewFixedProcess(2)
+	err = ExecuteFile(p, path, nil, 10, false, func(chunk int, lines []string) []string {
+		count.add(len(lines))
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if count.get() != 100 {
+		t.Errorf("Line count, %d, should be 100.", count.get())
+	}
+}
+
+// MARK: Helpers
+
+// errTestWrite is returned by failingWriter.
+var errTestWrite = errors.New("write failed")
+
+// failingWriter types fail every write.
+type failingWriter struct{}
+
+// Write returns errTestWrite.
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errTestWrite
+}
+
+// testLines returns n lines of input and their upper cased output.
+func testLines(n int) (string, string) {
+	var input, output strings.Builder
+	for i := 0; i < n; i++ {
+		line := fmt.Sprintf("line %d", i)
+		input.WriteString(line + "\n")
+		output.WriteString(strings.ToUpper(line) + "\n")
+	}
+
+	return input.String(), output.String()
+}
@@ -0,0 +1,131 @@
+package parallel
+
+import "sync"
+
+// Listener types are told about the scaling decisions of a variable process,
+// so applications can log or alert on them without polling NumRoutines.
+// Listeners are called on the process' optimizing goroutine after each
+// optimization, so a slow listener delays the next one.
+type Listener interface {
+	// OnOptimize is called after each optimization with the controller's
+	// signals and the number of routines it called for.
+	OnOptimize(sample ControllerSample)
+
+	// OnRoutineAdded is called when an optimization adds n routines.
+	OnRoutineAdded(n int)
+
+	// OnRoutineRemoved is called when an optimization removes n routines.
+	// The routines park after finishing their current iterations, and a
+	// later optimization that adds routines wakes them again.
+	OnRoutineRemoved(n int)
+
+	// OnComplete is called with a summary of the execution when the
+	// process' executions end.
+	OnComplete(report *RunSummary)
+}
+
+// ListenerFuncs types are listeners made from functions. Nil functions are
+// not called.
+type ListenerFuncs struct {
+	Optimize       func(sample ControllerSample)
+	RoutineAdded   func(n int)
+	RoutineRemoved func(n int)
+	Complete       func(report *RunSummary)
+}
+
+// listenerSet types are the listeners registered with a process.
+type listenerSet struct {
+	mutex     sync.Mutex
+	listeners []Listener
+}
+
+// MARK: Public methods
+
+// OnOptimize calls f.Optimize if it isn't nil.
+func (f *ListenerFuncs) OnOptimize(sample ControllerSample) {
+	if f.Optimize != nil {
+		f.Optimize(sample)
+	}
+}
+
+// OnRoutineAdded calls f.RoutineAdded if it isn't nil.
+func (f *ListenerFuncs) OnRoutineAdded(n int) {
+	if f.RoutineAdded != nil {
+		f.RoutineAdded(n)
+	}
+}
+
+// OnRoutineRemoved calls f.RoutineRemoved if it isn't nil.
+func (f *ListenerFuncs) OnRoutineRemoved(n int) {
+	if f.RoutineRemoved != nil {
+		f.RoutineRemoved(n)
+	}
+}
+
+// OnComplete calls f.Complete if it isn't nil.
+func (f *ListenerFuncs) OnComplete(report *RunSummary) {
+	if f.Complete != nil {
+		f.Complete(report)
+	}
+}
+
+// MARK: Private methods
+
+// add registers l.
+func (s *listenerSet) add(l Listener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	listeners := make([]Listener, 0, len(s.listeners)+1)
+	s.listeners = append(append(listeners, s.listeners...), l)
+}
+
+// remove unregisters the first registration of l and returns whether or not
+// it was registered.
+func (s *listenerSet) remove(l Listener) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, listener := range s.listeners {
+		if listener == l {
+			listeners := make([]Listener, 0, len(s.listeners)-1)
+			s.listeners = append(append(listeners, s.listeners[:i]...), s.listeners[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the registered listeners. The returned slice is never modified.
+func (s *listenerSet) get() []Listener {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.listeners
+}
+
+// optimized tells the listeners about an optimization that called for
+// sample's routines and added and removed routines.
+func (s *listenerSet) optimized(sample ControllerSample, added int, removed int) {
+	for _, l := range s.get() {
+		l.OnOptimize(sample)
+		if added > 0 {
+			l.OnRoutineAdded(added)
+		}
+		if removed > 0 {
+			l.OnRoutineRemoved(removed)
+		}
+	}
+}
+
+// completed tells the listeners that the process' executions have ended.
+func (s *listenerSet) completed(summary func() *RunSummary) {
+	listeners := s.get()
+	if len(listeners) == 0 {
+		return
+	}
+
+	report := summary()
+	for _, l := range listeners {
+		l.OnComplete(report)
+	}
+}
@@ -0,0 +1,87 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestVariableProcessListener(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	var mutex sync.Mutex
+	optimizations := 0
+	var reports []*RunSummary
+	l := &ListenerFuncs{
+		Optimize: func(sample ControllerSample) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			optimizations++
+
+			// Listeners are called without the process' locks held.
+			p.NumRoutines()
+		},
+		Complete: func(report *RunSummary) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			reports = append(reports, report)
+		},
+	}
+	p.AddListener(l)
+
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	mutex.Lock()
+	if optimizations == 0 {
+		t.Errorf("The listener should be told about optimizations.")
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Reports, %d, should be 1.", len(reports))
+	}
+	if reports[0].Stats.Progress.Completed != 100 {
+		t.Errorf("The report's progress, %+v, should be complete.", reports[0].Stats.Progress)
+	}
+	mutex.Unlock()
+
+	if !p.RemoveListener(l) {
+		t.Errorf("The listener should have been registered.")
+	}
+	if p.RemoveListener(l) {
+		t.Errorf("The listener should no longer be registered.")
+	}
+
+	p.Execute(10, func(i int) {})
+	if len(reports) != 1 {
+		t.Errorf("Reports, %d, should be 1 after the listener is removed.", len(reports))
+	}
+}
+
+func TestListenerSetOptimized(t *testing.T) {
+	var s listenerSet
+	added, removed, optimized := 0, 0, 0
+	s.add(&ListenerFuncs{
+		Optimize:       func(sample ControllerSample) { optimized++ },
+		RoutineAdded:   func(n int) { added += n },
+		RoutineRemoved: func(n int) { removed += n },
+	})
+	s.add(&ListenerFuncs{})
+
+	s.optimized(ControllerSample{Routines: 4.0}, 3, 0)
+	s.optimized(ControllerSample{Routines: 2.0}, 0, 2)
+	s.optimized(ControllerSample{Routines: 2.0}, 0, 0)
+
+	if optimized != 3 {
+		t.Errorf("Optimizations, %d, should be 3.", optimized)
+	}
+	if added != 3 {
+		t.Errorf("Added routines, %d, should be 3.", added)
+	}
+	if removed != 2 {
+		t.Errorf("Removed routines, %d, should be 2.", removed)
+	}
+}
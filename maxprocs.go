@@ -0,0 +1,111 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxProcsClaim types hold the number of routines a process adjusting
+// GOMAXPROCS is running.
+type maxProcsClaim struct {
+	routines int
+}
+
+// maxProcsAdjuster types set GOMAXPROCS to the total number of routines the
+// processes adjusting it are running, never exceeding the value it had before
+// the first of them began. The value is restored once the last of them ends.
+type maxProcsAdjuster struct {
+	mutex sync.Mutex
+
+	// GOMAXPROCS before the first claim was acquired.
+	original int
+
+	// The claims of the processes adjusting GOMAXPROCS.
+	claims map[*maxProcsClaim]struct{}
+}
+
+// The adjuster shared by every process in the program.
+var globalMaxProcs maxProcsAdjuster
+
+// MARK: Private methods
+
+// procs returns GOMAXPROCS, or its value before the first claim was acquired
+// if it is being adjusted.
+func (a *maxProcsAdjuster) procs() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.claims) > 0 {
+		return a.original
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// acquire adds a claim for the specified number of routines and returns it.
+func (a *maxProcsAdjuster) acquire(routines int) *maxProcsClaim {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.claims) == 0 {
+		a.original = runtime.GOMAXPROCS(0)
+		a.claims = make(map[*maxProcsClaim]struct{})
+	}
+
+	c := &maxProcsClaim{routines: routines}
+	a.claims[c] = struct{}{}
+	a.apply()
+	return c
+}
+
+// set changes the number of routines of claim c. A nil claim is ignored.
+func (a *maxProcsAdjuster) set(c *maxProcsClaim, routines int) {
+	if c == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, ok := a.claims[c]; !ok || c.routines == routines {
+		return
+	}
+
+	c.routines = routines
+	a.apply()
+}
+
+// release removes claim c, restoring GOMAXPROCS if no claims remain. A nil
+// claim is ignored.
+func (a *maxProcsAdjuster) release(c *maxProcsClaim) {
+	if c == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, ok := a.claims[c]; !ok {
+		return
+	}
+
+	delete(a.claims, c)
+	if len(a.claims) == 0 {
+		runtime.GOMAXPROCS(a.original)
+		return
+	}
+	a.apply()
+}
+
+// apply sets GOMAXPROCS from the claims. Setting GOMAXPROCS stops the world,
+// so it is only set when it changes. The mutex must be held.
+func (a *maxProcsAdjuster) apply() {
+	total := 0
+	for c := range a.claims {
+		total += c.routines
+	}
+
+	n := maxInt(1, minInt(total, a.original))
+	if runtime.GOMAXPROCS(0) != n {
+		runtime.GOMAXPROCS(n)
+	}
+}
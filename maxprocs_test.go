@@ -0,0 +1,124 @@
+package parallel
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestMaxProcsAdjuster(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	var a maxProcsAdjuster
+	c1 := a.acquire(2)
+	if n := runtime.GOMAXPROCS(0); n != 2 {
+		t.Errorf("GOMAXPROCS, %d, should be lowered to 2.", n)
+	}
+	if n := a.procs(); n != 4 {
+		t.Errorf("Procs, %d, should be the original GOMAXPROCS.", n)
+	}
+
+	c2 := a.acquire(1)
+	if n := runtime.GOMAXPROCS(0); n != 3 {
+		t.Errorf("GOMAXPROCS, %d, should be the claims' total.", n)
+	}
+
+	a.set(c1, 10)
+	if n := runtime.GOMAXPROCS(0); n != 4 {
+		t.Errorf("GOMAXPROCS, %d, shouldn't be raised above its original value.", n)
+	}
+
+	a.release(c1)
+	if n := runtime.GOMAXPROCS(0); n != 1 {
+		t.Errorf("GOMAXPROCS, %d, should follow the remaining claim.", n)
+	}
+
+	a.set(c2, 0)
+	if n := runtime.GOMAXPROCS(0); n != 1 {
+		t.Errorf("GOMAXPROCS, %d, should be at least 1.", n)
+	}
+
+	a.release(c2)
+	a.release(c2)
+	a.set(nil, 2)
+	a.release(nil)
+	if n := runtime.GOMAXPROCS(0); n != 4 {
+		t.Errorf("GOMAXPROCS, %d, should be restored once no claims remain.", n)
+	}
+}
+
+func TestUsageScaledByUnadjustedMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	claim := globalMaxProcs.acquire(1)
+	defer globalMaxProcs.release(claim)
+
+	if n := runtime.GOMAXPROCS(0); n != 1 {
+		t.Fatalf("GOMAXPROCS, %d, should be lowered to 1.", n)
+	}
+
+	s := gcSample{cpuFraction: 0.5}
+	if cpus := s.cpusSince(time.Now(), 0.0); cpus != 2.0 {
+		t.Errorf("The collector's CPUs, %f, should be scaled by the unadjusted GOMAXPROCS.", cpus)
+	}
+}
+
+func TestGCFractionScaledByUnadjustedMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	claim := globalMaxProcs.acquire(1)
+	defer globalMaxProcs.release(claim)
+
+	if n := runtime.GOMAXPROCS(0); n != 1 {
+		t.Fatalf("GOMAXPROCS, %d, should be lowered to 1.", n)
+	}
+
+	start := gcSample{time: time.Now(), cpuReported: true}
+	end := gcSample{time: start.time.Add(time.Second), cpu: 1.0, cpuReported: true}
+	if fraction := end.cpuFractionSince(start); fraction != 0.25 {
+		t.Errorf("The collector's CPU fraction, %f, should be scaled by the unadjusted GOMAXPROCS.", fraction)
+	}
+}
+
+func TestFixedProcessAdjustMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	p := NewFixedProcess(2)
+	p.SetAdjustMaxProcs(true)
+
+	var procs int32
+	p.Execute(10, func(i int) {
+		atomic.StoreInt32(&procs, int32(runtime.GOMAXPROCS(0)))
+	})
+
+	if procs != 2 {
+		t.Errorf("GOMAXPROCS, %d, should have been lowered to the number of routines.", procs)
+	}
+	if n := runtime.GOMAXPROCS(0); n != 4 {
+		t.Errorf("GOMAXPROCS, %d, should be restored after executing.", n)
+	}
+}
+
+func TestVariableProcessAdjustMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(5*time.Millisecond, 1, 1, c, false)
+	p.SetAdjustMaxProcs(true)
+
+	var procs int32
+	p.Execute(20, func(i int) {
+		atomic.StoreInt32(&procs, int32(runtime.GOMAXPROCS(0)))
+		time.Sleep(time.Millisecond)
+	})
+
+	if procs != 1 {
+		t.Errorf("GOMAXPROCS, %d, should have followed the number of routines.", procs)
+	}
+	if n := runtime.GOMAXPROCS(0); n != 4 {
+		t.Errorf("GOMAXPROCS, %d, should be restored after executing.", n)
+	}
+}
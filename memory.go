@@ -0,0 +1,72 @@
+package parallel
+
+import (
+	"math"
+	"runtime"
+)
+
+// memoryReporter types report the program's heap in use, both in bytes and as
+// a fraction of a memory limit.
+type memoryReporter struct {
+	// The limit the heap is measured against. If zero, the runtime's soft
+	// memory limit, GOMEMLIMIT, is used when one is set.
+	limit uint64
+}
+
+// MARK: Private methods
+
+// usage returns the number of bytes in the heap's in-use spans and their
+// fraction of the reporter's limit. The fraction is zero if there is no limit.
+func (r memoryReporter) usage() (uint64, float64) {
+	heap := heapInuse()
+
+	limit := r.limit
+	if limit == 0 {
+		limit = runtimeMemoryLimit()
+	}
+
+	if limit == 0 {
+		return heap, 0.0
+	}
+	return heap, float64(heap) / float64(limit)
+}
+
+// MARK: Private functions
+
+// heapInuse returns the number of bytes in the heap's in-use spans.
+func heapInuse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+// memoryLimitedRoutines returns m limited so that, if current routines put
+// heap bytes in use over limit, the routines are scaled down in proportion to
+// the excess. At least one routine is always returned.
+func memoryLimitedRoutines(m int, current int, heap uint64, limit uint64) int {
+	if limit == 0 || heap <= limit {
+		return m
+	}
+
+	n := int(float64(current) * float64(limit) / float64(heap))
+	if n < 1 {
+		n = 1
+	}
+	return minInt(m, n)
+}
+
+// memoryThrottledRoutines returns m limited to current routines if the heap's
+// fraction of its limit has reached highWater, so that no routines are added
+// while memory is running out.
+func memoryThrottledRoutines(m int, current int, fraction float64, highWater float64) int {
+	if highWater > 0.0 && fraction >= highWater {
+		return minInt(m, current)
+	}
+	return m
+}
+
+// isMemoryLimitSet returns whether limit, as returned by the runtime, is an
+// actual limit rather than the default of no limit.
+func isMemoryLimitSet(limit int64) bool {
+	return limit > 0 && limit < math.MaxInt64
+}
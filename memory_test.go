@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestMemoryLimitedRoutines(t *testing.T) {
+	cases := []struct {
+		m, current  int
+		heap, limit uint64
+		limited     int
+	}{
+		{8, 4, 100, 0, 8},
+		{8, 4, 100, 200, 8},
+		{8, 4, 200, 100, 2},
+		{8, 1, 1000, 1, 1},
+		{1, 8, 200, 100, 1},
+	}
+
+	for _, c := range cases {
+		if n := memoryLimitedRoutines(c.m, c.current, c.heap, c.limit); n != c.limited {
+			t.Errorf("memoryLimitedRoutines(%d, %d, %d, %d) = %d, should be %d.", c.m, c.current, c.heap, c.limit, n, c.limited)
+		}
+	}
+}
+
+func TestMemoryThrottledRoutines(t *testing.T) {
+	if n := memoryThrottledRoutines(8, 4, 0.95, 0.9); n != 4 {
+		t.Errorf("Routines, %d, should be held at 4 above the high water mark.", n)
+	}
+
+	if n := memoryThrottledRoutines(2, 4, 0.95, 0.9); n != 2 {
+		t.Errorf("Routines, %d, should still be removed above the high water mark.", n)
+	}
+
+	if n := memoryThrottledRoutines(8, 4, 0.5, 0.9); n != 8 {
+		t.Errorf("Routines, %d, should be added below the high water mark.", n)
+	}
+
+	if n := memoryThrottledRoutines(8, 4, 0.95, 0.0); n != 8 {
+		t.Errorf("Routines, %d, should be added without a high water mark.", n)
+	}
+}
+
+func TestMemoryReporter(t *testing.T) {
+	heap, fraction := memoryReporter{limit: 1 << 40}.usage()
+	if heap == 0 {
+		t.Errorf("Heap in use should be positive.")
+	}
+
+	if expected := float64(heap) / float64(1<<40); fraction != expected {
+		t.Errorf("Fraction, %g, should be %g.", fraction, expected)
+	}
+}
+
+func TestVariableProcessMemoryLimit(t *testing.T) {
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	c.MemoryLimit = 1
+	p := NewVariableProcess(time.Millisecond, 1, 16, c, false)
+
+	v := make([]int, 200)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Stats.PeakRoutines != 1 {
+		t.Errorf("Peak routines, %d, should be 1 while the heap is over the limit.", s.Stats.PeakRoutines)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build go1.19
+// +build go1.19
+
+package parallel
+
+import "runtime/debug"
+
+// MARK: Private functions
+
+// runtimeMemoryLimit returns the runtime's soft memory limit, or zero if none
+// is set.
+func runtimeMemoryLimit() uint64 {
+	if limit := debug.SetMemoryLimit(-1); isMemoryLimitSet(limit) {
+		return uint64(limit)
+	}
+	return 0
+}
@@ -0,0 +1,12 @@
+//go:build !go1.19
+// +build !go1.19
+
+package parallel
+
+// MARK: Private functions
+
+// runtimeMemoryLimit returns zero, since the runtime has no soft memory limit
+// before Go 1.19.
+func runtimeMemoryLimit() uint64 {
+	return 0
+}
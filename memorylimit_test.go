@@ -0,0 +1,55 @@
+//go:build go1.19
+// +build go1.19
+
+package parallel
+
+import (
+	"math"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestRuntimeMemoryLimit(t *testing.T) {
+	previous := debug.SetMemoryLimit(1 << 40)
+	defer debug.SetMemoryLimit(previous)
+
+	if limit := runtimeMemoryLimit(); limit != 1<<40 {
+		t.Errorf("Memory limit, %d, should be %d.", limit, uint64(1<<40))
+	}
+
+	debug.SetMemoryLimit(math.MaxInt64)
+	if limit := runtimeMemoryLimit(); limit != 0 {
+		t.Errorf("Memory limit, %d, should be 0 when no limit is set.", limit)
+	}
+}
+
+func TestVariableProcessMemoryHighWater(t *testing.T) {
+	// With a soft limit far below the heap, the high water mark is always
+	// exceeded and no routines are added.
+	previous := debug.SetMemoryLimit(1 << 10)
+	defer debug.SetMemoryLimit(previous)
+
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	c.MemoryHighWater = 0.9
+	p := NewVariableProcess(time.Millisecond, 2, 16, c, false)
+
+	v := make([]int, 200)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Stats.PeakRoutines > 2 {
+		t.Errorf("Peak routines, %d, should not exceed the initial 2 above the high water mark.", s.Stats.PeakRoutines)
+	}
+}
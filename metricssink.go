@@ -0,0 +1,15 @@
+package parallel
+
+// MetricsSink types receive the controller signals of a variable process at
+// each optimization, so that processes can report to any metrics stack.
+// Samples are handed to sinks on a dedicated goroutine, so a slow sink never
+// delays the optimization that produced them, but samples are dropped if the
+// sink falls behind.
+type MetricsSink interface {
+	// RecordSample records the controller's signals at an optimization.
+	RecordSample(sample ControllerSample)
+
+	// Flush is called when the process' executions end, after their last
+	// sample has been recorded.
+	Flush()
+}
@@ -0,0 +1,58 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestVariableProcessMetricsSink(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	sink := &recordingSink{}
+	p.SetMetricsSink(sink)
+	if p.MetricsSink() != sink {
+		t.Errorf("The process' metrics sink should be the one it was given.")
+	}
+
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	if sink.flushes != 1 {
+		t.Errorf("Flushes, %d, should be 1.", sink.flushes)
+	}
+
+	for i, sample := range sink.samples {
+		if sample.Routines < 1.0 {
+			t.Errorf("Sample %d, %+v, should call for at least one routine.", i, sample)
+		}
+	}
+}
+
+// MARK: Helpers
+
+// recordingSink types record the samples they receive.
+type recordingSink struct {
+	mutex   sync.Mutex
+	samples []ControllerSample
+	flushes int
+}
+
+func (s *recordingSink) RecordSample(sample ControllerSample) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.samples = append(s.samples, sample)
+}
+
+func (s *recordingSink) Flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushes++
+}
@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"fmt"
+	"time"
+)
+
+// MARK: Private functions
+
+// misuse panics with a message describing how the package was used
+// incorrectly. Misuse is reported immediately rather than surfacing later as a
+// nil pointer dereference or a hang deep inside an execution.
+func misuse(format string, args ...interface{}) {
+	panic(fmt.Sprintf("parallel: "+format, args...))
+}
+
+// checkIterations panics if iterations is negative.
+func checkIterations(method string, iterations int) {
+	if iterations < 0 {
+		misuse("%s called with %d iterations; the number of iterations must not be negative", method, iterations)
+	}
+}
+
+// checkOperation panics if isNil is true.
+func checkOperation(method string, isNil bool) {
+	if isNil {
+		misuse("%s called with a nil operation", method)
+	}
+}
+
+// checkInterval panics if interval is not positive.
+func checkInterval(method string, interval time.Duration) {
+	if interval <= 0 {
+		misuse("%s called with an interval of %s; the optimization interval must be positive", method, interval)
+	}
+}
+
+// checkRoutines panics if a routine count named name is less than one.
+func checkRoutines(method string, name string, n int) {
+	if n < 1 {
+		misuse("%s called with %s = %d; a process needs at least one goroutine", method, name, n)
+	}
+}
+
+// checkStackHint panics if a stack hint is negative.
+func checkStackHint(method string, bytes int) {
+	if bytes < 0 {
+		misuse("%s called with a hint of %d bytes; the hint must not be negative", method, bytes)
+	}
+}
+
+// checkPrepare panics if the number of goroutines to prepare is negative.
+func checkPrepare(method string, n int) {
+	if n < 0 {
+		misuse("%s called with n = %d; the number of goroutines must not be negative", method, n)
+	}
+}
+
+// checkCPUAffinity panics if any of cpus is negative.
+func checkCPUAffinity(method string, cpus []int) {
+	for _, cpu := range cpus {
+		if cpu < 0 {
+			misuse("%s called with CPU %d; CPU numbers must not be negative", method, cpu)
+		}
+	}
+}
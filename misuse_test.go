@@ -0,0 +1,244 @@
+package parallel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestMisuseNegativeIterations(t *testing.T) {
+	expectMisuse(t, "must not be negative", func() {
+		NewFixedProcess(2).Execute(-1, func(i int) {})
+	})
+
+	expectMisuse(t, "must not be negative", func() {
+		c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).Execute(-1, func(i int) {})
+	})
+}
+
+func TestMisuseNilOperation(t *testing.T) {
+	expectMisuse(t, "nil operation", func() {
+		NewFixedProcess(2).Execute(10, nil)
+	})
+
+	expectMisuse(t, "nil operation", func() {
+		NewForkJoinProcess(2).ExecuteTask(nil)
+	})
+
+	expectMisuse(t, "AutoExecute called with a nil operation", func() {
+		AutoExecute(10, nil)
+	})
+
+	expectMisuse(t, "ExecuteRoutines called with a nil operation", func() {
+		NewFixedProcess(2).ExecuteRoutines(10, nil)
+	})
+
+	expectMisuse(t, "AddTask called with a nil task", func() {
+		NewDAGProcess(NewFixedProcess(2)).AddTask("a", nil, nil)
+	})
+}
+
+func TestMisuseRoutineCounts(t *testing.T) {
+	expectMisuse(t, "numRoutines = 0", func() {
+		NewFixedProcess(0)
+	})
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "maxRoutines = 0", func() {
+		NewVariableProcess(time.Millisecond, 1, 0, c, false)
+	})
+
+	p := NewVariableProcess(time.Millisecond, 1, 2, c, false)
+	expectMisuse(t, "n = -1", func() {
+		p.SetMaxRoutines(-1)
+	})
+}
+
+func TestMisuseInterval(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must be positive", func() {
+		NewVariableProcess(0, 1, 2, c, false)
+	})
+}
+
+func TestMisuseOverheadTarget(t *testing.T) {
+	p := NewFixedProcess(2)
+	expectMisuse(t, "between 0 and 1", func() {
+		p.SetOverheadTarget(0)
+	})
+}
+
+func TestMisuseBlockSize(t *testing.T) {
+	expectMisuse(t, "must not be negative", func() {
+		NewFixedProcess(1).SetBlockSize(-1)
+	})
+}
+
+func TestMisuseStackHint(t *testing.T) {
+	expectMisuse(t, "must not be negative", func() {
+		NewFixedProcess(2).SetStackHint(-1)
+	})
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetStackHint(-1)
+	})
+}
+
+func TestMisuseCPUCount(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetCPUCount(-1)
+	})
+}
+
+func TestMisuseUsageHistoryLength(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetUsageHistoryLength(-1)
+	})
+}
+
+func TestMisuseClock(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "nil clock", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetClock(nil)
+	})
+
+	expectMisuse(t, "must be positive", func() {
+		NewManualClock(time.Now()).NewTicker(0)
+	})
+}
+
+func TestMisuseUsageSamples(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "at least one sample", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetUsageSamples(0)
+	})
+}
+
+func TestMisuseNUMANode(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must be -1 or a node number", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetNUMANode(-2)
+	})
+}
+
+func TestMisuseWarmUp(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetWarmUp(-time.Second)
+	})
+}
+
+func TestMisuseNilListener(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "nil listener", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).AddListener(nil)
+	})
+}
+
+func TestMisuseLatencyPercentile(t *testing.T) {
+	expectMisuse(t, "must be between 0 and 1", func() {
+		newLatencyHistogram().Percentile(1.5)
+	})
+}
+
+func TestMisuseThroughputWindow(t *testing.T) {
+	expectMisuse(t, "window must be positive", func() {
+		NewThroughputMeter(0)
+	})
+}
+
+func TestMisuseProbeSignalLength(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, true).SetProbeSignalLength(-1)
+	})
+}
+
+func TestMisuseStallTimeout(t *testing.T) {
+	expectMisuse(t, "must not be negative", func() {
+		NewFixedProcess(1).SetStallTimeout(-time.Second, func(Stall) {})
+	})
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "nil handler", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, true).SetStallTimeout(time.Second, nil)
+	})
+}
+
+func TestMisuseCounterShards(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	expectMisuse(t, "must not be negative", func() {
+		NewVariableProcess(time.Millisecond, 1, 2, c, true).SetCounterShards(-1)
+	})
+}
+
+func TestMisuseCPUAffinity(t *testing.T) {
+	expectMisuse(t, "CPU -1", func() {
+		NewFixedProcess(2).SetCPUAffinity([]int{0, -1})
+	})
+
+	expectMisuse(t, "CPU -2", func() {
+		c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).SetCPUAffinity([]int{-2})
+	})
+}
+
+func TestMisusePrepare(t *testing.T) {
+	expectMisuse(t, "n = -1", func() {
+		NewFixedProcess(2).Prepare(-1)
+	})
+
+	expectMisuse(t, "n = -2", func() {
+		c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+		NewVariableProcess(time.Millisecond, 1, 2, c, false).Prepare(-2)
+	})
+}
+
+func TestMisuseElementSize(t *testing.T) {
+	expectMisuse(t, "element size of 0 bytes", func() {
+		ExecuteBlocked(NewFixedProcess(2), 10, 0, func(i int) {})
+	})
+
+	expectMisuse(t, "element size of -8 bytes", func() {
+		ExecuteGridBlocked(NewFixedProcess(2), 10, 10, -8, func(x int, y int) {})
+	})
+}
+
+func TestSetOptimizationIntervalBeforeExecute(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 2, c, false)
+	p.SetOptimizationInterval(time.Second)
+
+	if p.GetOptimizationInterval() != time.Second {
+		t.Errorf("Optimization interval, %s, should be 1s.", p.GetOptimizationInterval())
+	}
+}
+
+// MARK: Helpers
+
+// expectMisuse fails the test if f does not panic with a message containing
+// substring.
+func expectMisuse(t *testing.T, substring string, f func()) {
+	t.Helper()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Errorf("Expected a panic containing %q.", substring)
+			return
+		}
+
+		message := fmt.Sprint(r)
+		if !strings.HasPrefix(message, "parallel: ") || !strings.Contains(message, substring) {
+			t.Errorf("Panic, %q, should contain %q.", message, substring)
+		}
+	}()
+
+	f()
+}
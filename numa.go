@@ -0,0 +1,114 @@
+package parallel
+
+import "sync"
+
+// NUMANode types describe a node of a machine with non-uniform memory access.
+// Memory attached to a node is faster to access from the node's CPUs than from
+// the CPUs of other nodes.
+type NUMANode struct {
+	// The node's number.
+	ID int
+
+	// The numbers of the node's CPUs.
+	CPUs []int
+}
+
+// NUMAReporter types report the number of CPUs used on each NUMA node between
+// successive calls to their Usage method. The whole machine is measured, not
+// just the program. NUMA reporters are safe for concurrent use.
+type NUMAReporter struct {
+	// A mutex to protect the reporter's measurements.
+	mutex sync.Mutex
+
+	// The machine's nodes.
+	nodes []NUMANode
+
+	// The busy and total time of each CPU at the last measurement.
+	last map[int]cpuTimes
+}
+
+// cpuTimes types contain the busy and total time of a CPU, in the units of the
+// operating system.
+type cpuTimes struct {
+	busy  float64
+	total float64
+}
+
+// MARK: Initializers
+
+// NewNUMAReporter creates and returns a new NUMA reporter measuring the
+// machine's nodes.
+func NewNUMAReporter() *NUMAReporter {
+	r := &NUMAReporter{nodes: NUMANodes()}
+	r.Reset()
+	return r
+}
+
+// MARK: Public functions
+
+// NUMANodes returns the machine's NUMA nodes ordered by their numbers. Only
+// Linux reports its nodes; on other operating systems, and on Linux machines
+// without NUMA support, nil is returned.
+func NUMANodes() []NUMANode {
+	return readNUMANodes()
+}
+
+// MARK: Public methods
+
+// Nodes returns the nodes the reporter measures.
+func (r *NUMAReporter) Nodes() []NUMANode {
+	return r.nodes
+}
+
+// Usage returns the number of CPUs used on each of the reporter's nodes since
+// the last call to Usage or Reset. The usage of a node whose CPU times can't
+// be read is zero.
+func (r *NUMAReporter) Usage() []float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	times, _ := readPerCPUTimes()
+	usage := make([]float64, len(r.nodes))
+	for i, node := range r.nodes {
+		var busy, total float64
+		for _, cpu := range node.CPUs {
+			now, ok := times[cpu]
+			last, seen := r.last[cpu]
+			if !ok || !seen || now.total <= last.total {
+				continue
+			}
+
+			busy += now.busy - last.busy
+			total += now.total - last.total
+		}
+
+		if total > 0.0 {
+			usage[i] = busy / total * float64(len(node.CPUs))
+		}
+	}
+
+	if times != nil {
+		r.last = times
+	}
+	return usage
+}
+
+// Reset restarts the reporter's measurements from now.
+func (r *NUMAReporter) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.last, _ = readPerCPUTimes()
+}
+
+// MARK: Private functions
+
+// numaNodeCPUs returns the CPUs of the NUMA node numbered id, or nil if the
+// machine has no such node.
+func numaNodeCPUs(id int) []int {
+	for _, node := range NUMANodes() {
+		if node.ID == id {
+			return node.CPUs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package parallel
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// numaRoot is the directory the kernel lists NUMA nodes in.
+const numaRoot = "/sys/devices/system/node"
+
+// cpuSetWords is the number of words in the CPU sets passed to
+// sched_setaffinity, enough for 1024 CPUs.
+const cpuSetWords = 16
+
+// cpuSet types are bit masks of CPUs.
+type cpuSet [cpuSetWords]uint64
+
+// MARK: Private functions
+
+// readNUMANodes returns the machine's NUMA nodes from sysfs.
+func readNUMANodes() []NUMANode {
+	paths, _ := filepath.Glob(filepath.Join(numaRoot, "node[0-9]*"))
+
+	var nodes []NUMANode
+	for _, path := range paths {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "node"))
+		if err != nil {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(path, "cpulist"))
+		if err != nil {
+			continue
+		}
+
+		cpus, ok := parseCPUList(strings.TrimSpace(string(data)))
+		if !ok {
+			continue
+		}
+
+		nodes = append(nodes, NUMANode{ID: id, CPUs: cpus})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+// parseCPUList returns the CPUs of a list such as 0-3,8-11.
+func parseCPUList(list string) ([]int, bool) {
+	var cpus []int
+	if list == "" {
+		return cpus, true
+	}
+
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, false
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil || last < first {
+				return nil, false
+			}
+		}
+
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	return cpus, true
+}
+
+// readPerCPUTimes returns the busy and total clock ticks of each CPU from
+// /proc/stat.
+func readPerCPUTimes() (map[int]cpuTimes, bool) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	times := make(map[int]cpuTimes)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") || fields[0] == "cpu" {
+			continue
+		}
+
+		cpu, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu"))
+		if err != nil {
+			continue
+		}
+
+		if busy, total, ok := parseCPUTimes(fields[1:]); ok {
+			times[cpu] = cpuTimes{busy: busy, total: total}
+		}
+	}
+
+	return times, true
+}
+
+// bindToCPUs locks the calling goroutine to its thread and restricts the
+// thread to cpus. The returned function restores the thread's CPUs and
+// unlocks the goroutine. If the thread can't be restricted, the goroutine
+// is left unlocked.
+func bindToCPUs(cpus []int) func() {
+	runtime.LockOSThread()
+
+	var previous cpuSet
+	if schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &previous) != nil {
+		runtime.UnlockOSThread()
+		return func() {}
+	}
+
+	var set cpuSet
+	for _, cpu := range cpus {
+		if cpu >= 0 && cpu < cpuSetWords*64 {
+			set[cpu/64] |= 1 << uint(cpu%64)
+		}
+	}
+
+	if schedAffinity(syscall.SYS_SCHED_SETAFFINITY, &set) != nil {
+		runtime.UnlockOSThread()
+		return func() {}
+	}
+
+	return func() {
+		if schedAffinity(syscall.SYS_SCHED_SETAFFINITY, &previous) != nil {
+			// Leave the goroutine locked so that the runtime discards the
+			// restricted thread when the goroutine exits.
+			return
+		}
+		runtime.UnlockOSThread()
+	}
+}
+
+// schedAffinity gets or sets the CPUs of the calling thread with the
+// sched_getaffinity or sched_setaffinity system call.
+func schedAffinity(trap uintptr, set *cpuSet) error {
+	_, _, errno := syscall.RawSyscall(trap, 0, unsafe.Sizeof(*set), uintptr(unsafe.Pointer(set)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package parallel
+
+import (
+	"reflect"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestParseCPUList(t *testing.T) {
+	cpus, ok := parseCPUList("0-3,8,10-11")
+	if !ok || !reflect.DeepEqual(cpus, []int{0, 1, 2, 3, 8, 10, 11}) {
+		t.Errorf("CPUs, %v, should be 0-3, 8 and 10-11.", cpus)
+	}
+
+	if cpus, ok = parseCPUList(""); !ok || len(cpus) != 0 {
+		t.Errorf("An empty list, %v, should have no CPUs.", cpus)
+	}
+
+	if _, ok = parseCPUList("3-1"); ok {
+		t.Errorf("A descending range shouldn't be parsed.")
+	}
+}
+
+func TestBindToCPUs(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var before cpuSet
+	if err := schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &before); err != nil {
+		t.Skipf("The thread's CPUs can't be read: %v", err)
+	}
+
+	restore := bindToCPUs([]int{0})
+	var bound cpuSet
+	schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &bound)
+	restore()
+
+	if before[0]&1 == 1 && bound != (cpuSet{1}) {
+		t.Errorf("The thread's CPUs, %x, should only contain CPU 0.", bound[0])
+	}
+
+	var after cpuSet
+	schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &after)
+	if after != before {
+		t.Errorf("The thread's CPUs, %x, should be restored to %x.", after[0], before[0])
+	}
+}
+
+func TestFixedProcessCPUAffinity(t *testing.T) {
+	runtime.LockOSThread()
+	var allowed cpuSet
+	err := schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &allowed)
+	runtime.UnlockOSThread()
+	if err != nil {
+		t.Skipf("The thread's CPUs can't be read: %v", err)
+	}
+	if allowed[0]&1 == 0 {
+		t.Skip("CPU 0 isn't available to the process.")
+	}
+
+	p := NewFixedProcess(2)
+	p.SetCPUAffinity([]int{0})
+
+	sets := make([]cpuSet, 2)
+	p.Execute(2, func(i int) {
+		schedAffinity(syscall.SYS_SCHED_GETAFFINITY, &sets[i])
+	})
+
+	for i, set := range sets {
+		if set != (cpuSet{1}) {
+			t.Errorf("Iteration %d ran on CPUs %x instead of CPU 0.", i, set[0])
+		}
+	}
+}
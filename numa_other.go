@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package parallel
+
+// MARK: Private functions
+
+// readNUMANodes returns nil, since NUMA nodes are only read on Linux.
+func readNUMANodes() []NUMANode {
+	return nil
+}
+
+// readPerCPUTimes reports that the CPUs' times can't be read.
+func readPerCPUTimes() (map[int]cpuTimes, bool) {
+	return nil, false
+}
+
+// bindToCPUs does nothing, since routines are only bound to CPUs on Linux.
+func bindToCPUs(cpus []int) func() {
+	return func() {}
+}
@@ -0,0 +1,54 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestNUMAReporterUsage(t *testing.T) {
+	r := NewNUMAReporter()
+
+	end := time.Now().Add(20 * time.Millisecond)
+	for time.Now().Before(end) {
+	}
+
+	usage := r.Usage()
+	if len(usage) != len(r.Nodes()) {
+		t.Fatalf("Usage, %v, should have a value for each of the %d nodes.", usage, len(r.Nodes()))
+	}
+
+	for i, node := range r.Nodes() {
+		if usage[i] < 0.0 || usage[i] > float64(len(node.CPUs))+1e-9 {
+			t.Errorf("Usage of node %d, %f, should be between 0 and its %d CPUs.", node.ID, usage[i], len(node.CPUs))
+		}
+	}
+}
+
+func TestVariableProcessNUMANode(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 2, 4, c, false)
+	if n := p.NUMANode(); n != -1 {
+		t.Errorf("NUMA node, %d, should be -1 by default.", n)
+	}
+
+	node := 1 << 20
+	if nodes := NUMANodes(); len(nodes) > 0 {
+		node = nodes[0].ID
+	}
+	p.SetNUMANode(node)
+
+	v := make([]int, 100)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package parallel
+
+import "fmt"
+
+// Optimizer types determine how a variable process chooses its number of
+// routines at each optimization.
+type Optimizer int
+
+const (
+	// OptimizerPID routines are controlled by a PID controller driving the
+	// process' CPU usage towards full utilization. The controller is
+	// configured by the process' controller configuration.
+	OptimizerPID Optimizer = iota
+
+	// OptimizerAIMD routines are increased additively and decreased
+	// multiplicatively based on throughput. One routine is added at each
+	// optimization while the number of iterations executed per second
+	// improves, and the routines are halved when it regresses. The controller
+	// configuration's coefficients are ignored.
+	OptimizerAIMD
+
+	// OptimizerThroughput routines are controlled by a PID controller driving
+	// the number of iterations executed per second towards the controller
+	// configuration's throughput target, rather than saturating the CPUs. It
+	// suits workloads that must sustain a processing rate, such as real-time
+	// audio frames.
+	OptimizerThroughput
+
+	// OptimizerLatency routines are removed when the 95th percentile of
+	// operation latencies rises above the controller configuration's latency
+	// target, and added when it is comfortably below. It suits operations that
+	// contend on a shared resource, such as a disk or database, where more
+	// routines make each operation slower.
+	OptimizerLatency
+
+	// OptimizerEnergy routines are chosen to minimize the energy used per
+	// iteration rather than to maximize CPU usage, which suits battery
+	// powered and cost sensitive deployments. The routines are moved one at a
+	// time in one direction, turning around when the energy per iteration
+	// regresses. The energy is read from the RAPL counters of Linux's
+	// powercap interface, which usually needs root privileges. If they can't
+	// be read, the number of routines isn't changed.
+	OptimizerEnergy
+)
+
+// String returns the name of the optimizer.
+func (o Optimizer) String() string {
+	switch o {
+	case OptimizerPID:
+		return "pid"
+	case OptimizerAIMD:
+		return "aimd"
+	case OptimizerThroughput:
+		return "throughput"
+	case OptimizerLatency:
+		return "latency"
+	case OptimizerEnergy:
+		return "energy"
+	default:
+		return fmt.Sprintf("Optimizer(%d)", int(o))
+	}
+}
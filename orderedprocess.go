@@ -0,0 +1,198 @@
+package parallel
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ValueOperation types represent a single operation that produces a value.
+// Responders should perform the i-th operation and return its value.
+type ValueOperation func(i int) interface{}
+
+// ValueConsumer types receive the value produced by the i-th operation.
+type ValueConsumer func(i int, value interface{})
+
+// OrderedProcess types execute operations on another process and deliver their
+// values to a consumer strictly in index order, even though the operations
+// finish out of order.
+//
+// Values that finish early are held in a reordering buffer. To bound the
+// buffer, an operation doesn't begin until every operation more than window
+// indices before it has been consumed.
+type OrderedProcess struct {
+	// The process used to execute operations.
+	process Process
+
+	// The maximum number of values held in the reordering buffer.
+	window int
+
+	// A mutex to protect the buffer and the process' state.
+	mutex sync.Mutex
+
+	// A condition signaled when a value is consumed or the process stops.
+	cond *sync.Cond
+
+	// The values that have finished but can't be consumed yet, keyed by index.
+	buffer map[int]interface{}
+
+	// The index of the next value to consume.
+	next int
+
+	// Whether or not a routine is consuming values, and the values it took
+	// from the buffer to consume.
+	consuming bool
+	ready     []interface{}
+
+	// Set to 1 once the process has been stopped. Written while holding the
+	// mutex so that waiting routines are woken.
+	stopped int32
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// MARK: Initializers
+
+// NewOrderedProcess creates and returns a new ordered process that executes
+// operations on the given process with a reordering buffer of window values.
+func NewOrderedProcess(process Process, window int) *OrderedProcess {
+	if process == nil {
+		misuse("NewOrderedProcess called with a nil process")
+	}
+	checkRoutines("NewOrderedProcess", "window", window)
+
+	p := &OrderedProcess{
+		process:    process,
+		window:     window,
+		throughput: newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+	p.cond = sync.NewCond(&p.mutex)
+	return p
+}
+
+// MARK: Public methods
+
+// ExecuteOrdered executes the operation for the specified number of iterations
+// and passes each value to consume in index order. The consumer is never
+// called concurrently.
+func (p *OrderedProcess) ExecuteOrdered(iterations int, operation ValueOperation, consume ValueConsumer) {
+	checkIterations("OrderedProcess.ExecuteOrdered", iterations)
+	checkOperation("OrderedProcess.ExecuteOrdered", operation == nil || consume == nil)
+
+	p.mutex.Lock()
+	p.buffer = make(map[int]interface{}, p.window)
+	p.next = 0
+	p.consuming = false
+	atomic.StoreInt32(&p.stopped, 0)
+	p.mutex.Unlock()
+
+	p.process.Execute(iterations, func(i int) {
+		if !p.wait(i) {
+			return
+		}
+
+		value := operation(i)
+
+		p.mutex.Lock()
+		if atomic.LoadInt32(&p.stopped) == 1 {
+			p.mutex.Unlock()
+			return
+		}
+
+		p.buffer[i] = value
+		if p.consuming {
+			// The routine consuming values takes this one once it is ready.
+			p.mutex.Unlock()
+			return
+		}
+
+		p.consuming = true
+		p.mutex.Unlock()
+		p.consumeReady(consume)
+	})
+}
+
+// Stop stops the ordered process after all of the current operations have
+// finished executing. Values that haven't been consumed are discarded.
+func (p *OrderedProcess) Stop() {
+	p.mutex.Lock()
+	atomic.StoreInt32(&p.stopped, 1)
+	p.cond.Broadcast()
+	p.mutex.Unlock()
+
+	p.process.Stop()
+}
+
+// NumRoutines returns the number of routines that the underlying process is
+// using.
+func (p *OrderedProcess) NumRoutines() int {
+	return p.process.NumRoutines()
+}
+
+// Window returns the maximum number of values held in the reordering buffer.
+func (p *OrderedProcess) Window() int {
+	return p.window
+}
+
+// Throughput returns the meter measuring the number of values the process
+// consumes per second.
+func (p *OrderedProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private methods
+
+// consumeReady consumes the values that are ready until the next value hasn't
+// finished. The values are taken from the buffer under the mutex and consumed
+// without holding it, so other routines can buffer values and the consumer can
+// call the process' methods. Only the routine that set consuming calls it.
+func (p *OrderedProcess) consumeReady(consume ValueConsumer) {
+	for {
+		p.mutex.Lock()
+		start := p.next
+		p.ready = p.ready[:0]
+		for {
+			value, ok := p.buffer[start+len(p.ready)]
+			if !ok {
+				break
+			}
+
+			delete(p.buffer, start+len(p.ready))
+			p.ready = append(p.ready, value)
+		}
+
+		if len(p.ready) == 0 || atomic.LoadInt32(&p.stopped) == 1 {
+			p.consuming = false
+			p.mutex.Unlock()
+			return
+		}
+		p.mutex.Unlock()
+
+		for n, value := range p.ready {
+			if atomic.LoadInt32(&p.stopped) == 1 {
+				break
+			}
+
+			consume(start+n, value)
+			p.throughput.Add(1)
+		}
+
+		p.mutex.Lock()
+		p.next += len(p.ready)
+		p.cond.Broadcast()
+		p.mutex.Unlock()
+	}
+}
+
+// wait blocks until the i-th operation is within the window of the next value
+// to consume. It returns false if the process was stopped.
+func (p *OrderedProcess) wait(i int) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i >= p.next+p.window && atomic.LoadInt32(&p.stopped) == 0 {
+		p.cond.Wait()
+	}
+
+	return atomic.LoadInt32(&p.stopped) == 0
+}
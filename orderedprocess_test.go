@@ -0,0 +1,101 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestOrderedProcessOrder(t *testing.T) {
+	var order []int
+
+	p := NewOrderedProcess(NewFixedProcess(4), 8)
+	p.ExecuteOrdered(1000, func(i int) interface{} {
+		if i%7 == 0 {
+			time.Sleep(100 * time.Microsecond)
+		}
+		return i * 2
+	}, func(i int, value interface{}) {
+		if value.(int) != i*2 {
+			t.Errorf("Value, %v, should be %d.", value, i*2)
+		}
+		order = append(order, i)
+	})
+
+	if len(order) != 1000 {
+		t.Fatalf("Consumed value count, %d, should be 1000.", len(order))
+	}
+
+	for i, value := range order {
+		if value != i {
+			t.Errorf("Value %d was consumed at position %d.", value, i)
+			break
+		}
+	}
+}
+
+func TestOrderedProcessStatic(t *testing.T) {
+	f := NewFixedProcess(4)
+	f.SetSchedule(ScheduleStatic)
+
+	count := 0
+	p := NewOrderedProcess(f, 2)
+	p.ExecuteOrdered(100, func(i int) interface{} {
+		return i
+	}, func(i int, value interface{}) {
+		count++
+	})
+
+	if count != 100 {
+		t.Errorf("Consumed value count, %d, should be 100.", count)
+	}
+}
+
+func TestStopOrderedProcess(t *testing.T) {
+	f := NewFixedProcess(4)
+	f.SetSchedule(ScheduleStatic)
+
+	count := 0
+	p := NewOrderedProcess(f, 2)
+	p.ExecuteOrdered(1000, func(i int) interface{} {
+		if i == 10 {
+			p.Stop()
+		}
+		return i
+	}, func(i int, value interface{}) {
+		count++
+	})
+
+	if count > 10 {
+		t.Errorf("Consumed value count, %d, should not exceed 10.", count)
+	}
+}
+
+func TestStopOrderedProcessFromConsumer(t *testing.T) {
+	count := 0
+	finished := make(chan struct{})
+	p := NewOrderedProcess(NewFixedProcess(4), 8)
+
+	go func() {
+		defer close(finished)
+		p.ExecuteOrdered(1000, func(i int) interface{} {
+			return i
+		}, func(i int, value interface{}) {
+			count++
+			if i == 10 {
+				p.Stop()
+			}
+		})
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Stopping the process from the consumer should not deadlock.")
+	}
+
+	if count > 11 {
+		t.Errorf("Consumed value count, %d, should not exceed 11.", count)
+	}
+}
@@ -0,0 +1,10 @@
+package parallel
+
+// The size of the cache lines padded fields are separated by. Most CPUs use 64
+// byte lines.
+const cacheLineSize = 64
+
+// cacheLinePad types separate a frequently mutated field from its neighbours,
+// so that routines updating it don't invalidate the cache line holding fields
+// that other routines are reading or writing.
+type cacheLinePad [cacheLineSize]byte
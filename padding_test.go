@@ -0,0 +1,39 @@
+package parallel
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// MARK: Tests
+
+func TestVariableExecutionPadding(t *testing.T) {
+	var x variableExecution
+	offsets := []uintptr{
+		unsafe.Offsetof(x.iteration),
+		unsafe.Offsetof(x.numRoutines),
+		unsafe.Offsetof(x.numToRemove),
+		unsafe.Offsetof(x.startedRoutines),
+	}
+
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i]-offsets[i-1] < cacheLineSize {
+			t.Errorf("Fields %d and %d are %d bytes apart and may share a cache line.", i-1, i, offsets[i]-offsets[i-1])
+		}
+	}
+}
+
+func TestSchedulerPadding(t *testing.T) {
+	var d dynamicScheduler
+	if unsafe.Offsetof(d.iterations)-unsafe.Offsetof(d.iteration) < cacheLineSize {
+		t.Error("The dynamic scheduler's counter may share a cache line with its fields.")
+	}
+
+	var a adaptiveScheduler
+	if unsafe.Offsetof(a.iterations)-unsafe.Offsetof(a.iteration) < cacheLineSize {
+		t.Error("The adaptive scheduler's counter may share a cache line with its fields.")
+	}
+	if unsafe.Sizeof(adaptiveRoutine{}) < cacheLineSize {
+		t.Errorf("Adaptive routines, %d bytes, may share a cache line.", unsafe.Sizeof(adaptiveRoutine{}))
+	}
+}
@@ -0,0 +1,124 @@
+package parallel
+
+import (
+	"context"
+	"sort"
+)
+
+// PriorityFunction types return the priority of the i-th operation. Operations
+// with higher priorities are started first.
+type PriorityFunction func(i int) int
+
+// PriorityProcess types execute operations on another process in order of
+// their priority instead of their index.
+//
+// Operations with equal priorities are started in index order. Because
+// routines claim operations concurrently, operations are started in priority
+// order but may finish in any order.
+type PriorityProcess struct {
+	// The process used to execute operations.
+	process Process
+
+	// The function returning the priority of each operation.
+	priority PriorityFunction
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+}
+
+// MARK: Initializers
+
+// NewPriorityProcess creates and returns a new priority process that executes
+// operations on the given process in the order given by priority.
+func NewPriorityProcess(process Process, priority PriorityFunction) *PriorityProcess {
+	if process == nil {
+		misuse("NewPriorityProcess called with a nil process")
+	}
+
+	if priority == nil {
+		misuse("NewPriorityProcess called with a nil priority function")
+	}
+
+	return &PriorityProcess{
+		process:    process,
+		priority:   priority,
+		throughput: newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+	}
+}
+
+// MARK: Public methods
+
+// Execute executes the priority process for the specified number of
+// operations. The priority of every operation is evaluated once before any
+// operation begins.
+func (p *PriorityProcess) Execute(iterations int, operation Operation) {
+	checkIterations("PriorityProcess.Execute", iterations)
+	checkOperation("PriorityProcess.Execute", operation == nil)
+
+	order := p.order(iterations)
+	p.process.Execute(len(order), func(i int) {
+		operation(order[i])
+		p.throughput.Add(1)
+	})
+}
+
+// ExecuteContext executes the priority process for the specified number of
+// operations, passing ctx to each operation. The process stops when ctx is done
+// and the context's error is returned.
+func (p *PriorityProcess) ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error {
+	checkOperation("PriorityProcess.ExecuteContext", operation == nil)
+	return executeContext(ctx, p, iterations, operation)
+}
+
+// Stop stops the priority process after all of the current operations have
+// finished executing.
+func (p *PriorityProcess) Stop() {
+	p.process.Stop()
+}
+
+// NumRoutines returns the number of routines that the underlying process is
+// using.
+func (p *PriorityProcess) NumRoutines() int {
+	return p.process.NumRoutines()
+}
+
+// Stats returns a snapshot of the underlying process' current or last
+// execution.
+func (p *PriorityProcess) Stats() ProcessStats {
+	return p.process.Stats()
+}
+
+// Throughput returns the meter measuring the number of operations the process
+// completes per second.
+func (p *PriorityProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// MARK: Private methods
+
+// wasStopped returns whether or not the underlying process' last execution was
+// stopped.
+func (p *PriorityProcess) wasStopped() bool {
+	return processStopped(p.process)
+}
+
+// order returns the indices of the specified number of operations sorted by
+// descending priority.
+func (p *PriorityProcess) order(iterations int) []int {
+	if iterations <= 0 {
+		return nil
+	}
+
+	order := make([]int, iterations)
+	priorities := make([]int, iterations)
+	for i := range order {
+		order[i] = i
+		priorities[i] = p.priority(i)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return priorities[order[i]] > priorities[order[j]]
+	})
+
+	return order
+}
@@ -0,0 +1,42 @@
+package parallel
+
+import "testing"
+
+// MARK: Tests
+
+func TestPriorityProcessOrder(t *testing.T) {
+	var order []int
+	p := NewPriorityProcess(NewFixedProcess(1), func(i int) int {
+		return i % 3
+	})
+
+	p.Execute(9, func(i int) {
+		order = append(order, i)
+	})
+
+	expected := []int{2, 5, 8, 1, 4, 7, 0, 3, 6}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Order, %v, should be %v.", order, expected)
+			break
+		}
+	}
+}
+
+func TestPriorityProcessCompleteness(t *testing.T) {
+	v := make([]float64, 100000)
+	p := NewPriorityProcess(NewFixedProcess(4), func(i int) int {
+		return -i
+	})
+
+	p.Execute(len(v), func(i int) {
+		v[i] = float64(i + 1)
+	})
+
+	for i, value := range v {
+		if float64(i+1) != value {
+			t.Errorf("Value, %f, should be equal to %f.", value, float64(i+1))
+			break
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// probePublisherBufferLength is the number of samples a probe publisher
+// buffers before it drops samples.
+const probePublisherBufferLength = 256
+
+// probePublisher types publish controller samples to a variable process'
+// metrics sinks on a dedicated goroutine, so that slow sinks never delay the
+// optimization that produced the samples. Samples are dropped when the
+// publisher's buffer is full.
+type probePublisher struct {
+	// The buffered samples waiting to be published.
+	samples chan ControllerSample
+
+	// Closed when every buffered sample has been published.
+	done chan struct{}
+
+	// The number of samples that were dropped.
+	dropped int64
+
+	// The time in nanoseconds the optimizer spent handing samples to the
+	// publisher.
+	overhead int64
+}
+
+// MARK: Initializers
+
+// newProbePublisher creates and returns a new probe publisher and starts
+// publishing to the specified sinks.
+func newProbePublisher(sinks ...MetricsSink) *probePublisher {
+	p := &probePublisher{
+		samples: make(chan ControllerSample, probePublisherBufferLength),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+		for sample := range p.samples {
+			for _, sink := range sinks {
+				sink.RecordSample(sample)
+			}
+		}
+	}()
+
+	return p
+}
+
+// MARK: Private methods
+
+// publish hands sample to the publisher without blocking.
+func (p *probePublisher) publish(sample ControllerSample) {
+	start := time.Now()
+	select {
+	case p.samples <- sample:
+	default:
+		atomic.AddInt64(&p.dropped, 1)
+	}
+	atomic.AddInt64(&p.overhead, int64(time.Since(start)))
+}
+
+// close publishes the buffered samples and stops the publisher.
+func (p *probePublisher) close() {
+	close(p.samples)
+	<-p.done
+}
+
+// stats returns the number of dropped samples and the time the optimizer
+// spent handing samples to the publisher.
+func (p *probePublisher) stats() (int64, time.Duration) {
+	return atomic.LoadInt64(&p.dropped), time.Duration(atomic.LoadInt64(&p.overhead))
+}
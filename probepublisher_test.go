@@ -0,0 +1,79 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestProbePublisherDrops(t *testing.T) {
+	sink := &blockingSink{samples: make(chan ControllerSample)}
+	p := newProbePublisher(sink)
+
+	// Nothing reads from the sink until the samples are drained below.
+	for i := 0; i < 300; i++ {
+		p.publish(ControllerSample{Usage: float64(i)})
+	}
+
+	dropped, overhead := p.stats()
+	if dropped < 300-probePublisherBufferLength-1 || dropped > 300-probePublisherBufferLength {
+		t.Errorf("Dropped samples, %d, should be about %d.", dropped, 300-probePublisherBufferLength)
+	}
+
+	if overhead <= 0 || overhead > time.Second {
+		t.Errorf("Overhead, %s, should be small and positive.", overhead)
+	}
+
+	published := 0
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case <-sink.samples:
+				published++
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	p.close()
+	<-drained
+
+	if int64(published)+dropped != 300 {
+		t.Errorf("Published, %d, and dropped, %d, samples should total 300.", published, dropped)
+	}
+}
+
+func TestVariableProcessProbeStats(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, true)
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	s := p.Summary()
+	if s.Stats.ProbeSamplesDropped != 0 {
+		t.Errorf("Dropped samples, %d, should be 0.", s.Stats.ProbeSamplesDropped)
+	}
+
+	if len(s.ControllerHistory) > 0 && s.Stats.ProbeOverhead <= 0 {
+		t.Errorf("Probe overhead, %s, should be measured.", s.Stats.ProbeOverhead)
+	}
+}
+
+// MARK: Helpers
+
+// blockingSink types hand each sample to a channel, blocking until it is
+// received.
+type blockingSink struct {
+	samples chan ControllerSample
+}
+
+func (s *blockingSink) RecordSample(sample ControllerSample) {
+	s.samples <- sample
+}
+
+func (s *blockingSink) Flush() {}
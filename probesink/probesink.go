@@ -0,0 +1,101 @@
+// Package probesink records the controller signals of a parallel variable
+// process with the probes of github.com/colinc86/probes.
+package probesink
+
+import (
+	"math"
+
+	"github.com/colinc86/parallel"
+	"github.com/colinc86/probes"
+)
+
+// Sink types adapt four probes to a parallel.MetricsSink. Give a sink to a
+// variable process with SetMetricsSink, and read the probes' signals after the
+// process executes.
+type Sink struct {
+	// The CPU probe.
+	CPUProbe *probes.Probe
+
+	// The error probe.
+	ErrorProbe *probes.Probe
+
+	// The PID output probe.
+	PIDProbe *probes.Probe
+
+	// The routine probe.
+	RoutineProbe *probes.Probe
+
+	// The number of values each probe's signal keeps.
+	maximumSignalLength int
+
+	// Whether or not the probes have been activated since the sink was
+	// created or last flushed.
+	active bool
+}
+
+// MARK: Initializers
+
+// New creates and returns a new sink whose probes keep the last
+// maximumSignalLength values of their signals, or every value if it is zero.
+func New(maximumSignalLength int) *Sink {
+	if maximumSignalLength <= 0 {
+		maximumSignalLength = math.MaxInt32
+	}
+
+	return &Sink{
+		CPUProbe:            probes.NewProbe(),
+		ErrorProbe:          probes.NewProbe(),
+		PIDProbe:            probes.NewProbe(),
+		RoutineProbe:        probes.NewProbe(),
+		maximumSignalLength: maximumSignalLength,
+	}
+}
+
+// MARK: Public methods
+
+// RecordSample sends the sample's signals to their probes. The first sample
+// after the sink is created or flushed clears the probes' signals and
+// activates them, so the signals hold a single run of executions.
+func (s *Sink) RecordSample(sample parallel.ControllerSample) {
+	if !s.active {
+		s.activate()
+	}
+
+	s.CPUProbe.C <- sample.Usage
+	s.PIDProbe.C <- sample.Output
+	s.ErrorProbe.C <- sample.Error
+	s.RoutineProbe.C <- sample.Routines
+}
+
+// Flush flushes and deactivates the probes.
+func (s *Sink) Flush() {
+	if !s.active {
+		return
+	}
+
+	for _, probe := range s.probes() {
+		probe.Flush()
+		probe.Deactivate()
+	}
+	s.active = false
+}
+
+// MARK: Private methods
+
+// activate clears the probes, sets the number of values their signals keep and
+// activates them. The length is only set once a probe's signal is cleared,
+// since the goroutine of a probe deactivated by the last execution reads it
+// while holding the probe's signal mutex.
+func (s *Sink) activate() {
+	for _, probe := range s.probes() {
+		probe.ClearSignal()
+		probe.MaximumSignalLength = s.maximumSignalLength
+		probe.Activate()
+	}
+	s.active = true
+}
+
+// probes returns the sink's probes.
+func (s *Sink) probes() []*probes.Probe {
+	return []*probes.Probe{s.CPUProbe, s.ErrorProbe, s.PIDProbe, s.RoutineProbe}
+}
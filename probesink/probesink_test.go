@@ -0,0 +1,77 @@
+package probesink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/colinc86/parallel"
+)
+
+// MARK: Tests
+
+func TestSinkSignals(t *testing.T) {
+	s := New(3)
+	for i := 0; i < 5; i++ {
+		s.RecordSample(parallel.ControllerSample{
+			Usage:    float64(i),
+			Error:    float64(-i),
+			Output:   float64(2 * i),
+			Routines: float64(i + 1),
+		})
+	}
+	s.Flush()
+
+	expected := map[string][]float64{
+		"CPU":     {2, 3, 4},
+		"Error":   {-2, -3, -4},
+		"PID":     {4, 6, 8},
+		"Routine": {3, 4, 5},
+	}
+	signals := map[string][]float64{
+		"CPU":     s.CPUProbe.Signal(),
+		"Error":   s.ErrorProbe.Signal(),
+		"PID":     s.PIDProbe.Signal(),
+		"Routine": s.RoutineProbe.Signal(),
+	}
+	for name, signal := range signals {
+		if len(signal) != len(expected[name]) {
+			t.Errorf("%s signal, %v, should be %v.", name, signal, expected[name])
+			continue
+		}
+		for i := range signal {
+			if signal[i] != expected[name][i] {
+				t.Errorf("%s signal, %v, should be %v.", name, signal, expected[name])
+				break
+			}
+		}
+	}
+
+	s.RecordSample(parallel.ControllerSample{Usage: 9})
+	s.Flush()
+	if signal := s.CPUProbe.Signal(); len(signal) != 1 || signal[0] != 9 {
+		t.Errorf("CPU signal, %v, should be cleared by the next run.", signal)
+	}
+}
+
+func TestVariableProcessSink(t *testing.T) {
+	c := parallel.NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := parallel.NewVariableProcess(time.Millisecond, 1, 4, c, true)
+	s := New(0)
+	p.SetMetricsSink(s)
+
+	p.Execute(200, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	history := p.ControllerHistory()
+	routines := s.RoutineProbe.Signal()
+	if len(routines) == 0 || len(routines) != len(history) {
+		t.Fatalf("Routine signal length, %d, should match the controller history's, %d.", len(routines), len(history))
+	}
+	for i, sample := range history {
+		if routines[i] != sample.Routines {
+			t.Errorf("Routine signal, %v, should match the controller history.", routines)
+			break
+		}
+	}
+}
@@ -2,6 +2,8 @@
 // a fixed or variable number of goroutines.
 package parallel
 
+import "context"
+
 // Operation types represent a single operation in a parallel process.
 // Responders should perform the i-th operation.
 type Operation func(i int)
@@ -14,10 +16,23 @@ type Process interface {
 	// using the provided operation function.
 	Execute(iterations int, operation Operation)
 
+	// ExecuteContext executes a parallel process for the given number of
+	// iterations, passing ctx to every call to the operation function. The
+	// process stops when ctx is done and the context's error is returned. If
+	// ctx has a deadline, iterations that can't start before it are skipped.
+	ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error
+
 	// Stop stops the process if it is currently executing.
 	Stop()
 
 	// NumRoutines returns the number of routines that are currently executing in
 	// the parallel process.
 	NumRoutines() int
+
+	// Stats returns a snapshot of the process' current or last execution.
+	Stats() ProcessStats
 }
+
+// Task types represent a single unit of work that isn't identified by an
+// iteration index.
+type Task func()
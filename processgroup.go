@@ -0,0 +1,174 @@
+package parallel
+
+import "sync"
+
+// ProcessGroup types own a goroutine budget shared by every process in the
+// group, so that several processes executing at the same time don't each scale
+// up to the number of CPUs and oversubscribe the machine.
+//
+// Every routine a member process runs holds one unit of the budget until it
+// exits. A member that starts executing while the budget is exhausted is
+// still granted a single routine so that it can make progress, so the number
+// of allocated routines can briefly exceed the budget by one per member.
+//
+// The budget is partitioned fairly between the members that are executing. No
+// member is granted more than its share of the budget, and variable processes
+// that hold more than their share when another member starts executing remove
+// routines until they are back within it.
+type ProcessGroup struct {
+	// A mutex to protect the group's budget.
+	mutex sync.Mutex
+
+	// The maximum number of routines shared by the group's processes.
+	budget int
+
+	// The number of routines currently allocated to the group's processes.
+	allocated int
+
+	// The number of members that are currently executing.
+	members int
+}
+
+// groupMember types track the routines allocated to a single execution of a
+// group's member process.
+type groupMember struct {
+	// The number of routines allocated to the member.
+	allocated int
+}
+
+// MARK: Initializers
+
+// NewProcessGroup creates and returns a new process group with a budget of
+// the specified number of goroutines.
+func NewProcessGroup(budget int) *ProcessGroup {
+	checkRoutines("NewProcessGroup", "budget", budget)
+
+	return &ProcessGroup{
+		budget: budget,
+	}
+}
+
+// MARK: Public methods
+
+// Budget returns the maximum number of goroutines shared by the group's
+// processes.
+func (g *ProcessGroup) Budget() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.budget
+}
+
+// SetBudget sets the maximum number of goroutines shared by the group's
+// processes. Lowering the budget doesn't stop running routines, but no new
+// routines are granted until the allocation falls below the new budget.
+func (g *ProcessGroup) SetBudget(budget int) {
+	checkRoutines("ProcessGroup.SetBudget", "budget", budget)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.budget = budget
+}
+
+// Allocated returns the number of goroutines currently allocated to the
+// group's processes.
+func (g *ProcessGroup) Allocated() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.allocated
+}
+
+// Members returns the number of the group's processes that are currently
+// executing.
+func (g *ProcessGroup) Members() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.members
+}
+
+// MARK: Private methods
+
+// join adds an executing member to the group and returns it.
+func (g *ProcessGroup) join() *groupMember {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.members++
+	return &groupMember{}
+}
+
+// leave removes an executing member from the group and returns any routines
+// it still holds to the budget.
+func (g *ProcessGroup) leave(member *groupMember) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.members--
+	g.allocated -= member.allocated
+	member.allocated = 0
+}
+
+// share returns the maximum number of routines each executing member may hold.
+func (g *ProcessGroup) share() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.fairShare()
+}
+
+// acquire requests n routines from the budget for member and returns the
+// number granted, which is between zero and n.
+func (g *ProcessGroup) acquire(member *groupMember, n int) int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	n = g.available(member, n)
+	if n < 0 {
+		n = 0
+	}
+
+	g.allocated += n
+	member.allocated += n
+	return n
+}
+
+// acquireAtLeastOne requests n routines from the budget for member and returns
+// the number granted, which is between one and n.
+func (g *ProcessGroup) acquireAtLeastOne(member *groupMember, n int) int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	n = g.available(member, n)
+	if n < 1 {
+		n = 1
+	}
+
+	g.allocated += n
+	member.allocated += n
+	return n
+}
+
+// release returns n of member's routines to the budget.
+func (g *ProcessGroup) release(member *groupMember, n int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.allocated -= n
+	member.allocated -= n
+}
+
+// available limits n to the number of routines left in the budget and in
+// member's share of it. The group's mutex must be held.
+func (g *ProcessGroup) available(member *groupMember, n int) int {
+	if available := g.budget - g.allocated; n > available {
+		n = available
+	}
+	if available := g.fairShare() - member.allocated; n > available {
+		n = available
+	}
+	return n
+}
+
+// fairShare returns the number of routines each executing member is entitled
+// to. The group's mutex must be held.
+func (g *ProcessGroup) fairShare() int {
+	if g.members < 2 {
+		return g.budget
+	}
+	return (g.budget + g.members - 1) / g.members
+}
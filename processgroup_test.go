@@ -0,0 +1,106 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestProcessGroupAcquire(t *testing.T) {
+	g := NewProcessGroup(4)
+	m := g.join()
+
+	if n := g.acquire(m, 3); n != 3 {
+		t.Errorf("Granted routines, %d, should be 3.", n)
+	}
+
+	if n := g.acquire(m, 3); n != 1 {
+		t.Errorf("Granted routines, %d, should be 1.", n)
+	}
+
+	if n := g.acquire(m, 1); n != 0 {
+		t.Errorf("Granted routines, %d, should be 0.", n)
+	}
+
+	if n := g.acquireAtLeastOne(m, 2); n != 1 {
+		t.Errorf("Granted routines, %d, should be 1.", n)
+	}
+
+	g.release(m, 5)
+	g.leave(m)
+	if g.Allocated() != 0 || g.Members() != 0 {
+		t.Errorf("Allocated routines, %d, and members, %d, should be 0.", g.Allocated(), g.Members())
+	}
+}
+
+func TestProcessGroupFairShare(t *testing.T) {
+	g := NewProcessGroup(8)
+	m1 := g.join()
+
+	if n := g.acquire(m1, 8); n != 8 {
+		t.Errorf("Granted routines, %d, should be 8.", n)
+	}
+
+	m2 := g.join()
+	if s := g.share(); s != 4 {
+		t.Errorf("Share, %d, should be 4.", s)
+	}
+
+	g.release(m1, 4)
+	if n := g.acquire(m1, 4); n != 0 {
+		t.Errorf("Granted routines, %d, should be 0 once the share is held.", n)
+	}
+
+	if n := g.acquire(m2, 8); n != 4 {
+		t.Errorf("Granted routines, %d, should be 4.", n)
+	}
+}
+
+func TestProcessGroupSharedBudget(t *testing.T) {
+	g := NewProcessGroup(4)
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+
+	var mutex sync.Mutex
+	var active, maxActive int
+	operation := func(i int) {
+		mutex.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Microsecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		p := NewVariableProcess(time.Millisecond, 8, 20, c, false)
+		p.SetProcessGroup(g)
+		go func() {
+			defer wg.Done()
+			p.Execute(10000, operation)
+		}()
+	}
+
+	f := NewFixedProcess(8)
+	f.SetProcessGroup(g)
+	f.Execute(10000, operation)
+	wg.Wait()
+
+	// Each member may be granted one routine over the budget.
+	if maxActive > g.Budget()+3 {
+		t.Errorf("Maximum active routines, %d, should not exceed %d.", maxActive, g.Budget()+3)
+	}
+
+	if g.Allocated() != 0 || g.Members() != 0 {
+		t.Errorf("Allocated routines, %d, and members, %d, should be 0.", g.Allocated(), g.Members())
+	}
+}
@@ -0,0 +1,104 @@
+package parallel
+
+import "sync/atomic"
+
+// Progress types describe how far an execution has progressed.
+type Progress struct {
+	// The total number of iterations in the execution.
+	Iterations int64
+
+	// The number of iterations routines have claimed.
+	Claimed int64
+
+	// The number of iterations whose operation has returned.
+	Completed int64
+}
+
+// The number of completed iterations a routine counts locally before
+// reconciling its counts with its process.
+const progressFlushInterval = 1024
+
+// progressCounter types accumulate the claimed and completed iteration counts
+// of an execution's routines. The counts are updated atomically and should be
+// allocated on their own so that they are 64-bit aligned.
+type progressCounter struct {
+	iterations int64
+	claimed    int64
+	completed  int64
+}
+
+// routineProgress types count a single routine's claimed and completed
+// iterations locally and periodically reconcile them with a progress counter,
+// keeping shared counter updates out of the per-iteration path.
+type routineProgress struct {
+	counter   *progressCounter
+	claimed   int64
+	completed int64
+}
+
+// MARK: Public methods
+
+// Discrepancy returns the number of claimed iterations that have not
+// completed. While an execution is running this includes in-flight iterations
+// and counts that routines have not yet reconciled. After an execution
+// finishes it is zero unless iterations were lost, for example because a
+// routine exited in the middle of an operation.
+func (p Progress) Discrepancy() int64 {
+	return p.Claimed - p.Completed
+}
+
+// Remaining returns the number of iterations that have not completed.
+func (p Progress) Remaining() int64 {
+	return p.Iterations - p.Completed
+}
+
+// MARK: Private methods
+
+// reset resets the counter for an execution with the specified number of
+// iterations.
+func (c *progressCounter) reset(iterations int) {
+	atomic.StoreInt64(&c.iterations, int64(iterations))
+	atomic.StoreInt64(&c.claimed, 0)
+	atomic.StoreInt64(&c.completed, 0)
+}
+
+// add adds the specified number of iterations to the counter's execution.
+func (c *progressCounter) add(iterations int) {
+	atomic.AddInt64(&c.iterations, int64(iterations))
+}
+
+// snapshot returns the counter's current progress.
+func (c *progressCounter) snapshot() Progress {
+	return Progress{
+		Iterations: atomic.LoadInt64(&c.iterations),
+		Claimed:    atomic.LoadInt64(&c.claimed),
+		Completed:  atomic.LoadInt64(&c.completed),
+	}
+}
+
+// claim records that n iterations were claimed.
+func (r *routineProgress) claim(n int) {
+	r.claimed += int64(n)
+}
+
+// complete records that an iteration completed, reconciling with the counter
+// every progressFlushInterval iterations.
+func (r *routineProgress) complete() {
+	r.completed++
+	if r.completed >= progressFlushInterval {
+		r.flush()
+	}
+}
+
+// flush reconciles the routine's local counts with the counter.
+func (r *routineProgress) flush() {
+	if r.claimed != 0 {
+		atomic.AddInt64(&r.counter.claimed, r.claimed)
+		r.claimed = 0
+	}
+
+	if r.completed != 0 {
+		atomic.AddInt64(&r.counter.completed, r.completed)
+		r.completed = 0
+	}
+}
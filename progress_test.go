@@ -0,0 +1,48 @@
+package parallel
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessProgress(t *testing.T) {
+	for _, schedule := range []Schedule{ScheduleDynamic, ScheduleWorkStealing, ScheduleGuided, ScheduleStatic, ScheduleAdaptive} {
+		p := NewFixedProcess(4)
+		p.ExecuteSchedule(100000, schedule, func(i int) {})
+
+		progress := p.Progress()
+		if progress.Claimed != 100000 || progress.Completed != 100000 || progress.Discrepancy() != 0 {
+			t.Errorf("Progress, %+v, should be complete with schedule %d.", progress, schedule)
+		}
+	}
+}
+
+func TestVariableProcessProgress(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.Execute(100000, func(i int) {})
+
+	progress := p.Progress()
+	if progress.Completed != 100000 || progress.Remaining() != 0 || progress.Discrepancy() != 0 {
+		t.Errorf("Progress, %+v, should be complete.", progress)
+	}
+}
+
+func TestProgressDiscrepancy(t *testing.T) {
+	p := NewFixedProcess(2)
+	p.ExecuteSchedule(1000, ScheduleStatic, func(i int) {
+		if i == 10 {
+			// Exit the routine in the middle of its block, losing the rest of
+			// its iterations.
+			runtime.Goexit()
+		}
+	})
+
+	progress := p.Progress()
+	if progress.Discrepancy() != 490 {
+		t.Errorf("Discrepancy, %d, should be 490.", progress.Discrepancy())
+	}
+}
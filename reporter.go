@@ -1,46 +1,198 @@
 package parallel
 
-//#include <time.h>
-import "C"
-import "time"
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReporterSource types determine how a variable process or CPU reporter
+// measures CPU usage.
+type ReporterSource int
+
+const (
+	// ReporterSourceClock measures the CPU time used by the program with the C
+	// library's clock function, or GetProcessTimes on Windows.
+	ReporterSourceClock ReporterSource = iota
+
+	// ReporterSourceRuntimeMetrics measures the CPU time the Go runtime spent
+	// on the program's goroutines, garbage collection and scavenging, as
+	// reported by runtime/metrics. It needs neither cgo nor OS-specific code.
+	// The runtime updates these metrics at the end of each garbage collection,
+	// so the usage changes once per cycle and holds its value in between.
+	// Before Go 1.20 the clock is used instead.
+	ReporterSourceRuntimeMetrics
+
+	// ReporterSourceSystem measures the CPU usage of the whole machine rather
+	// than the program, so the controller backs off when other processes on
+	// the host are busy. The machine's busy fraction is scaled by GOMAXPROCS.
+	// It is supported on Linux, where /proc/stat is read, and Windows. On
+	// other operating systems the clock is used instead.
+	ReporterSourceSystem
+)
+
+// CPUReporter types report the number of CPUs used between successive calls to
+// their Usage method. They measure usage the same way variable processes do,
+// so applications can base their own scaling decisions on the same signal.
+// CPU reporters are safe for concurrent use.
+type CPUReporter struct {
+	// A mutex to protect the reporter's measurements.
+	mutex sync.Mutex
+
+	// The reporter measuring usage.
+	reporter usageReporter
+
+	// The source the reporter measures usage from.
+	source ReporterSource
+}
+
+// usageReporter types report the number of CPUs used by the program between
+// successive calls to their usage method.
+type usageReporter interface {
+	usage() float64
+	reset()
+}
 
 // reporter types report the amount of CPU usage between the current and last
 // call to the usage method.
 type reporter struct {
+	clock    Clock
 	lastTime time.Time
-	lastTick C.clock_t
+	lastCPU  time.Duration
+}
+
+// usageSmoother types smooth a CPU usage signal with an exponential moving
+// average.
+type usageSmoother struct {
+	// The smoothed usage.
+	value float64
+
+	// Whether or not the smoother has received a sample since it was reset.
+	primed bool
 }
 
 // MARK: Initializers
 
-// newReporter creates and returns a new CPU reporter.
-func newReporter() *reporter {
+// NewCPUReporter creates and returns a new CPU reporter measuring usage from
+// source. Sources that aren't supported on the platform or Go version fall
+// back to ReporterSourceClock.
+func NewCPUReporter(source ReporterSource) *CPUReporter {
+	return &CPUReporter{
+		reporter: newUsageReporter(source, SystemClock{}),
+		source:   source,
+	}
+}
+
+// newUsageReporter creates and returns a new reporter measuring usage from
+// source. If source isn't supported, a clock reporter measuring elapsed time
+// with clock is returned.
+func newUsageReporter(source ReporterSource, clock Clock) usageReporter {
+	var r usageReporter
+	switch source {
+	case ReporterSourceRuntimeMetrics:
+		r = newMetricsReporter()
+	case ReporterSourceSystem:
+		r = newSystemReporter()
+	}
+
+	if r == nil {
+		return newReporter(clock)
+	}
+	return r
+}
+
+// newReporter creates and returns a new CPU reporter measuring elapsed time
+// with clock.
+func newReporter(clock Clock) *reporter {
 	return &reporter{
-		lastTime: time.Now(),
-		lastTick: C.clock(),
+		clock:    clock,
+		lastTime: clock.Now(),
+		lastCPU:  processCPUTime(),
 	}
 }
 
 // MARK: Public methods
 
+// Usage returns the number of CPUs used since the last call to Usage or
+// Reset, or since the reporter was created.
+func (r *CPUReporter) Usage() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.reporter.usage()
+}
+
+// Reset restarts the reporter's measurements from now.
+func (r *CPUReporter) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reporter.reset()
+}
+
+// Source returns the source the reporter was created with.
+func (r *CPUReporter) Source() ReporterSource {
+	return r.source
+}
+
 // usage returns the decimal percent of CPU usage used by the process. If this
 // is the first time to call this method, then the usage reported will be
 // calculated between this call and the last call to reset (or instantiation).
 func (r *reporter) usage() float64 {
-	nowClock := C.clock()
-	nowActual := time.Now()
+	nowCPU := processCPUTime()
+	nowActual := r.clock.Now()
 
-	clockSeconds := float64(nowClock-r.lastTick) / float64(C.CLOCKS_PER_SEC)
-	r.lastTick = nowClock
+	cpuSeconds := (nowCPU - r.lastCPU).Seconds()
+	r.lastCPU = nowCPU
 
 	actualSeconds := nowActual.Sub(r.lastTime).Seconds()
 	r.lastTime = nowActual
 
-	return clockSeconds / actualSeconds
+	if actualSeconds <= 0.0 {
+		return 0.0
+	}
+	return cpuSeconds / actualSeconds
 }
 
-// Reset resets the reporter's last time and tick.
+// Reset resets the reporter's last time and CPU time.
 func (r *reporter) reset() {
-	r.lastTime = time.Now()
-	r.lastTick = C.clock()
+	r.lastTime = r.clock.Now()
+	r.lastCPU = processCPUTime()
+}
+
+// next returns the smoothed usage after adding the usage sample. The previous
+// smoothed usage is given the weight smoothing, and the first sample after a
+// reset is returned unchanged.
+func (s *usageSmoother) next(usage float64, smoothing float64) float64 {
+	if !s.primed {
+		s.value = usage
+		s.primed = true
+		return usage
+	}
+
+	s.value = smoothing*s.value + (1.0-smoothing)*usage
+	return s.value
+}
+
+// reset discards the smoother's samples.
+func (s *usageSmoother) reset() {
+	s.value = 0.0
+	s.primed = false
+}
+
+// MARK: Private functions
+
+// medianUsage returns the median of the usage samples, or zero if there are
+// none.
+func medianUsage(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0.0
+	}
+
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2.0
 }
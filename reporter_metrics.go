@@ -0,0 +1,85 @@
+//go:build go1.20
+// +build go1.20
+
+package parallel
+
+import (
+	"runtime"
+	"runtime/metrics"
+)
+
+// The runtime metrics read by a metrics reporter.
+const (
+	metricsTotalCPU = "/cpu/classes/total:cpu-seconds"
+	metricsIdleCPU  = "/cpu/classes/idle:cpu-seconds"
+)
+
+// metricsReporter types report CPU usage from the runtime's CPU classes. The
+// total class is the CPU time available to the program's Ps, so the fraction
+// of it that wasn't idle, scaled by the number of Ps, is the number of CPUs
+// the program used.
+type metricsReporter struct {
+	samples []metrics.Sample
+
+	// The total and idle CPU seconds at the last measurement.
+	lastTotal float64
+	lastIdle  float64
+
+	// The usage of the last period in which the metrics changed.
+	lastUsage float64
+}
+
+// MARK: Initializers
+
+// newMetricsReporter creates and returns a new runtime metrics reporter.
+func newMetricsReporter() usageReporter {
+	r := &metricsReporter{
+		samples: []metrics.Sample{
+			{Name: metricsTotalCPU},
+			{Name: metricsIdleCPU},
+		},
+	}
+	r.reset()
+	return r
+}
+
+// MARK: Private methods
+
+// usage returns the number of CPUs the program used between the garbage
+// collections that ended before this and the last call to usage. If no
+// garbage collection has ended since, the last usage is returned again.
+func (r *metricsReporter) usage() float64 {
+	total, idle := r.read()
+	dt := total - r.lastTotal
+	if dt <= 0.0 {
+		return r.lastUsage
+	}
+
+	busy := dt - (idle - r.lastIdle)
+	r.lastTotal = total
+	r.lastIdle = idle
+
+	// The total class accrues GOMAXPROCS CPU seconds per second, so the busy
+	// fraction scaled by GOMAXPROCS is the number of CPUs in use.
+	r.lastUsage = busy / dt * float64(runtime.GOMAXPROCS(0))
+	return r.lastUsage
+}
+
+// reset restarts the reporter's measurements.
+func (r *metricsReporter) reset() {
+	r.lastTotal, r.lastIdle = r.read()
+	r.lastUsage = 0.0
+}
+
+// read returns the total and idle CPU seconds reported by the runtime.
+func (r *metricsReporter) read() (float64, float64) {
+	metrics.Read(r.samples)
+
+	var values [2]float64
+	for i, sample := range r.samples {
+		if sample.Value.Kind() == metrics.KindFloat64 {
+			values[i] = sample.Value.Float64()
+		}
+	}
+	return values[0], values[1]
+}
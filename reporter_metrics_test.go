@@ -0,0 +1,56 @@
+//go:build go1.20
+// +build go1.20
+
+package parallel
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestMetricsReporterUsage(t *testing.T) {
+	r := newMetricsReporter()
+	runtime.GC()
+	r.reset()
+
+	end := time.Now().Add(20 * time.Millisecond)
+	for time.Now().Before(end) {
+	}
+	runtime.GC()
+
+	u := r.usage()
+	if !(u > 0.0) || u > float64(runtime.GOMAXPROCS(0))+1e-9 {
+		t.Errorf("CPU usage, %f, should be positive and at most GOMAXPROCS.", u)
+	}
+
+	if held := r.usage(); held != u {
+		t.Errorf("CPU usage, %f, should hold at %f until the next garbage collection.", held, u)
+	}
+}
+
+func TestVariableProcessRuntimeMetricsSource(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetReporterSource(ReporterSourceRuntimeMetrics)
+
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		buffer := make([]byte, 64<<10)
+		buffer[i%len(buffer)] = 1
+		v[i] += int(buffer[i%len(buffer)])
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if p.ReporterSource() != ReporterSourceRuntimeMetrics {
+		t.Errorf("Reporter source, %d, should be the runtime metrics.", p.ReporterSource())
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !go1.20
+// +build !go1.20
+
+package parallel
+
+// MARK: Initializers
+
+// newMetricsReporter returns nil, since the runtime doesn't report CPU
+// classes before Go 1.20.
+func newMetricsReporter() usageReporter {
+	return nil
+}
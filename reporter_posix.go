@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package parallel
+
+//#include <time.h>
+import "C"
+import "time"
+
+// MARK: Private functions
+
+// processCPUTime returns the CPU time used by the process as measured by the C
+// library's clock function.
+func processCPUTime() time.Duration {
+	return time.Duration(float64(C.clock()) / float64(C.CLOCKS_PER_SEC) * float64(time.Second))
+}
@@ -0,0 +1,60 @@
+package parallel
+
+// systemReporter types report the CPU usage of the whole machine, including
+// other processes. The busy fraction of the machine's CPU time is scaled by
+// GOMAXPROCS, or by its value before processes began adjusting it, so a
+// saturated machine reports the controller's setpoint and a process sharing
+// its host backs off when the other processes are busy.
+type systemReporter struct {
+	// The busy and total CPU time at the last measurement, in the units of
+	// the operating system.
+	lastBusy  float64
+	lastTotal float64
+
+	// The usage of the last period in which CPU time elapsed.
+	lastUsage float64
+}
+
+// MARK: Initializers
+
+// newSystemReporter creates and returns a new system-wide reporter, or nil if
+// the operating system's CPU times can't be read.
+func newSystemReporter() usageReporter {
+	busy, total, ok := systemCPUTimes()
+	if !ok {
+		return nil
+	}
+
+	return &systemReporter{
+		lastBusy:  busy,
+		lastTotal: total,
+	}
+}
+
+// MARK: Private methods
+
+// usage returns the machine's busy fraction since the last call to usage,
+// scaled by the unadjusted GOMAXPROCS. If no CPU time has elapsed since, the
+// last usage is returned again.
+func (r *systemReporter) usage() float64 {
+	busy, total, ok := systemCPUTimes()
+	if !ok || total <= r.lastTotal {
+		return r.lastUsage
+	}
+
+	fraction := (busy - r.lastBusy) / (total - r.lastTotal)
+	r.lastBusy = busy
+	r.lastTotal = total
+
+	r.lastUsage = fraction * float64(globalMaxProcs.procs())
+	return r.lastUsage
+}
+
+// reset restarts the reporter's measurements.
+func (r *systemReporter) reset() {
+	if busy, total, ok := systemCPUTimes(); ok {
+		r.lastBusy = busy
+		r.lastTotal = total
+	}
+	r.lastUsage = 0.0
+}
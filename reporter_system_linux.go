@@ -0,0 +1,58 @@
+package parallel
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MARK: Private functions
+
+// systemCPUTimes returns the busy and total CPU time of every CPU on the
+// machine, in clock ticks, from the aggregate line of /proc/stat. Time spent
+// idle or waiting for I/O isn't busy. ok is false if the times can't be read.
+func systemCPUTimes() (busy float64, total float64, ok bool) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0.0, 0.0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return 0.0, 0.0, false
+	}
+
+	fields := strings.Fields(scanner.Text())
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0.0, 0.0, false
+	}
+
+	return parseCPUTimes(fields[1:])
+}
+
+// parseCPUTimes returns the busy and total clock ticks of the fields of a CPU
+// line of /proc/stat, following the CPU's name.
+func parseCPUTimes(fields []string) (busy float64, total float64, ok bool) {
+	if len(fields) < 4 {
+		return 0.0, 0.0, false
+	}
+
+	// The fields are user, nice, system, idle, iowait, irq, softirq and
+	// steal. Later fields count guest time, which is included in user time.
+	var idle float64
+	for i, field := range fields[:minInt(len(fields), 8)] {
+		ticks, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0.0, 0.0, false
+		}
+
+		total += float64(ticks)
+		if i == 3 || i == 4 {
+			idle += float64(ticks)
+		}
+	}
+
+	return total - idle, total, true
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package parallel
+
+// MARK: Private functions
+
+// systemCPUTimes reports that the machine's CPU times can't be read on this
+// operating system.
+func systemCPUTimes() (busy float64, total float64, ok bool) {
+	return 0.0, 0.0, false
+}
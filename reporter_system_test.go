@@ -0,0 +1,56 @@
+package parallel
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestSystemCPUTimes(t *testing.T) {
+	busy, total, ok := systemCPUTimes()
+	if !ok {
+		t.Skip("The machine's CPU times can't be read on this operating system.")
+	}
+
+	if busy < 0.0 || busy > total {
+		t.Errorf("Busy CPU time, %f, should be between 0 and the total, %f.", busy, total)
+	}
+}
+
+func TestSystemReporterUsage(t *testing.T) {
+	r := newUsageReporter(ReporterSourceSystem, SystemClock{})
+
+	end := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(end) {
+	}
+
+	u := r.usage()
+	if u < 0.0 || u > float64(runtime.GOMAXPROCS(0))+1e-9 {
+		t.Errorf("CPU usage, %f, should be between 0 and GOMAXPROCS.", u)
+	}
+}
+
+func TestVariableProcessSystemSource(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetReporterSource(ReporterSourceSystem)
+
+	v := make([]int, 100)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	if p.ReporterSource() != ReporterSourceSystem {
+		t.Errorf("Reporter source, %d, should be the system.", p.ReporterSource())
+	}
+}
@@ -0,0 +1,30 @@
+package parallel
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// procGetSystemTimes is the GetSystemTimes function of kernel32.dll.
+var procGetSystemTimes = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemTimes")
+
+// MARK: Private functions
+
+// systemCPUTimes returns the busy and total CPU time of every CPU on the
+// machine, in nanoseconds, as reported by GetSystemTimes. ok is false if the
+// times can't be read.
+func systemCPUTimes() (busy float64, total float64, ok bool) {
+	var idle, kernel, user syscall.Filetime
+	r, _, _ := procGetSystemTimes.Call(
+		uintptr(unsafe.Pointer(&idle)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)),
+	)
+	if r == 0 {
+		return 0.0, 0.0, false
+	}
+
+	// Kernel time includes the time the CPUs were idle.
+	total = float64(filetimeDuration(kernel) + filetimeDuration(user))
+	return total - float64(filetimeDuration(idle)), total, true
+}
@@ -4,7 +4,7 @@ import "testing"
 import "time"
 
 func TestReporterReset(t *testing.T) {
-	r := newReporter()
+	r := newReporter(SystemClock{})
 	nowTime := time.Now()
 
 	if r.lastTime.After(nowTime) || r.lastTime.Equal(time.Unix(0, 0)) {
@@ -21,10 +21,68 @@ func TestReporterReset(t *testing.T) {
 }
 
 func TestReporterUsage(t *testing.T) {
-	r := newReporter()
+	r := newReporter(SystemClock{})
+
+	// The process' CPU time may only advance in clock ticks, so spin until it
+	// does.
+	start := processCPUTime()
+	for processCPUTime() == start {
+	}
+
 	u := r.usage()
 
 	if u <= 0.0 {
 		t.Errorf("CPU usage, %f, should be greater than 0.0.", u)
 	}
 }
+
+func TestReporterManualClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	r := newReporter(clock)
+
+	if u := r.usage(); u != 0.0 {
+		t.Errorf("CPU usage, %f, should be 0 when no time has elapsed.", u)
+	}
+
+	start := processCPUTime()
+	for processCPUTime() == start {
+	}
+	clock.Advance(time.Hour)
+
+	if u := r.usage(); u <= 0.0 || u > 1.0 {
+		t.Errorf("CPU usage, %f, should be positive and small over an hour of manual time.", u)
+	}
+}
+
+func TestMedianUsage(t *testing.T) {
+	if u := medianUsage(nil); u != 0.0 {
+		t.Errorf("Median of no samples, %f, should be 0.", u)
+	}
+
+	if u := medianUsage([]float64{3.0, 0.1, 2.0}); u != 2.0 {
+		t.Errorf("Median, %f, should be 2.", u)
+	}
+
+	if u := medianUsage([]float64{4.0, 1.0, 2.0, 3.0}); u != 2.5 {
+		t.Errorf("Median, %f, should be 2.5.", u)
+	}
+}
+
+func TestCPUReporter(t *testing.T) {
+	for _, source := range []ReporterSource{ReporterSourceClock, ReporterSourceRuntimeMetrics, ReporterSourceSystem} {
+		r := NewCPUReporter(source)
+		if r.Source() != source {
+			t.Errorf("Source, %d, should be %d.", r.Source(), source)
+		}
+
+		end := time.Now().Add(10 * time.Millisecond)
+		for time.Now().Before(end) {
+		}
+
+		if u := r.Usage(); u < 0.0 {
+			t.Errorf("Usage from source %d, %f, should not be negative.", source, u)
+		}
+
+		r.Reset()
+	}
+}
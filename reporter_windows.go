@@ -0,0 +1,30 @@
+package parallel
+
+import (
+	"syscall"
+	"time"
+)
+
+// MARK: Private functions
+
+// processCPUTime returns the kernel and user CPU time used by the process as
+// reported by GetProcessTimes. It returns zero if the times can't be read.
+func processCPUTime() time.Duration {
+	var creation, exit, kernel, user syscall.Filetime
+	handle, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0
+	}
+
+	if err = syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+
+	return filetimeDuration(kernel) + filetimeDuration(user)
+}
+
+// filetimeDuration returns the duration represented by a FILETIME, which
+// counts intervals of 100 nanoseconds.
+func filetimeDuration(t syscall.Filetime) time.Duration {
+	return time.Duration(uint64(t.HighDateTime)<<32|uint64(t.LowDateTime)) * 100
+}
@@ -0,0 +1,193 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrorOperation types represent a single operation in a parallel process that
+// may fail. Responders should perform the i-th operation.
+type ErrorOperation func(i int) error
+
+// KeyedOperation types represent a single operation on a keyed item, such as a
+// database row. Responders should perform the operation for key.
+type KeyedOperation func(key string) error
+
+// RetryPolicy types describe how failed operations are retried.
+type RetryPolicy struct {
+	// The maximum number of times an operation is attempted. Values less than
+	// one are treated as one.
+	MaxAttempts int
+
+	// The delay before the first retry. The delay doubles after every
+	// subsequent failed attempt.
+	Backoff time.Duration
+
+	// The maximum delay between attempts. Zero means the delay isn't capped.
+	MaxBackoff time.Duration
+}
+
+// OperationError types describe an operation that failed on every attempt.
+type OperationError struct {
+	// The index of the failed operation.
+	Index int
+
+	// The key of the failed operation, if it was executed by ExecuteKeys.
+	Key string
+
+	// The number of times the operation was attempted.
+	Attempts int
+
+	// The error returned by the last attempt.
+	Err error
+}
+
+// ExecutionError types collect the operations that failed during an
+// execution.
+type ExecutionError struct {
+	// The failed operations sorted by index.
+	Failures []*OperationError
+
+	// The number of operations in the execution.
+	Iterations int
+}
+
+// MARK: Public methods
+
+// Error returns a description of the failed operation.
+func (e *OperationError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("operation %d (%s) failed after %d attempts: %s", e.Index, e.Key, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("operation %d failed after %d attempts: %s", e.Index, e.Attempts, e.Err)
+}
+
+// Unwrap returns the error returned by the operation's last attempt.
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Error returns a description of the execution's failures.
+func (e *ExecutionError) Error() string {
+	return fmt.Sprintf("parallel: %d of %d operations failed, first: %s", len(e.Failures), e.Iterations, e.Failures[0])
+}
+
+// MARK: Public functions
+
+// ExecuteWithRetry executes the operation for the specified number of
+// iterations on process, retrying failed operations according to policy.
+//
+// Concurrency is bounded by the number of routines the process uses and no
+// additional goroutines are created. If any operation fails on every attempt,
+// an *ExecutionError containing every failure is returned.
+func ExecuteWithRetry(process Process, iterations int, policy RetryPolicy, operation ErrorOperation) error {
+	return executeWithRetry(context.Background(), process, iterations, policy, nil, operation)
+}
+
+// ExecuteWithRetryContext behaves like ExecuteWithRetry, but stops the process
+// and abandons pending retries once ctx is done, in which case the context's
+// error is returned.
+func ExecuteWithRetryContext(ctx context.Context, process Process, iterations int, policy RetryPolicy, operation ErrorOperation) error {
+	return executeWithRetry(ctx, process, iterations, policy, nil, operation)
+}
+
+// ExecuteKeys executes the operation once for each key on process, retrying
+// failed operations according to policy. It is intended for batches of
+// database operations where each item is identified by a key.
+//
+// If any operation fails on every attempt, an *ExecutionError containing every
+// failure is returned.
+func ExecuteKeys(process Process, keys []string, policy RetryPolicy, operation KeyedOperation) error {
+	return ExecuteKeysContext(context.Background(), process, keys, policy, operation)
+}
+
+// ExecuteKeysContext behaves like ExecuteKeys, but stops the process and
+// abandons pending retries once ctx is done, in which case the context's error
+// is returned.
+func ExecuteKeysContext(ctx context.Context, process Process, keys []string, policy RetryPolicy, operation KeyedOperation) error {
+	return executeWithRetry(ctx, process, len(keys), policy, keys, func(i int) error {
+		return operation(keys[i])
+	})
+}
+
+// MARK: Private functions
+
+// executeWithRetry executes the operation on process and collects the
+// failures. If keys is not nil, failures are labeled with their key.
+func executeWithRetry(ctx context.Context, process Process, iterations int, policy RetryPolicy, keys []string, operation ErrorOperation) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var mutex sync.Mutex
+	var failures []*OperationError
+
+	err := process.ExecuteContext(ctx, iterations, func(ctx context.Context, i int) {
+		backoff := policy.Backoff
+
+		var err error
+		for attempt := 1; attempt <= attempts; attempt++ {
+			if err = operation(i); err == nil {
+				return
+			}
+
+			if attempt < attempts && backoff > 0 {
+				if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+				if !waitBackoff(ctx, backoff) {
+					return
+				}
+				backoff *= 2
+			}
+		}
+
+		failure := &OperationError{
+			Index:    i,
+			Attempts: attempts,
+			Err:      err,
+		}
+		if keys != nil {
+			failure.Key = keys[i]
+		}
+
+		mutex.Lock()
+		failures = append(failures, failure)
+		mutex.Unlock()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+
+	return &ExecutionError{
+		Failures:   failures,
+		Iterations: iterations,
+	}
+}
+
+// waitBackoff waits for the backoff to elapse and returns true, or returns
+// false as soon as ctx is done.
+func waitBackoff(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestExecuteWithRetry(t *testing.T) {
+	attempts := make([]safeInt, 100)
+	p := NewFixedProcess(4)
+	err := ExecuteWithRetry(p, len(attempts), RetryPolicy{MaxAttempts: 3}, func(i int) error {
+		n := attempts[i].add(1)
+		if i == 42 || (i%2 == 0 && n < 3) {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	executionErr, ok := err.(*ExecutionError)
+	if !ok {
+		t.Fatalf("Error, %v, should be an execution error.", err)
+	}
+
+	if len(executionErr.Failures) != 1 {
+		t.Fatalf("Failure count, %d, should be 1.", len(executionErr.Failures))
+	}
+
+	failure := executionErr.Failures[0]
+	if failure.Index != 42 || failure.Attempts != 3 {
+		t.Errorf("Failure, %s, should be for operation 42 after 3 attempts.", failure)
+	}
+
+	for i := range attempts {
+		if i%2 == 0 && attempts[i].get() != 3 {
+			t.Errorf("Operation %d should have been attempted 3 times, not %d.", i, attempts[i].get())
+			break
+		}
+	}
+}
+
+func TestExecuteKeys(t *testing.T) {
+	keys := []string{"a", "b", "c", "d"}
+	p := NewFixedProcess(2)
+	err := ExecuteKeys(p, keys, RetryPolicy{}, func(key string) error {
+		if key == "c" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	executionErr, ok := err.(*ExecutionError)
+	if !ok {
+		t.Fatalf("Error, %v, should be an execution error.", err)
+	}
+
+	if len(executionErr.Failures) != 1 || executionErr.Failures[0].Key != "c" {
+		t.Errorf("Failures, %v, should only contain key c.", executionErr.Failures)
+	}
+}
+
+func TestExecuteWithRetrySuccess(t *testing.T) {
+	p := NewFixedProcess(2)
+	err := ExecuteWithRetry(p, 100, RetryPolicy{MaxAttempts: 2}, func(i int) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Error, %s, should be nil.", err)
+	}
+}
+
+func TestExecuteWithRetryMaxBackoff(t *testing.T) {
+	failed := errors.New("failed")
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		Backoff:     time.Hour,
+		MaxBackoff:  time.Millisecond,
+	}
+
+	start := time.Now()
+	err := ExecuteWithRetry(NewFixedProcess(2), 4, policy, func(i int) error {
+		return failed
+	})
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Elapsed time, %s, should be capped by the maximum backoff.", elapsed)
+	}
+
+	executionErr, ok := err.(*ExecutionError)
+	if !ok {
+		t.Fatalf("Error, %v, should be an execution error.", err)
+	}
+
+	if len(executionErr.Failures) != 4 {
+		t.Fatalf("Failure count, %d, should be 4.", len(executionErr.Failures))
+	}
+
+	if executionErr.Failures[0].Unwrap() != failed {
+		t.Errorf("Unwrapped error, %v, should be %s.", executionErr.Failures[0].Unwrap(), failed)
+	}
+}
+
+func TestExecuteWithRetryContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     time.Hour,
+	}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := ExecuteWithRetryContext(ctx, NewFixedProcess(2), 4, policy, func(i int) error {
+		return errors.New("failed")
+	})
+
+	if err != context.Canceled {
+		t.Errorf("Error, %v, should be %s.", err, context.Canceled)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Elapsed time, %s, should end when the context is canceled.", elapsed)
+	}
+}
@@ -0,0 +1,44 @@
+package parallel
+
+import "sync/atomic"
+
+// RoutineOperation types represent a single operation in a parallel process
+// that receives the context of the routine executing it. Responders should
+// perform the i-th operation.
+type RoutineOperation func(i int, r *RoutineContext)
+
+// RoutineContext types describe the routine executing an operation. A routine
+// passes the same context to every operation it executes, so operations can
+// keep per-routine state in it instead of capturing shared state in a closure.
+type RoutineContext struct {
+	// The index of the routine within its execution.
+	Routine int
+
+	// Scratch storage owned by the routine. It is nil when the routine starts
+	// and keeps whatever its operations store in it until the routine returns.
+	// Only the routine accesses it, so it needs no synchronization.
+	Scratch interface{}
+
+	// Set to 1 once the execution has been stopped.
+	stopped *int32
+}
+
+// MARK: Public methods
+
+// Stopped returns whether or not the execution has been stopped. Operations
+// that take a long time can check it and return early.
+func (r *RoutineContext) Stopped() bool {
+	return atomic.LoadInt32(r.stopped) == 1
+}
+
+// MARK: Private functions
+
+// routineOperation returns an operation that calls operation with the context
+// of the routine-th routine. The routine's execution is stopped once stopped
+// is set to 1.
+func routineOperation(operation RoutineOperation, routine int, stopped *int32) Operation {
+	r := &RoutineContext{Routine: routine, stopped: stopped}
+	return func(i int) {
+		operation(i, r)
+	}
+}
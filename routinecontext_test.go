@@ -0,0 +1,91 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessExecuteRoutines(t *testing.T) {
+	p := NewFixedProcess(4)
+	contexts := testRoutineContexts(t, 1000, p.ExecuteRoutines)
+
+	if len(contexts) > 4 {
+		t.Errorf("%d routine contexts were used by 4 routines.", len(contexts))
+	}
+}
+
+func TestVariableProcessExecuteRoutines(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 2, 4, c, false)
+	testRoutineContexts(t, 1000, p.ExecuteRoutines)
+}
+
+func TestRoutineContextStopped(t *testing.T) {
+	p := NewFixedProcess(1)
+	p.ExecuteRoutines(100, func(i int, r *RoutineContext) {
+		if r.Stopped() {
+			t.Errorf("The routine context reported that the process stopped at iteration %d.", i)
+		}
+		if i == 10 {
+			p.Stop()
+			if !r.Stopped() {
+				t.Error("The routine context should report that the process stopped.")
+			}
+		}
+	})
+
+	p.ExecuteRoutines(1, func(i int, r *RoutineContext) {
+		if r.Stopped() {
+			t.Error("A new execution shouldn't begin stopped.")
+		}
+	})
+
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	v := NewVariableProcess(time.Millisecond, 1, 1, c, false)
+	v.ExecuteRoutines(100, func(i int, r *RoutineContext) {
+		if i == 10 {
+			v.Stop()
+			if !r.Stopped() {
+				t.Error("The routine context should report that the variable process stopped.")
+			}
+		}
+	})
+}
+
+// MARK: Helpers
+
+// testRoutineContexts executes iterations operations with execute, counting
+// the iterations each routine executes in its scratch storage, and returns the
+// contexts the routines used.
+func testRoutineContexts(t *testing.T, iterations int, execute func(int, RoutineOperation)) map[*RoutineContext]bool {
+	var mutex sync.Mutex
+	contexts := make(map[*RoutineContext]bool)
+	routines := make(map[int]*RoutineContext)
+
+	execute(iterations, func(i int, r *RoutineContext) {
+		if r.Scratch == nil {
+			r.Scratch = new(int)
+
+			mutex.Lock()
+			if routines[r.Routine] != nil {
+				t.Errorf("Routine %d was given two contexts.", r.Routine)
+			}
+			routines[r.Routine] = r
+			contexts[r] = true
+			mutex.Unlock()
+		}
+		*r.Scratch.(*int)++
+	})
+
+	total := 0
+	for r := range contexts {
+		total += *r.Scratch.(*int)
+	}
+	if total != iterations {
+		t.Errorf("The routines' scratch storage counted %d iterations, expected %d.", total, iterations)
+	}
+	return contexts
+}
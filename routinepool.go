@@ -0,0 +1,86 @@
+package parallel
+
+import "sync"
+
+// routinePool types hold goroutines that were started ahead of an execution
+// and park until a routine is handed to them.
+type routinePool struct {
+	// A mutex to protect the pool's size and number of busy goroutines.
+	mutex sync.Mutex
+
+	// The number of goroutines in the pool, and the number of them that have
+	// been handed a routine that hasn't returned.
+	size int
+	busy int
+
+	// The channel routines are handed to the pool's goroutines on. A nil
+	// routine exits the goroutine that receives it.
+	routines chan func()
+}
+
+// MARK: Private methods
+
+// resize exits the pool's goroutines and starts n new ones, returning once
+// they are parked. It must not be called while any of the pool's goroutines
+// are busy.
+func (r *routinePool) resize(n int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if n == r.size {
+		return
+	}
+
+	previous := r.routines
+	r.routines = make(chan func(), n)
+	for i := 0; i < r.size; i++ {
+		previous <- nil
+	}
+
+	var ready sync.WaitGroup
+	ready.Add(n)
+	for i := 0; i < n; i++ {
+		go r.park(r.routines, &ready)
+	}
+	ready.Wait()
+	r.size = n
+}
+
+// run runs routine on one of the pool's idle goroutines, or on a new
+// goroutine if none are idle. A nil pool always starts a new goroutine.
+func (r *routinePool) run(routine func()) {
+	if r == nil {
+		go routine()
+		return
+	}
+
+	r.mutex.Lock()
+	if r.busy == r.size {
+		r.mutex.Unlock()
+		go routine()
+		return
+	}
+
+	// At most one routine is buffered per idle goroutine, so the send never
+	// blocks and the routine never waits behind another.
+	r.busy++
+	routines := r.routines
+	r.mutex.Unlock()
+	routines <- routine
+}
+
+// park runs the routines handed to the pool until it receives a nil routine.
+func (r *routinePool) park(routines chan func(), ready *sync.WaitGroup) {
+	ready.Done()
+	for routine := range routines {
+		if routine == nil {
+			return
+		}
+
+		routine()
+
+		r.mutex.Lock()
+		r.busy--
+		r.mutex.Unlock()
+	}
+}
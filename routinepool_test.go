@@ -0,0 +1,100 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestRoutinePool(t *testing.T) {
+	var r routinePool
+	r.resize(2)
+	goroutines := runtime.NumGoroutine()
+
+	release := make(chan struct{})
+	var running sync.WaitGroup
+	running.Add(3)
+	for i := 0; i < 3; i++ {
+		r.run(func() {
+			running.Done()
+			<-release
+		})
+	}
+	running.Wait()
+
+	if n := runtime.NumGoroutine(); n != goroutines+1 {
+		t.Errorf("%d goroutines are running; only the routine without an idle goroutine should start one.", n-goroutines)
+	}
+
+	close(release)
+	waitForGoroutines(t, goroutines)
+
+	r.resize(0)
+	waitForGoroutines(t, goroutines-2)
+}
+
+func TestNilRoutinePool(t *testing.T) {
+	var r *routinePool
+	done := make(chan struct{})
+	r.run(func() {
+		close(done)
+	})
+	<-done
+}
+
+func TestFixedProcessPrepare(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.Prepare(4)
+	defer p.Prepare(0)
+	goroutines := runtime.NumGoroutine()
+
+	for execution := 0; execution < 3; execution++ {
+		var count, started int32
+		p.Execute(100, func(i int) {
+			atomic.AddInt32(&count, 1)
+			if n := int32(runtime.NumGoroutine() - goroutines); n > atomic.LoadInt32(&started) {
+				atomic.StoreInt32(&started, n)
+			}
+		})
+
+		if count != 100 {
+			t.Errorf("%d iterations executed, expected 100.", count)
+		}
+		if execution == 0 && started > 0 {
+			t.Errorf("The first execution started %d goroutines instead of using the prepared ones.", started)
+		}
+	}
+}
+
+func TestVariableProcessPrepare(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 2, 4, c, false)
+	p.Prepare(2)
+	defer p.Prepare(0)
+
+	var count int32
+	p.Execute(200, func(i int) {
+		atomic.AddInt32(&count, 1)
+	})
+
+	if count != 200 {
+		t.Errorf("%d iterations executed, expected 200.", count)
+	}
+}
+
+// MARK: Helpers
+
+// waitForGoroutines waits for the number of goroutines to fall to n.
+func waitForGoroutines(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines are running, expected %d.", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,75 @@
+package parallel
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RoutineStats types describe the work a single routine of an execution did.
+type RoutineStats struct {
+	// The index of the routine. Fixed processes number their routines from
+	// zero, and variable processes number them in the order they started.
+	Routine int `json:"routine"`
+
+	// The number of iterations the routine completed.
+	Iterations int64 `json:"iterations"`
+
+	// The time from when the routine started until it exited.
+	Busy time.Duration `json:"busy"`
+}
+
+// routineStatsRecorder types collect the stats of an execution's routines as
+// they exit.
+type routineStatsRecorder struct {
+	// The number of routines that have started. It is updated atomically and
+	// is the first field so that it is 64-bit aligned.
+	started int64
+
+	// A mutex to protect the recorded stats.
+	mutex sync.Mutex
+
+	// The stats of the routines that have exited.
+	routines []RoutineStats
+}
+
+// MARK: Private methods
+
+// reset discards the recorded stats.
+func (r *routineStatsRecorder) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	atomic.StoreInt64(&r.started, 0)
+	r.routines = nil
+}
+
+// index returns the index of a routine that is starting, counting the routines
+// that have started since the recorder was reset.
+func (r *routineStatsRecorder) index() int {
+	return int(atomic.AddInt64(&r.started, 1) - 1)
+}
+
+// record records the stats of a routine that started at start and is
+// exiting. It is deferred by routines, so stats are read when they exit.
+func (r *routineStatsRecorder) record(stats *RoutineStats, start time.Time) {
+	stats.Busy = time.Since(start)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.routines = append(r.routines, *stats)
+}
+
+// snapshot returns the stats of the routines that have exited, ordered by
+// routine.
+func (r *routineStatsRecorder) snapshot() []RoutineStats {
+	r.mutex.Lock()
+	routines := append([]RoutineStats(nil), r.routines...)
+	r.mutex.Unlock()
+
+	sort.Slice(routines, func(i int, j int) bool {
+		return routines[i].Routine < routines[j].Routine
+	})
+	return routines
+}
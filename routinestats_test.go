@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessRoutineStats(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleStatic)
+	p.Execute(100, func(i int) {
+		time.Sleep(10 * time.Microsecond)
+	})
+
+	routines := p.RoutineStats()
+	if len(routines) != 4 {
+		t.Fatalf("Routines, %d, should be 4.", len(routines))
+	}
+
+	for i, r := range routines {
+		if r.Routine != i {
+			t.Errorf("Routine %d's index, %d, should be %d.", i, r.Routine, i)
+		}
+		if r.Iterations != 25 {
+			t.Errorf("Routine %d completed %d iterations instead of the 25 in its block.", i, r.Iterations)
+		}
+		if r.Busy < 25*10*time.Microsecond {
+			t.Errorf("Routine %d's busy time, %s, should be at least 250µs.", i, r.Busy)
+		}
+	}
+
+	if s := p.Summary(); len(s.Stats.Routines) != 4 {
+		t.Errorf("The summary's routines, %d, should be 4.", len(s.Stats.Routines))
+	}
+
+	p.Execute(2, func(i int) {})
+	if len(p.RoutineStats()) != 2 {
+		t.Errorf("Routines, %d, should be reset for each execution.", len(p.RoutineStats()))
+	}
+}
+
+func TestVariableProcessRoutineStats(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 2, 4, c, false)
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	routines := p.RoutineStats()
+	if len(routines) < 2 {
+		t.Fatalf("Routines, %d, should be at least 2.", len(routines))
+	}
+
+	total := int64(0)
+	for i, r := range routines {
+		if r.Routine != i {
+			t.Errorf("Routine %d's index, %d, should be %d.", i, r.Routine, i)
+		}
+		total += r.Iterations
+	}
+	if total != 100 {
+		t.Errorf("The routines completed %d iterations instead of 100.", total)
+	}
+}
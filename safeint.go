@@ -1,45 +1,79 @@
 package parallel
 
-import "sync"
+import "sync/atomic"
+
+// The bounds of the int type.
+const (
+	maxIntValue = int(^uint(0) >> 1)
+	minIntValue = -maxIntValue - 1
+)
 
 // safeInt wraps an integer type and exposes methods to safely read/write to the
-// integer value from multiple threads.
+// integer value from multiple threads. The value is updated atomically, so a
+// safeInt must be 64-bit aligned; place it at the start of the struct holding
+// it.
 type safeInt struct {
-	value int
-	mutex sync.Mutex
+	value int64
 }
 
 // get gets the integer value.
 func (s *safeInt) get() int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.value
+	return int(atomic.LoadInt64(&s.value))
 }
 
 // set sets the integer value and returns the result.
 func (s *safeInt) set(n int) int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.value = n
-	return s.value
+	atomic.StoreInt64(&s.value, int64(n))
+	return n
 }
 
 // add adds the input parameter to the integer value and returns the result.
+// The result saturates at the bounds of int instead of overflowing.
 func (s *safeInt) add(n int) int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.value += n
-	return s.value
+	for {
+		old := s.get()
+		value := saturatingAdd(old, n)
+		if s.compareAndSwap(old, value) {
+			return value
+		}
+	}
 }
 
 // subtract subtracts the input parameter from the integer value and returns the
-// result.
+// result. The result saturates at the bounds of int instead of overflowing.
 func (s *safeInt) subtract(n int) int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	for {
+		old := s.get()
+		value := saturatingAdd(old, -n)
+		if n == minIntValue {
+			value = saturatingAdd(saturatingAdd(old, maxIntValue), 1)
+		}
+		if s.compareAndSwap(old, value) {
+			return value
+		}
+	}
+}
+
+// getAndAdd adds the input parameter to the integer value and returns the
+// value before the addition. Unlike add it is a single atomic instruction and
+// doesn't saturate, so it is used for counters that stay far from the bounds of
+// int, like the iterations claimed from a scheduler.
+func (s *safeInt) getAndAdd(n int) int {
+	return int(atomic.AddInt64(&s.value, int64(n)) - int64(n))
+}
+
+// compareAndSwap sets the integer value to new if it is old and returns
+// whether or not it was set.
+func (s *safeInt) compareAndSwap(old int, new int) bool {
+	return atomic.CompareAndSwapInt64(&s.value, int64(old), int64(new))
+}
 
-	s.value -= n
-	return s.value
+// saturatingAdd returns a + b, clamped to the bounds of int.
+func saturatingAdd(a int, b int) int {
+	if b > 0 && a > maxIntValue-b {
+		return maxIntValue
+	} else if b < 0 && a < minIntValue-b {
+		return minIntValue
+	}
+	return a + b
 }
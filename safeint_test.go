@@ -1,6 +1,9 @@
 package parallel
 
-import "testing"
+import (
+	"sync"
+	"testing"
+)
 
 func TestGetSafeIntValue(t *testing.T) {
 	var s safeInt
@@ -35,3 +38,62 @@ func TestSubtractSafeIntValue(t *testing.T) {
 		t.Errorf("Value, %d, should be -2.", s.value)
 	}
 }
+
+func TestSafeIntSaturation(t *testing.T) {
+	s := safeInt{value: int64(maxIntValue - 1)}
+	s.add(10)
+
+	if s.get() != maxIntValue {
+		t.Errorf("Value, %d, should saturate at %d.", s.get(), maxIntValue)
+	}
+
+	s.set(minIntValue + 1)
+	s.subtract(10)
+
+	if s.get() != minIntValue {
+		t.Errorf("Value, %d, should saturate at %d.", s.get(), minIntValue)
+	}
+}
+
+func TestGetAndAddSafeIntValue(t *testing.T) {
+	s := safeInt{value: 3}
+	if n := s.getAndAdd(2); n != 3 {
+		t.Errorf("Previous value, %d, should be 3.", n)
+	}
+
+	if s.get() != 5 {
+		t.Errorf("Value, %d, should be 5.", s.get())
+	}
+}
+
+func TestCompareAndSwapSafeIntValue(t *testing.T) {
+	s := safeInt{value: 1}
+	if s.compareAndSwap(2, 3) {
+		t.Error("The value shouldn't be swapped when it doesn't match.")
+	}
+
+	if !s.compareAndSwap(1, 3) || s.get() != 3 {
+		t.Errorf("Value, %d, should be swapped to 3.", s.get())
+	}
+}
+
+func TestConcurrentSafeIntValue(t *testing.T) {
+	var s safeInt
+	var group sync.WaitGroup
+	group.Add(8)
+	for n := 0; n < 8; n++ {
+		go func() {
+			defer group.Done()
+			for i := 0; i < 1000; i++ {
+				s.add(1)
+				s.getAndAdd(1)
+				s.subtract(1)
+			}
+		}()
+	}
+	group.Wait()
+
+	if s.get() != 8000 {
+		t.Errorf("Value, %d, should be 8000.", s.get())
+	}
+}
@@ -0,0 +1,617 @@
+package parallel
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Schedule types determine how a fixed process divides its iterations between
+// its routines.
+type Schedule int
+
+const (
+	// ScheduleDynamic routines claim blocks of iterations from a single shared
+	// counter, one counter update per block. Blocks hold a single iteration
+	// unless the process' block size is set. With single iterations, stopping
+	// the process guarantees that no iteration after the ones currently
+	// executing begins.
+	ScheduleDynamic Schedule = iota
+
+	// ScheduleWorkStealing routines begin with an equal contiguous block of
+	// iterations. Routines that finish their block early steal half of the
+	// remaining iterations of the routine with the most work left, which
+	// balances workloads with skewed per-iteration costs without contending on
+	// a shared counter.
+	ScheduleWorkStealing
+
+	// ScheduleGuided routines claim chunks of iterations from a shared counter.
+	// Chunks begin large and shrink as fewer iterations remain, which keeps
+	// counter contention low early on while balancing the routines' finishing
+	// times.
+	ScheduleGuided
+
+	// ScheduleStatic divides the iterations into one contiguous block per
+	// routine before execution begins. Routines never synchronize with each
+	// other, which is the fastest schedule for workloads with uniform
+	// per-iteration costs.
+	ScheduleStatic
+
+	// ScheduleAdaptive routines claim single iterations from a shared counter
+	// during a warm-up window while the process measures the average duration
+	// of an iteration and the overhead of claiming one. Afterwards routines
+	// claim chunks just large enough to keep the claiming overhead below the
+	// process' overhead target, shrinking them as fewer iterations remain.
+	// Iteration weights are ignored since costs are measured.
+	ScheduleAdaptive
+)
+
+// String returns the name of the schedule.
+func (s Schedule) String() string {
+	switch s {
+	case ScheduleDynamic:
+		return "dynamic"
+	case ScheduleWorkStealing:
+		return "work-stealing"
+	case ScheduleGuided:
+		return "guided"
+	case ScheduleStatic:
+		return "static"
+	case ScheduleAdaptive:
+		return "adaptive"
+	default:
+		return fmt.Sprintf("Schedule(%d)", int(s))
+	}
+}
+
+// defaultOverheadTarget is the fraction of execution time the adaptive
+// schedule allows to be spent claiming iterations.
+const defaultOverheadTarget = 0.01
+
+// adaptiveWarmupPerRoutine is the number of iterations each routine executes
+// one at a time while the adaptive schedule measures their duration.
+const adaptiveWarmupPerRoutine = 16
+
+// dynamicClaimsPerRoutine is the number of blocks each routine claims when the
+// dynamic schedule sizes its blocks automatically.
+const dynamicClaimsPerRoutine = 64
+
+// scheduler types hand out iterations to the routines of a process.
+type scheduler interface {
+
+	// next claims the next range of iterations, [start, end), for the
+	// routine-th routine. It returns false when no iterations remain.
+	next(routine int) (start int, end int, ok bool)
+
+	// stop prevents any further iterations from being claimed.
+	stop()
+
+	// stopped returns whether or not iteration i of a claimed range must not
+	// begin because the scheduler has been stopped. Routines check it before
+	// each iteration of a claimed range.
+	stopped(i int) bool
+}
+
+// MARK: Initializers
+
+// newScheduler creates and returns a new scheduler for the specified number of
+// iterations and routines. Schedules that partition or chunk iterations divide
+// them by costs, which may be nil for iterations of equal weight. The adaptive
+// schedule sizes its chunks to keep the claiming overhead below overheadTarget,
+// starting from claimOverhead if it was measured before, and the dynamic
+// schedule claims blockSize iterations at a time, or chooses a block size if it
+// is zero.
+func newScheduler(schedule Schedule, iterations int, numRoutines int, costs *iterationCosts, overheadTarget float64, claimOverhead time.Duration, blockSize int) scheduler {
+	switch schedule {
+	case ScheduleAdaptive:
+		return newAdaptiveScheduler(iterations, numRoutines, overheadTarget, claimOverhead)
+	case ScheduleWorkStealing:
+		return newStealingScheduler(costs.partition(iterations, numRoutines))
+	case ScheduleGuided:
+		return newGuidedScheduler(iterations, numRoutines, costs)
+	case ScheduleStatic:
+		return newStaticScheduler(costs.partition(iterations, numRoutines))
+	default:
+		if blockSize == 0 {
+			blockSize = dynamicBlockSize(iterations, numRoutines)
+		}
+		return newDynamicScheduler(iterations, numRoutines, blockSize)
+	}
+}
+
+// MARK: Dynamic scheduling
+
+// dynamicScheduler types hand out fixed size blocks of iterations from a
+// shared counter. When the scheduler is stopped, routines finish the blocks
+// that precede the last block claimed, so that the iterations that ran are
+// still a prefix of the execution's indices.
+type dynamicScheduler struct {
+	// The number of iterations that have been claimed.
+	iteration safeInt
+	_         cacheLinePad
+
+	// The start of the last block claimed before the scheduler was stopped.
+	last int64
+
+	// The total number of iterations.
+	iterations int
+
+	// The number of iterations claimed at a time.
+	blockSize int
+
+	// The start of each routine's block. Each routine only writes its own
+	// element.
+	routines []dynamicRoutine
+
+	// Whether the scheduler is running, stopping or stopped.
+	state int32
+}
+
+// dynamicRoutine types hold the start of a routine's block. They are padded so
+// that neighbouring routines don't share a cache line.
+type dynamicRoutine struct {
+	start int64
+	_     cacheLinePad
+}
+
+// The states of a dynamic scheduler. A stopping scheduler is finding the last
+// block that was claimed.
+const (
+	dynamicRunning int32 = iota
+	dynamicStopping
+	dynamicStopped
+)
+
+// newDynamicScheduler creates and returns a new dynamic scheduler handing out
+// blockSize iterations at a time to numRoutines routines.
+func newDynamicScheduler(iterations int, numRoutines int, blockSize int) *dynamicScheduler {
+	s := &dynamicScheduler{
+		iterations: iterations,
+		blockSize:  blockSize,
+		routines:   make([]dynamicRoutine, numRoutines),
+	}
+
+	for r := range s.routines {
+		s.routines[r].start = -1
+	}
+
+	return s
+}
+
+func (s *dynamicScheduler) next(routine int) (int, int, bool) {
+	start := s.iteration.getAndAdd(s.blockSize)
+	if start >= s.iterations {
+		return 0, 0, false
+	}
+
+	atomic.StoreInt64(&s.routines[routine].start, int64(start))
+	return start, minInt(start+s.blockSize, s.iterations), true
+}
+
+func (s *dynamicScheduler) stop() {
+	if !atomic.CompareAndSwapInt32(&s.state, dynamicRunning, dynamicStopping) {
+		return
+	}
+
+	s.iteration.set(s.iterations)
+	last := int64(-1)
+	for r := range s.routines {
+		if start := atomic.LoadInt64(&s.routines[r].start); start > last {
+			last = start
+		}
+	}
+
+	atomic.StoreInt64(&s.last, last)
+	atomic.StoreInt32(&s.state, dynamicStopped)
+}
+
+func (s *dynamicScheduler) stopped(i int) bool {
+	state := atomic.LoadInt32(&s.state)
+	for state == dynamicStopping {
+		runtime.Gosched()
+		state = atomic.LoadInt32(&s.state)
+	}
+
+	return state == dynamicStopped && int64(i) > atomic.LoadInt64(&s.last)
+}
+
+// dynamicBlockSize returns the block size the dynamic schedule uses when the
+// process' block size isn't set. Small executions claim single iterations so
+// that expensive operations stay balanced between routines.
+func dynamicBlockSize(iterations int, numRoutines int) int {
+	if n := iterations / (numRoutines * dynamicClaimsPerRoutine); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// MARK: Guided scheduling
+
+// guidedScheduler types hand out chunks of iterations from a shared counter
+// whose size is proportional to the number of remaining iterations.
+type guidedScheduler struct {
+	// The number of iterations that have been claimed.
+	iteration safeInt
+	_         cacheLinePad
+
+	// The total number of iterations.
+	iterations int
+
+	// The number of routines sharing the iterations.
+	numRoutines int
+
+	// The costs of the iterations, or nil if they have equal weight.
+	costs *iterationCosts
+
+	// Whether or not the scheduler has been stopped.
+	isStopped int32
+}
+
+// newGuidedScheduler creates and returns a new guided scheduler.
+func newGuidedScheduler(iterations int, numRoutines int, costs *iterationCosts) *guidedScheduler {
+	return &guidedScheduler{
+		iterations:  iterations,
+		numRoutines: numRoutines,
+		costs:       costs,
+	}
+}
+
+func (s *guidedScheduler) next(routine int) (int, int, bool) {
+	for {
+		start := s.iteration.get()
+		if start >= s.iterations {
+			return 0, 0, false
+		}
+
+		end := s.costs.chunk(start, s.iterations, 2*s.numRoutines)
+		if s.iteration.compareAndSwap(start, end) {
+			return start, end, true
+		}
+	}
+}
+
+func (s *guidedScheduler) stop() {
+	atomic.StoreInt32(&s.isStopped, 1)
+	s.iteration.set(s.iterations)
+}
+
+func (s *guidedScheduler) stopped(i int) bool {
+	return atomic.LoadInt32(&s.isStopped) == 1
+}
+
+// MARK: Adaptive scheduling
+
+// adaptiveScheduler types hand out chunks of iterations from a shared counter
+// whose size is chosen from the measured iteration duration and claiming
+// overhead.
+type adaptiveScheduler struct {
+	// The number of iterations that have been claimed.
+	iteration safeInt
+	_         cacheLinePad
+
+	// The total number of iterations.
+	iterations int
+
+	// The number of routines sharing the iterations.
+	numRoutines int
+
+	// The fraction of execution time that may be spent claiming iterations.
+	overheadTarget float64
+
+	// The number of iteration durations to measure before choosing a chunk
+	// size.
+	warmup int
+
+	// The chosen chunk size, or zero during the warm-up window.
+	chunkSize int64
+
+	// The time each routine finished its last claim and the number of
+	// iterations it claimed. Each routine only accesses its own element.
+	routines []adaptiveRoutine
+
+	// A mutex to protect the measurements.
+	sampleMutex sync.Mutex
+
+	// The summed iteration durations and claiming overheads, and the number of
+	// each that were measured.
+	latency       time.Duration
+	latencyCount  int
+	overhead      time.Duration
+	overheadCount int
+
+	// Whether or not the scheduler has been stopped.
+	isStopped int32
+}
+
+// adaptiveRoutine types record a routine's last claim. They are padded so
+// that neighbouring routines don't share a cache line.
+type adaptiveRoutine struct {
+	last time.Time
+	size int
+	_    cacheLinePad
+}
+
+// newAdaptiveScheduler creates and returns a new adaptive scheduler. A
+// positive claimOverhead, measured by an earlier execution, seeds the
+// scheduler's claiming overhead.
+func newAdaptiveScheduler(iterations int, numRoutines int, overheadTarget float64, claimOverhead time.Duration) *adaptiveScheduler {
+	s := &adaptiveScheduler{
+		iterations:     iterations,
+		numRoutines:    numRoutines,
+		overheadTarget: overheadTarget,
+		warmup:         minInt(adaptiveWarmupPerRoutine*numRoutines, iterations/8),
+		routines:       make([]adaptiveRoutine, numRoutines),
+	}
+	if claimOverhead > 0 {
+		s.overhead = claimOverhead
+		s.overheadCount = 1
+	}
+	return s
+}
+
+func (s *adaptiveScheduler) next(routine int) (int, int, bool) {
+	if chunk := int(atomic.LoadInt64(&s.chunkSize)); chunk > 0 {
+		return s.claim(chunk)
+	}
+
+	r := &s.routines[routine]
+	now := time.Now()
+	var latency time.Duration
+	if r.size > 0 {
+		latency = now.Sub(r.last) / time.Duration(r.size)
+	}
+
+	start, end, ok := s.claim(1)
+	r.last = time.Now()
+	r.size = end - start
+	s.measure(latency, r.last.Sub(now))
+
+	return start, end, ok
+}
+
+func (s *adaptiveScheduler) stop() {
+	atomic.StoreInt32(&s.isStopped, 1)
+	s.iteration.set(s.iterations)
+}
+
+func (s *adaptiveScheduler) stopped(i int) bool {
+	return atomic.LoadInt32(&s.isStopped) == 1
+}
+
+// claimOverhead returns the average measured overhead of claiming a single
+// iteration, or zero if none was measured.
+func (s *adaptiveScheduler) claimOverhead() time.Duration {
+	s.sampleMutex.Lock()
+	defer s.sampleMutex.Unlock()
+
+	if s.overheadCount == 0 {
+		return 0
+	}
+	return s.overhead / time.Duration(s.overheadCount)
+}
+
+// claim claims up to chunk iterations from the shared counter. Chunks are
+// limited to a share of the remaining iterations so that routines finish at
+// about the same time.
+func (s *adaptiveScheduler) claim(chunk int) (int, int, bool) {
+	for {
+		start := s.iteration.get()
+		if start >= s.iterations {
+			return 0, 0, false
+		}
+
+		size := chunk
+		remaining := s.iterations - start
+		if limit := remaining / (2 * s.numRoutines); size > limit {
+			size = limit
+		}
+		if size < 1 {
+			size = 1
+		}
+
+		if s.iteration.compareAndSwap(start, start+size) {
+			return start, start + size, true
+		}
+	}
+}
+
+// measure records an iteration duration and a claiming overhead, and chooses
+// the chunk size once enough durations have been measured. A zero latency is
+// not recorded.
+func (s *adaptiveScheduler) measure(latency time.Duration, overhead time.Duration) {
+	s.sampleMutex.Lock()
+	defer s.sampleMutex.Unlock()
+
+	if atomic.LoadInt64(&s.chunkSize) > 0 {
+		return
+	}
+
+	s.overhead += overhead
+	s.overheadCount++
+	if latency > 0 {
+		s.latency += latency
+		s.latencyCount++
+	}
+
+	if s.latencyCount < s.warmup || s.latencyCount == 0 {
+		return
+	}
+
+	atomic.StoreInt64(&s.chunkSize, int64(adaptiveChunkSize(
+		s.latency/time.Duration(s.latencyCount),
+		s.overhead/time.Duration(s.overheadCount),
+		s.overheadTarget,
+	)))
+}
+
+// adaptiveChunkSize returns the smallest chunk size that keeps the overhead of
+// claiming a chunk below target times the duration of executing it.
+func adaptiveChunkSize(latency time.Duration, overhead time.Duration, target float64) int {
+	if latency <= 0 {
+		return 1
+	}
+
+	chunk := math.Ceil(float64(overhead) / (target * float64(latency)))
+	if chunk < 1.0 {
+		return 1
+	}
+	if chunk > float64(maxIntValue) {
+		return maxIntValue
+	}
+	return int(chunk)
+}
+
+// MARK: Static scheduling
+
+// staticScheduler types hand each routine a single contiguous block of
+// iterations.
+type staticScheduler struct {
+	// The boundaries of the routines' blocks.
+	bounds []int
+
+	// Whether or not each routine has claimed its block. Each routine only
+	// accesses its own element.
+	claimed []bool
+
+	// Whether or not the scheduler has been stopped.
+	isStopped int32
+}
+
+// newStaticScheduler creates and returns a new static scheduler whose r-th
+// routine executes the block [bounds[r], bounds[r+1]).
+func newStaticScheduler(bounds []int) *staticScheduler {
+	return &staticScheduler{
+		bounds:  bounds,
+		claimed: make([]bool, len(bounds)-1),
+	}
+}
+
+func (s *staticScheduler) next(routine int) (int, int, bool) {
+	if s.claimed[routine] || atomic.LoadInt32(&s.isStopped) == 1 {
+		return 0, 0, false
+	}
+
+	s.claimed[routine] = true
+	return s.bounds[routine], s.bounds[routine+1], true
+}
+
+func (s *staticScheduler) stop() {
+	atomic.StoreInt32(&s.isStopped, 1)
+}
+
+func (s *staticScheduler) stopped(i int) bool {
+	return atomic.LoadInt32(&s.isStopped) == 1
+}
+
+// MARK: Work-stealing scheduling
+
+// stealingScheduler types give each routine its own block of iterations and
+// let routines that run out of work steal from the others.
+type stealingScheduler struct {
+	// The remaining iterations of each routine.
+	blocks []stealingBlock
+
+	// Whether or not the scheduler has been stopped.
+	isStopped int32
+}
+
+// stealingBlock types represent the remaining iterations, [start, end), owned
+// by a routine.
+type stealingBlock struct {
+	mutex sync.Mutex
+	start int
+	end   int
+}
+
+// newStealingScheduler creates and returns a new work-stealing scheduler whose
+// r-th routine begins with the block [bounds[r], bounds[r+1]).
+func newStealingScheduler(bounds []int) *stealingScheduler {
+	s := &stealingScheduler{
+		blocks: make([]stealingBlock, len(bounds)-1),
+	}
+
+	for r := range s.blocks {
+		s.blocks[r].start = bounds[r]
+		s.blocks[r].end = bounds[r+1]
+	}
+
+	return s
+}
+
+func (s *stealingScheduler) next(routine int) (int, int, bool) {
+	for atomic.LoadInt32(&s.isStopped) == 0 {
+		block := &s.blocks[routine]
+		block.mutex.Lock()
+		if block.start < block.end {
+			i := block.start
+			block.start++
+			block.mutex.Unlock()
+			return i, i + 1, true
+		}
+		block.mutex.Unlock()
+
+		if !s.steal(routine) {
+			break
+		}
+	}
+
+	return 0, 0, false
+}
+
+func (s *stealingScheduler) stop() {
+	atomic.StoreInt32(&s.isStopped, 1)
+}
+
+func (s *stealingScheduler) stopped(i int) bool {
+	return atomic.LoadInt32(&s.isStopped) == 1
+}
+
+// steal moves half of the remaining iterations of the routine with the most
+// remaining work to the thief's block. It returns false if no routine has work
+// left to steal.
+func (s *stealingScheduler) steal(thief int) bool {
+	for {
+		victim := -1
+		remaining := 0
+		for r := range s.blocks {
+			if r == thief {
+				continue
+			}
+
+			block := &s.blocks[r]
+			block.mutex.Lock()
+			if n := block.end - block.start; n > remaining {
+				victim = r
+				remaining = n
+			}
+			block.mutex.Unlock()
+		}
+
+		if victim < 0 {
+			return false
+		}
+
+		block := &s.blocks[victim]
+		block.mutex.Lock()
+		n := block.end - block.start
+		if n <= 0 {
+			// The victim finished its work while we were looking.
+			block.mutex.Unlock()
+			continue
+		}
+
+		middle := block.end - (n+1)/2
+		start, end := middle, block.end
+		block.end = middle
+		block.mutex.Unlock()
+
+		own := &s.blocks[thief]
+		own.mutex.Lock()
+		own.start, own.end = start, end
+		own.mutex.Unlock()
+		return true
+	}
+}
@@ -0,0 +1,90 @@
+package parallel
+
+import (
+	"math"
+	"time"
+)
+
+// schedulingLatencyPercentile is the percentile of scheduling latencies
+// compared against a configuration's scheduling latency limit.
+const schedulingLatencyPercentile = 0.95
+
+// schedulingReporter types report the time goroutines spent runnable before
+// they ran between successive calls to their latency method. Latencies that
+// grow as routines are added show that the CPUs are oversubscribed.
+type schedulingReporter struct {
+	// The histogram's counts at the last measurement.
+	lastCounts []uint64
+}
+
+// MARK: Private methods
+
+// latency returns the percentile p of the scheduling latencies since the last
+// call to latency or reset. ok is false if no goroutines were scheduled since,
+// or if the runtime doesn't report scheduling latencies.
+func (r *schedulingReporter) latency(p float64) (time.Duration, bool) {
+	counts, buckets, ok := readSchedulingLatencies()
+	if !ok {
+		return 0, false
+	}
+
+	delta := make([]uint64, len(counts))
+	for i, count := range counts {
+		delta[i] = count
+		if i < len(r.lastCounts) {
+			delta[i] -= r.lastCounts[i]
+		}
+	}
+	r.lastCounts = counts
+
+	return histogramPercentile(delta, buckets, p)
+}
+
+// reset restarts the reporter's measurements.
+func (r *schedulingReporter) reset() {
+	r.lastCounts, _, _ = readSchedulingLatencies()
+}
+
+// MARK: Private functions
+
+// histogramPercentile returns the upper bound of the bucket of a histogram of
+// seconds containing percentile p. The buckets hold the boundaries of the
+// counts, so there is one more bucket than there are counts. ok is false if
+// the histogram is empty.
+func histogramPercentile(counts []uint64, buckets []float64, p float64) (time.Duration, bool) {
+	var total uint64
+	for _, count := range counts {
+		total += count
+	}
+
+	if total == 0 || len(buckets) != len(counts)+1 {
+		return 0, false
+	}
+
+	rank := uint64(math.Ceil(p * float64(total)))
+	var cumulative uint64
+	for i, count := range counts {
+		cumulative += count
+		if cumulative < rank {
+			continue
+		}
+
+		upper := buckets[i+1]
+		if math.IsInf(upper, 1) {
+			upper = buckets[i]
+		}
+		return time.Duration(upper * float64(time.Second)), true
+	}
+
+	return time.Duration(buckets[len(buckets)-1] * float64(time.Second)), true
+}
+
+// schedulingThrottledRoutines returns m limited to current routines if the
+// scheduling latency has exceeded limit, so that no routines are added while
+// goroutines wait for CPUs.
+func schedulingThrottledRoutines(m int, current int, latency time.Duration, limit time.Duration) int {
+	if limit > 0 && latency > limit {
+		return minInt(m, current)
+	}
+	return m
+}
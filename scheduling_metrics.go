@@ -0,0 +1,26 @@
+//go:build go1.17
+// +build go1.17
+
+package parallel
+
+import "runtime/metrics"
+
+// metricsSchedulingLatencies is the runtime metric of the time goroutines
+// spent runnable before running.
+const metricsSchedulingLatencies = "/sched/latencies:seconds"
+
+// MARK: Private functions
+
+// readSchedulingLatencies returns the counts and bucket boundaries, in
+// seconds, of the runtime's histogram of scheduling latencies.
+func readSchedulingLatencies() ([]uint64, []float64, bool) {
+	samples := []metrics.Sample{{Name: metricsSchedulingLatencies}}
+	metrics.Read(samples)
+
+	if samples[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil, nil, false
+	}
+
+	h := samples[0].Value.Float64Histogram()
+	return h.Counts, h.Buckets, true
+}
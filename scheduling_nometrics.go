@@ -0,0 +1,12 @@
+//go:build !go1.17
+// +build !go1.17
+
+package parallel
+
+// MARK: Private functions
+
+// readSchedulingLatencies reports that the runtime doesn't measure scheduling
+// latencies before Go 1.17.
+func readSchedulingLatencies() ([]uint64, []float64, bool) {
+	return nil, nil, false
+}
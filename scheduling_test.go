@@ -0,0 +1,99 @@
+package parallel
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestHistogramPercentile(t *testing.T) {
+	buckets := []float64{0.0, 0.001, 0.002, 0.004, math.Inf(1)}
+
+	if _, ok := histogramPercentile([]uint64{0, 0, 0, 0}, buckets, 0.95); ok {
+		t.Errorf("An empty histogram shouldn't have a percentile.")
+	}
+
+	if latency, _ := histogramPercentile([]uint64{90, 5, 5, 0}, buckets, 0.5); latency != time.Millisecond {
+		t.Errorf("Median, %s, should be 1ms.", latency)
+	}
+
+	if latency, _ := histogramPercentile([]uint64{90, 5, 5, 0}, buckets, 0.95); latency != 2*time.Millisecond {
+		t.Errorf("95th percentile, %s, should be 2ms.", latency)
+	}
+
+	if latency, _ := histogramPercentile([]uint64{0, 0, 0, 10}, buckets, 0.95); latency != 4*time.Millisecond {
+		t.Errorf("95th percentile in the last bucket, %s, should be its lower bound, 4ms.", latency)
+	}
+}
+
+func TestSchedulingThrottledRoutines(t *testing.T) {
+	if n := schedulingThrottledRoutines(8, 4, time.Millisecond, 0); n != 8 {
+		t.Errorf("Routines, %d, should be 8 without a limit.", n)
+	}
+
+	if n := schedulingThrottledRoutines(8, 4, time.Millisecond, 2*time.Millisecond); n != 8 {
+		t.Errorf("Routines, %d, should be 8 below the limit.", n)
+	}
+
+	if n := schedulingThrottledRoutines(8, 4, 3*time.Millisecond, 2*time.Millisecond); n != 4 {
+		t.Errorf("Routines, %d, should be held at 4 above the limit.", n)
+	}
+
+	if n := schedulingThrottledRoutines(2, 4, 3*time.Millisecond, 2*time.Millisecond); n != 2 {
+		t.Errorf("Routines, %d, should still be removed above the limit.", n)
+	}
+}
+
+func TestSchedulingReporterLatency(t *testing.T) {
+	if _, _, reported := readSchedulingLatencies(); !reported {
+		t.Skip("The runtime doesn't report scheduling latencies.")
+	}
+
+	var r schedulingReporter
+	r.reset()
+
+	// The runtime only samples some scheduling events, so keep scheduling
+	// goroutines until one is recorded.
+	var latency time.Duration
+	ok := false
+	for deadline := time.Now().Add(time.Second); !ok && time.Now().Before(deadline); {
+		var group sync.WaitGroup
+		for i := 0; i < 4*runtime.GOMAXPROCS(0); i++ {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				time.Sleep(time.Millisecond)
+			}()
+		}
+		group.Wait()
+
+		latency, ok = r.latency(schedulingLatencyPercentile)
+	}
+
+	if !ok || latency < 0 {
+		t.Errorf("Latency, %s, should be measured after scheduling goroutines.", latency)
+	}
+}
+
+func TestVariableProcessSchedulingLatencyLimit(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	c.SchedulingLatencyLimit = time.Nanosecond
+	p := NewVariableProcess(time.Millisecond, 1, 4*runtime.NumCPU(), c, false)
+
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(100 * time.Microsecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package parallel
+
+import "sync"
+
+// shardedCounter types hand out an execution's iterations from several
+// independently locked ranges, so that hundreds of routines don't contend on a
+// single shared counter. Each routine claims iterations from its own shard.
+// Once a shard is empty, the routine claiming from it steals half of the
+// remaining iterations of the fullest shard, and the shards are rebalanced the
+// same way each time the process optimizes.
+type shardedCounter struct {
+	// The shards the iterations are divided between.
+	shards []counterShard
+
+	// The total number of iterations.
+	iterations int
+}
+
+// counterShard types hold the iterations [next, end) that remain in a shard.
+// They are padded so that neighbouring shards don't share a cache line.
+type counterShard struct {
+	mutex sync.Mutex
+	next  int
+	end   int
+	_     cacheLinePad
+}
+
+// MARK: Initializers
+
+// newShardedCounter creates and returns a new counter dividing iterations into
+// n contiguous shards of equal size. There are never more shards than
+// iterations.
+func newShardedCounter(iterations int, n int) *shardedCounter {
+	n = maxInt(minInt(n, iterations), 1)
+	c := &shardedCounter{
+		shards:     make([]counterShard, n),
+		iterations: iterations,
+	}
+
+	for k := range c.shards {
+		c.shards[k].next = int(int64(k) * int64(iterations) / int64(n))
+		c.shards[k].end = int(int64(k+1) * int64(iterations) / int64(n))
+	}
+
+	return c
+}
+
+// MARK: Private methods
+
+// claim claims an iteration for the routine-th routine and returns false once
+// no iterations remain.
+func (c *shardedCounter) claim(routine int) (int, bool) {
+	k := routine % len(c.shards)
+	s := &c.shards[k]
+	for {
+		s.mutex.Lock()
+		if s.next < s.end {
+			i := s.next
+			s.next++
+			s.mutex.Unlock()
+			return i, true
+		}
+		s.mutex.Unlock()
+
+		if !c.steal(k) {
+			return 0, false
+		}
+	}
+}
+
+// steal moves half of the remaining iterations of the fullest shard into the
+// k-th shard and returns false if no shard has iterations remaining.
+func (c *shardedCounter) steal(k int) bool {
+	for {
+		victim, remaining := -1, 0
+		for v := range c.shards {
+			if r := c.shards[v].remaining(); r > remaining {
+				victim, remaining = v, r
+			}
+		}
+		if victim < 0 {
+			return false
+		}
+		if c.move(victim, k) {
+			return true
+		}
+	}
+}
+
+// move moves half of the remaining iterations of the from-th shard into the
+// empty to-th shard and returns whether the to-th shard has iterations
+// remaining, which it may already have been given by another routine. The
+// shards are locked in order so that concurrent moves can't deadlock and
+// iterations are never outside of a shard.
+func (c *shardedCounter) move(from int, to int) bool {
+	if from == to {
+		return false
+	}
+
+	first, second := &c.shards[from], &c.shards[to]
+	if to < from {
+		first, second = second, first
+	}
+	first.mutex.Lock()
+	defer first.mutex.Unlock()
+	second.mutex.Lock()
+	defer second.mutex.Unlock()
+
+	source, destination := &c.shards[from], &c.shards[to]
+	if destination.next < destination.end {
+		return true
+	}
+
+	remaining := source.end - source.next
+	if remaining <= 0 {
+		return false
+	}
+
+	mid := source.end - (remaining+1)/2
+	destination.next, destination.end = mid, source.end
+	source.end = mid
+	return true
+}
+
+// rebalance refills each empty shard with half of the remaining iterations of
+// the fullest shard, so that routines claiming from empty shards don't all
+// steal at once.
+func (c *shardedCounter) rebalance() {
+	for k := range c.shards {
+		if c.shards[k].remaining() == 0 && !c.steal(k) {
+			return
+		}
+	}
+}
+
+// started returns the number of iterations that have been claimed. While
+// iterations are being stolen the count is approximate.
+func (c *shardedCounter) started() int {
+	remaining := 0
+	for k := range c.shards {
+		remaining += c.shards[k].remaining()
+	}
+	return c.iterations - remaining
+}
+
+// stop discards the iterations remaining in every shard.
+func (c *shardedCounter) stop() {
+	for k := range c.shards {
+		s := &c.shards[k]
+		s.mutex.Lock()
+		s.next = s.end
+		s.mutex.Unlock()
+	}
+}
+
+// remaining returns the number of iterations remaining in the shard.
+func (s *counterShard) remaining() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.end - s.next
+}
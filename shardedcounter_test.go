@@ -0,0 +1,90 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestShardedCounterCompleteness(t *testing.T) {
+	counts := make([]int32, 100003)
+	c := newShardedCounter(len(counts), 8)
+
+	var group sync.WaitGroup
+	group.Add(64)
+	for r := 0; r < 64; r++ {
+		go func(routine int) {
+			defer group.Done()
+			for i, ok := c.claim(routine); ok; i, ok = c.claim(routine) {
+				counts[i]++
+				if i%1000 == 0 {
+					c.rebalance()
+				}
+			}
+		}(r)
+	}
+	group.Wait()
+
+	for i, count := range counts {
+		if count != 1 {
+			t.Errorf("Iteration %d was claimed %d times.", i, count)
+			break
+		}
+	}
+	if n := c.started(); n != len(counts) {
+		t.Errorf("Started iterations, %d, should be %d.", n, len(counts))
+	}
+}
+
+func TestShardedCounterSteal(t *testing.T) {
+	c := newShardedCounter(10, 2)
+	for i := 0; i < 5; i++ {
+		if n, ok := c.claim(0); !ok || n != i {
+			t.Fatalf("Claimed iteration, %d, should be %d.", n, i)
+		}
+	}
+
+	if n, ok := c.claim(0); !ok || n != 7 {
+		t.Errorf("The empty shard should steal the upper half of the other, not iteration %d.", n)
+	}
+	if n, ok := c.claim(1); !ok || n != 5 {
+		t.Errorf("The other shard should keep its lower half, not iteration %d.", n)
+	}
+}
+
+func TestShardedCounterStop(t *testing.T) {
+	c := newShardedCounter(100, 4)
+	c.claim(0)
+	c.stop()
+
+	if _, ok := c.claim(1); ok {
+		t.Error("No iterations should be claimed after stopping.")
+	}
+	if n := c.started(); n != 100 {
+		t.Errorf("Started iterations, %d, should be 100 after stopping.", n)
+	}
+}
+
+func TestVariableProcessCounterShards(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 16, 256, c, false)
+	p.SetCounterShards(4)
+	if p.CounterShards() != 4 {
+		t.Errorf("Counter shards, %d, should be 4.", p.CounterShards())
+	}
+
+	v := make([]int32, 5000)
+	p.Execute(len(v), func(i int) {
+		v[i]++
+		time.Sleep(10 * time.Microsecond)
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
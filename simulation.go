@@ -0,0 +1,47 @@
+package parallel
+
+import "runtime"
+
+// MARK: Public functions
+
+// SimulateController replays a recorded CPU usage signal, such as the usage of
+// a variable process' controller history, through a controller using
+// configuration and returns the controller's sample at each step. Gains can
+// then be tuned offline against traces of real workloads.
+//
+// The replay is open loop: the recorded usage doesn't respond to the simulated
+// number of routines, so the trajectory shows how the controller would react
+// to the trace rather than how the workload would respond to the controller.
+// Like an execution, the simulation starts with and never drops below one
+// routine. It applies the configuration's usage smoothing, deadband and routine
+// delta limit, but not its memory limit. If cpuCount is less than one,
+// runtime.GOMAXPROCS(0) is used.
+func SimulateController(configuration *ControllerConfiguration, usage []float64, cpuCount int, maxRoutines int) []ControllerSample {
+	if configuration == nil {
+		misuse("SimulateController called with a nil configuration")
+	}
+	checkRoutines("SimulateController", "maxRoutines", maxRoutines)
+
+	if cpuCount < 1 {
+		cpuCount = runtime.GOMAXPROCS(0)
+	}
+
+	c := NewPIDController(configuration, float64(cpuCount))
+
+	var smoother usageSmoother
+	samples := make([]ControllerSample, len(usage))
+	routines := 1
+	for i, sample := range usage {
+		sample = smoother.next(sample, configuration.UsageSmoothing)
+		u, e := c.Next(sample)
+		routines = maxInt(1, controlledRoutines(u, routines, maxRoutines, configuration.Deadband, configuration.MaxRoutinesDeltaPerTick))
+		samples[i] = ControllerSample{
+			Usage:    sample,
+			Error:    e,
+			Output:   u,
+			Routines: float64(routines),
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,77 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestSimulateController(t *testing.T) {
+	c := NewControllerConfiguration(0.0, 1.0, 0.0, 1.0, 1.0)
+	usage := []float64{0.0, 1.0, 2.0, 3.0, 4.0, 4.0}
+	samples := SimulateController(c, usage, 4, 16)
+
+	if len(samples) != len(usage) {
+		t.Fatalf("Sample count, %d, should be %d.", len(samples), len(usage))
+	}
+
+	// The integral accumulates 1, 0.75, 0.5, 0.25, 0 and 0.
+	expected := []float64{1.0, 1.75, 2.25, 2.5, 2.5, 2.5}
+	for i, sample := range samples {
+		if math.Abs(sample.Output-expected[i]) > 1e-9 {
+			t.Errorf("Output %d, %f, should be %f.", i, sample.Output, expected[i])
+		}
+
+		if sample.Routines != math.Ceil(expected[i]) {
+			t.Errorf("Routines %d, %f, should be %f.", i, sample.Routines, math.Ceil(expected[i]))
+		}
+
+		if sample.Usage != usage[i] {
+			t.Errorf("Usage %d, %f, should be %f.", i, sample.Usage, usage[i])
+		}
+	}
+}
+
+func TestSimulateControllerLimits(t *testing.T) {
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	c.MaxRoutinesDeltaPerTick = 2
+	samples := SimulateController(c, []float64{0.0, 0.0, 0.0, 0.0, 0.0, 0.0}, 4, 8)
+
+	expected := []float64{3.0, 5.0, 7.0, 8.0, 8.0, 8.0}
+	for i, sample := range samples {
+		if sample.Routines != expected[i] {
+			t.Errorf("Routines %d, %f, should be %f.", i, sample.Routines, expected[i])
+		}
+	}
+}
+
+func TestSimulateControllerIsDeterministic(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.5, 1.0, 0.5, 1.0)
+	usage := []float64{0.5, 1.2, 2.8, 3.6, 3.9, 3.7, 3.95}
+	a := SimulateController(c, usage, 4, 32)
+	b := SimulateController(c.Copy(), usage, 4, 32)
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("Sample %d, %+v, should equal, %+v.", i, a[i], b[i])
+		}
+	}
+}
+
+func TestSimulateControllerUsageSmoothing(t *testing.T) {
+	c := NewControllerConfiguration(1.0, 0.0, 0.0, 1.0, 1.0)
+	usage := []float64{4.0, 0.0, 4.0}
+	raw := SimulateController(c, usage, 4, 8)
+
+	c.UsageSmoothing = 0.75
+	smoothed := SimulateController(c, usage, 4, 8)
+
+	if smoothed[1].Usage != 3.0 {
+		t.Errorf("Smoothed usage, %f, should be 3.", smoothed[1].Usage)
+	}
+
+	if math.Abs(smoothed[1].Error) >= math.Abs(raw[1].Error) {
+		t.Errorf("Error with smoothing, %f, should be smaller than without, %f.", smoothed[1].Error, raw[1].Error)
+	}
+}
@@ -0,0 +1,151 @@
+package parallel
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSoakGoroutineGrowth is the number of goroutines a soak test allows
+// the program to gain when its configuration doesn't specify a limit.
+const defaultSoakGoroutineGrowth = 4
+
+// defaultSoakMemoryGrowth is the number of bytes of live heap a soak test
+// allows the program to gain when its configuration doesn't specify a limit.
+const defaultSoakMemoryGrowth = 16 << 20
+
+// SoakConfiguration types describe a soak test.
+type SoakConfiguration struct {
+	// The process to test.
+	Process Process
+
+	// The number of iterations of each run.
+	Iterations int
+
+	// The operation to execute. It must not stop the process.
+	Operation Operation
+
+	// How long to keep executing runs.
+	Duration time.Duration
+
+	// The number of goroutines the program may gain over the test. Zero uses
+	// a default of 4.
+	MaxGoroutineGrowth int
+
+	// The number of bytes of live heap the program may gain over the test.
+	// Zero uses a default of 16 MiB.
+	MaxMemoryGrowth int64
+}
+
+// SoakReport types describe the result of a soak test.
+type SoakReport struct {
+	// The number of runs executed.
+	Runs int
+
+	// How long the test ran for.
+	Duration time.Duration
+
+	// The number of goroutines and bytes of live heap the program gained.
+	GoroutineGrowth int
+	MemoryGrowth    int64
+
+	// Descriptions of the invariants that were violated.
+	Violations []string
+}
+
+// progressReporter types report the progress of their executions.
+type progressReporter interface {
+	Progress() Progress
+}
+
+// MARK: Public functions
+
+// Soak executes the configured workload repeatedly for the configured duration
+// and reports whether the process behaved. Every run must execute each
+// iteration exactly once and, for processes that report progress, finish
+// without a discrepancy between claimed and completed iterations. Over the
+// whole test, the number of goroutines and the live heap must not grow by more
+// than the configured limits.
+func Soak(configuration SoakConfiguration) SoakReport {
+	if configuration.Process == nil {
+		misuse("Soak called with a nil process")
+	}
+	checkIterations("Soak", configuration.Iterations)
+	checkOperation("Soak", configuration.Operation == nil)
+
+	maxGoroutineGrowth := configuration.MaxGoroutineGrowth
+	if maxGoroutineGrowth == 0 {
+		maxGoroutineGrowth = defaultSoakGoroutineGrowth
+	}
+
+	maxMemoryGrowth := configuration.MaxMemoryGrowth
+	if maxMemoryGrowth == 0 {
+		maxMemoryGrowth = defaultSoakMemoryGrowth
+	}
+
+	var report SoakReport
+	goroutines, memory := soakSample()
+	counts := make([]int32, configuration.Iterations)
+	start := time.Now()
+
+	for report.Runs == 0 || time.Since(start) < configuration.Duration {
+		for i := range counts {
+			counts[i] = 0
+		}
+
+		configuration.Process.Execute(configuration.Iterations, func(i int) {
+			atomic.AddInt32(&counts[i], 1)
+			configuration.Operation(i)
+		})
+		report.Runs++
+
+		for i, count := range counts {
+			if count != 1 {
+				report.Violations = append(report.Violations, fmt.Sprintf("run %d executed iteration %d %d times", report.Runs, i, count))
+				break
+			}
+		}
+
+		if reporter, ok := configuration.Process.(progressReporter); ok {
+			if progress := reporter.Progress(); progress.Discrepancy() != 0 || progress.Completed != int64(configuration.Iterations) {
+				report.Violations = append(report.Violations, fmt.Sprintf("run %d finished with progress %+v", report.Runs, progress))
+			}
+		}
+	}
+
+	report.Duration = time.Since(start)
+	finalGoroutines, finalMemory := soakSample()
+	report.GoroutineGrowth = finalGoroutines - goroutines
+	report.MemoryGrowth = finalMemory - memory
+
+	if report.GoroutineGrowth > maxGoroutineGrowth {
+		report.Violations = append(report.Violations, fmt.Sprintf("goroutines grew by %d over %d runs", report.GoroutineGrowth, report.Runs))
+	}
+
+	if report.MemoryGrowth > maxMemoryGrowth {
+		report.Violations = append(report.Violations, fmt.Sprintf("live heap grew by %d bytes over %d runs", report.MemoryGrowth, report.Runs))
+	}
+
+	return report
+}
+
+// MARK: Public methods
+
+// Passed returns whether or not the soak test passed.
+func (r SoakReport) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// MARK: Private functions
+
+// soakSample returns the number of goroutines and bytes of live heap after
+// letting exiting goroutines finish and collecting garbage.
+func soakSample() (int, int64) {
+	time.Sleep(10 * time.Millisecond)
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return runtime.NumGoroutine(), int64(stats.HeapAlloc)
+}
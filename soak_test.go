@@ -0,0 +1,40 @@
+package parallel
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestSoakPasses(t *testing.T) {
+	report := Soak(SoakConfiguration{
+		Process:    NewFixedProcess(4),
+		Iterations: 1000,
+		Operation:  func(i int) {},
+		Duration:   50 * time.Millisecond,
+	})
+
+	if !report.Passed() || report.Runs < 2 {
+		t.Errorf("Report, %+v, should pass after several runs.", report)
+	}
+}
+
+func TestSoakDetectsGoroutineGrowth(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	report := Soak(SoakConfiguration{
+		Process:    NewFixedProcess(2),
+		Iterations: 10,
+		Operation: func(i int) {
+			go func() { <-done }()
+		},
+		Duration: 20 * time.Millisecond,
+	})
+
+	if report.Passed() || !strings.Contains(report.Violations[0], "goroutines grew") {
+		t.Errorf("Report, %+v, should fail with goroutine growth.", report)
+	}
+}
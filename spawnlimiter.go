@@ -0,0 +1,85 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// spawnLimiter types limit the rate at which goroutines are spawned with a
+// token bucket that holds up to one second of tokens.
+type spawnLimiter struct {
+	mutex sync.Mutex
+
+	// The number of goroutines that may be spawned per second, or zero if
+	// spawning is unlimited.
+	rate float64
+
+	// The number of goroutines that may currently be spawned.
+	tokens float64
+
+	// The last time the bucket was refilled.
+	last time.Time
+}
+
+// The limiter shared by every adaptive process in the program.
+var globalSpawnLimiter spawnLimiter
+
+// MARK: Public functions
+
+// SetMaxSpawnRate sets the maximum number of goroutines per second that
+// adaptive processes, such as VariableProcess, may spawn across the whole
+// program. It acts as a safety valve against controller misconfiguration or
+// feedback that would otherwise cause a goroutine explosion. Values less than
+// one remove the limit, which is the default.
+func SetMaxSpawnRate(perSecond int) {
+	globalSpawnLimiter.setRate(perSecond)
+}
+
+// MaxSpawnRate returns the maximum number of goroutines per second that
+// adaptive processes may spawn, or zero if spawning is unlimited.
+func MaxSpawnRate() int {
+	globalSpawnLimiter.mutex.Lock()
+	defer globalSpawnLimiter.mutex.Unlock()
+	return int(globalSpawnLimiter.rate)
+}
+
+// MARK: Private methods
+
+// setRate sets the limiter's rate and fills its bucket.
+func (l *spawnLimiter) setRate(perSecond int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if perSecond < 1 {
+		perSecond = 0
+	}
+
+	l.rate = float64(perSecond)
+	l.tokens = l.rate
+	l.last = time.Now()
+}
+
+// take requests permission to spawn n goroutines and returns the number that
+// may be spawned.
+func (l *spawnLimiter) take(n int) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.rate == 0 || n <= 0 {
+		return n
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+
+	if float64(n) > l.tokens {
+		n = int(l.tokens)
+	}
+
+	l.tokens -= float64(n)
+	return n
+}
@@ -0,0 +1,47 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestSpawnLimiterUnlimited(t *testing.T) {
+	var l spawnLimiter
+	if n := l.take(1000); n != 1000 {
+		t.Errorf("Granted count, %d, should be 1000.", n)
+	}
+}
+
+func TestSpawnLimiterRate(t *testing.T) {
+	var l spawnLimiter
+	l.setRate(10)
+
+	if n := l.take(25); n != 10 {
+		t.Errorf("Granted count, %d, should be limited to the burst of 10.", n)
+	}
+
+	if n := l.take(5); n != 0 {
+		t.Errorf("Granted count, %d, should be 0 with an empty bucket.", n)
+	}
+
+	l.last = l.last.Add(-500 * time.Millisecond)
+	if n := l.take(25); n != 5 {
+		t.Errorf("Granted count, %d, should be 5 after half a second.", n)
+	}
+}
+
+func TestMaxSpawnRate(t *testing.T) {
+	defer SetMaxSpawnRate(0)
+
+	SetMaxSpawnRate(100)
+	if MaxSpawnRate() != 100 {
+		t.Errorf("Max spawn rate, %d, should be 100.", MaxSpawnRate())
+	}
+
+	SetMaxSpawnRate(-1)
+	if MaxSpawnRate() != 0 {
+		t.Errorf("Max spawn rate, %d, should be 0.", MaxSpawnRate())
+	}
+}
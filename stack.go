@@ -0,0 +1,38 @@
+package parallel
+
+// stackFrameSize is the size in bytes of the frames growStack pushes.
+const stackFrameSize = 1024
+
+// stackSink keeps the compiler from eliminating the frames pushed by
+// growStack.
+var stackSink byte
+
+// MARK: Private functions
+
+// growStack grows the calling goroutine's stack to at least the specified
+// number of bytes.
+//
+// A goroutine starts with a small stack that the runtime copies to one twice
+// its size whenever it overflows. An operation that recurses deeply pays for
+// each of those copies on every new goroutine. Pushing frames worth n bytes
+// before the first iteration grows the stack once, up front, and the stack
+// keeps its size for the rest of the routine unless the garbage collector
+// finds it mostly unused and shrinks it.
+func growStack(n int) {
+	if n > 0 {
+		pushStackFrames(n / stackFrameSize)
+	}
+}
+
+// pushStackFrames recursively pushes n frames of stackFrameSize bytes.
+//
+//go:noinline
+func pushStackFrames(n int) byte {
+	var frame [stackFrameSize]byte
+	frame[n%stackFrameSize] = byte(n)
+	if n > 0 {
+		frame[0] = pushStackFrames(n - 1)
+	}
+	stackSink = frame[0]
+	return frame[n%stackFrameSize]
+}
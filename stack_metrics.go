@@ -0,0 +1,25 @@
+//go:build go1.16
+// +build go1.16
+
+package parallel
+
+import "runtime/metrics"
+
+// metricsStacks is the runtime metric of the heap memory reserved for
+// goroutine stacks.
+const metricsStacks = "/memory/classes/heap/stacks:bytes"
+
+// MARK: Private functions
+
+// stackInuse returns the number of bytes of stack spans in use. Reading the
+// runtime's metrics doesn't stop the world, so the stacks are always measured
+// and memStats is ignored.
+func stackInuse(memStats bool) (int64, bool) {
+	samples := []metrics.Sample{{Name: metricsStacks}}
+	metrics.Read(samples)
+
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0, false
+	}
+	return int64(samples[0].Value.Uint64()), true
+}
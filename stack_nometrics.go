@@ -0,0 +1,21 @@
+//go:build !go1.16
+// +build !go1.16
+
+package parallel
+
+import "runtime"
+
+// MARK: Private functions
+
+// stackInuse returns the number of bytes of stack spans in use if memStats is
+// true. Before Go 1.16 the runtime only reports them through ReadMemStats,
+// which stops the world, so they aren't measured otherwise.
+func stackInuse(memStats bool) (int64, bool) {
+	if !memStats {
+		return 0, false
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.StackInuse), true
+}
@@ -0,0 +1,77 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestGrowStack(t *testing.T) {
+	done := make(chan int)
+	go func() {
+		growStack(1 << 20)
+		done <- recurse(1000)
+	}()
+
+	if depth := <-done; depth != 1000 {
+		t.Errorf("Depth, %d, should be 1000.", depth)
+	}
+}
+
+func TestFixedProcessStackHint(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.SetStackHint(256 << 10)
+
+	v := make([]int, 100)
+	p.Execute(len(v), func(i int) {
+		v[i] = recurse(2000)
+	})
+
+	for i, value := range v {
+		if value != 2000 {
+			t.Errorf("Index %d has value %d.", i, value)
+			break
+		}
+	}
+
+	if s := p.Summary(); s.Configuration.StackHint != 256<<10 {
+		t.Errorf("Summary stack hint, %d, should be %d.", s.Configuration.StackHint, 256<<10)
+	}
+}
+
+func TestVariableProcessStackHint(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetStackHint(256 << 10)
+
+	v := make([]int, 100)
+	p.Execute(len(v), func(i int) {
+		v[i] = recurse(2000)
+	})
+
+	for i, value := range v {
+		if value != 2000 {
+			t.Errorf("Index %d has value %d.", i, value)
+			break
+		}
+	}
+
+	if p.StackHint() != 256<<10 {
+		t.Errorf("Stack hint, %d, should be %d.", p.StackHint(), 256<<10)
+	}
+}
+
+// MARK: Helpers
+
+// recurse recurses to the specified depth and returns the depth reached.
+//
+//go:noinline
+func recurse(depth int) int {
+	var frame [64]byte
+	frame[depth%len(frame)] = 1
+	if depth == 0 {
+		return int(frame[0]) - 1
+	}
+	return recurse(depth-1) + int(frame[depth%len(frame)])
+}
@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// ProcessStats types are snapshots of a process' current or last execution,
+// for dashboards and tests that poll a process.
+type ProcessStats struct {
+	// The number of routines the process is using.
+	Routines int `json:"routines"`
+
+	// The number of iterations that have completed and the total number of
+	// iterations in the execution.
+	Completed  int64 `json:"completed"`
+	Iterations int64 `json:"iterations"`
+
+	// The time the execution has been running for, or ran for if it has
+	// finished.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// The controller's signals at its last optimization, or nil if the
+	// process has no controller or hasn't optimized.
+	Controller *ControllerSample `json:"controller,omitempty"`
+}
+
+// executionTimer types time a process' executions.
+type executionTimer struct {
+	mutex    sync.Mutex
+	started  time.Time
+	finished time.Time
+}
+
+// MARK: Private methods
+
+// start records that an execution started.
+func (t *executionTimer) start() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.started = time.Now()
+	t.finished = time.Time{}
+}
+
+// stop records that the execution finished.
+func (t *executionTimer) stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.finished = time.Now()
+}
+
+// elapsed returns the duration of the current or last execution, or zero if
+// nothing has executed.
+func (t *executionTimer) elapsed() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.started.IsZero() {
+		return 0
+	} else if t.finished.IsZero() {
+		return time.Since(t.started)
+	}
+	return t.finished.Sub(t.started)
+}
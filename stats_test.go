@@ -0,0 +1,74 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestProcessStats(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	processes := []struct {
+		name    string
+		process Process
+	}{
+		{"FixedProcess", NewFixedProcess(2)},
+		{"VariableProcess", NewVariableProcess(time.Millisecond, 1, 2, c, false)},
+		{"ForkJoinProcess", NewForkJoinProcess(2)},
+		{"BatchProcess", NewBatchProcess(NewFixedProcess(2), 7)},
+		{"PriorityProcess", NewPriorityProcess(NewFixedProcess(2), func(i int) int { return -i })},
+	}
+
+	for _, p := range processes {
+		if stats := p.process.Stats(); stats.Elapsed != 0 || stats.Completed != 0 {
+			t.Errorf("The %s's stats, %+v, should be empty before it executes.", p.name, stats)
+		}
+
+		p.process.Execute(100, func(i int) {
+			time.Sleep(100 * time.Microsecond)
+		})
+
+		stats := p.process.Stats()
+		if stats.Completed != 100 || stats.Iterations != 100 {
+			t.Errorf("The %s's stats, %+v, should have completed 100 of 100 iterations.", p.name, stats)
+		}
+		if stats.Elapsed <= 0 {
+			t.Errorf("The %s's elapsed time, %s, should be positive.", p.name, stats.Elapsed)
+		}
+		if stats.Elapsed != p.process.Stats().Elapsed {
+			t.Errorf("The %s's elapsed time shouldn't change after it finishes.", p.name)
+		}
+	}
+}
+
+func TestVariableProcessStatsController(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	var running ProcessStats
+	p.Execute(100, func(i int) {
+		if i == 50 {
+			running = p.Stats()
+		}
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	if running.Routines < 1 {
+		t.Errorf("Routines, %d, should be at least 1 while executing.", running.Routines)
+	}
+	if running.Iterations != 100 {
+		t.Errorf("Iterations, %d, should be 100.", running.Iterations)
+	}
+
+	stats := p.Stats()
+	if stats.Controller == nil {
+		t.Fatalf("The stats should contain the controller's last sample.")
+	}
+	if stats.Controller.Routines < 1.0 {
+		t.Errorf("The controller's sample, %+v, should call for at least one routine.", stats.Controller)
+	}
+	if NewFixedProcess(1).Stats().Controller != nil {
+		t.Errorf("Processes without a controller shouldn't report one.")
+	}
+}
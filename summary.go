@@ -0,0 +1,381 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// maxSummaryErrors is the maximum number of error messages kept in a run
+// summary.
+const maxSummaryErrors = 10
+
+// RunSummary types describe a single execution of a process. Summaries are
+// self-contained and can be written to a JSON file to attach to CI jobs or
+// experiment trackers.
+type RunSummary struct {
+	// The configuration of the process that executed.
+	Configuration SummaryConfiguration `json:"configuration"`
+
+	// Statistics about the execution.
+	Stats SummaryStats `json:"stats"`
+
+	// The controller's samples at each optimization. Only variable processes
+	// that probe their controller record a history.
+	ControllerHistory []ControllerSample `json:"controllerHistory,omitempty"`
+
+	// The errors the execution encountered.
+	Errors ErrorSummary `json:"errors"`
+}
+
+// SummaryConfiguration types describe the configuration of the process and
+// host of an execution.
+type SummaryConfiguration struct {
+	// The type of the process, such as FixedProcess.
+	Process string `json:"process"`
+
+	// The number of routines the process was created with. For variable
+	// processes this is the initial number of routines.
+	Routines int `json:"routines"`
+
+	// The maximum number of routines of a variable process.
+	MaxRoutines int `json:"maxRoutines,omitempty"`
+
+	// The schedule of a fixed process.
+	Schedule string `json:"schedule,omitempty"`
+
+	// The optimization interval of a variable process.
+	OptimizationInterval time.Duration `json:"optimizationInterval,omitempty"`
+
+	// The number of bytes each routine's stack was grown to before executing.
+	StackHint int `json:"stackHint,omitempty"`
+
+	// The optimizer of a variable process.
+	Optimizer string `json:"optimizer,omitempty"`
+
+	// The controller configuration of a variable process.
+	Controller *ControllerConfiguration `json:"controller,omitempty"`
+
+	// The host the execution ran on.
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUCount  int    `json:"cpuCount"`
+	MaxProcs  int    `json:"gomaxprocs"`
+	GoVersion string `json:"goVersion"`
+}
+
+// SummaryStats types contain statistics about an execution.
+type SummaryStats struct {
+	// The progress of the execution when it finished.
+	Progress Progress `json:"progress"`
+
+	// The times the execution started and finished.
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+
+	// The execution's duration.
+	Duration time.Duration `json:"duration"`
+
+	// The number of iterations completed per second.
+	Throughput float64 `json:"throughput"`
+
+	// The largest number of routines that executed at once.
+	PeakRoutines int `json:"peakRoutines"`
+
+	// The iterations each routine completed and how long it was busy.
+	Routines []RoutineStats `json:"routines,omitempty"`
+
+	// The number of controller samples a variable process dropped instead of
+	// publishing them to its metrics sinks, and the time its optimizer spent
+	// handing samples to the sinks' publisher.
+	ProbeSamplesDropped int64         `json:"probeSamplesDropped,omitempty"`
+	ProbeOverhead       time.Duration `json:"probeOverhead,omitempty"`
+
+	// The number of bytes the runtime's goroutine stacks grew by during the
+	// execution. Deep operations that repeatedly outgrow their routines'
+	// stacks show up here, and setting a stack hint on the process reduces
+	// the number of times the stacks are copied. Before Go 1.16 the stacks
+	// are only measured when the process has a stack hint.
+	StackGrowth int64 `json:"stackGrowth"`
+
+	// The time the garbage collector paused the program during the execution,
+	// and the fraction of the program's available CPU time it used. Before Go
+	// 1.20 the collector is only measured by variable processes that subtract
+	// its usage or processes with a stack hint.
+	GCPause       time.Duration `json:"gcPause"`
+	GCCPUFraction float64       `json:"gcCPUFraction"`
+}
+
+// ControllerSample types contain the signals of a variable process'
+// controller at a single optimization.
+type ControllerSample struct {
+	Usage    float64 `json:"usage"`
+	Error    float64 `json:"error"`
+	Output   float64 `json:"output"`
+	Routines float64 `json:"routines"`
+}
+
+// ErrorSummary types summarize the errors of an execution.
+type ErrorSummary struct {
+	// The number of errors.
+	Count int `json:"count"`
+
+	// The messages of the first errors.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// SummaryDifference types describe a field whose value differs between two
+// run summaries.
+type SummaryDifference struct {
+	// The name of the field.
+	Field string
+
+	// The field's values in the first and second summary.
+	A string
+	B string
+
+	// The relative change from A to B for numeric fields, or NaN for fields
+	// that aren't numeric or whose A value is zero.
+	Change float64
+}
+
+// executionRecord types record the information about a process' last
+// execution that a run summary needs.
+type executionRecord struct {
+	// The times the execution started and finished.
+	started  time.Time
+	finished time.Time
+
+	// The largest number of routines that executed at once.
+	peakRoutines int64
+
+	// The error returned by the execution, if any.
+	err error
+
+	// The number of dropped probe samples and the time spent publishing them.
+	probesDropped int64
+	probeOverhead time.Duration
+
+	// The bytes of stack in use when the execution started, whether they
+	// were measured, and how many more were in use when it finished.
+	stackStart    int64
+	stackMeasured bool
+	stackGrowth   int64
+
+	// The garbage collector's totals when the execution started, whether
+	// they were measured, and its pause time and CPU fraction over the
+	// execution.
+	gcStart       gcSample
+	gcMeasured    bool
+	gcPause       time.Duration
+	gcCPUFraction float64
+}
+
+// MARK: Public functions
+
+// ReadSummary reads the run summary in the JSON file at path.
+func ReadSummary(path string) (*RunSummary, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RunSummary{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// DiffSummaries returns the configuration and statistics fields whose values
+// differ between a and b.
+func DiffSummaries(a *RunSummary, b *RunSummary) []SummaryDifference {
+	var differences []SummaryDifference
+	add := func(field string, x interface{}, y interface{}) {
+		if x == y {
+			return
+		}
+
+		d := SummaryDifference{
+			Field:  field,
+			A:      fmt.Sprint(x),
+			B:      fmt.Sprint(y),
+			Change: math.NaN(),
+		}
+		if fx, ok := summaryNumber(x); ok && fx != 0.0 {
+			fy, _ := summaryNumber(y)
+			d.Change = (fy - fx) / fx
+		}
+		differences = append(differences, d)
+	}
+
+	ac, bc := a.Configuration, b.Configuration
+	add("configuration.process", ac.Process, bc.Process)
+	add("configuration.routines", ac.Routines, bc.Routines)
+	add("configuration.maxRoutines", ac.MaxRoutines, bc.MaxRoutines)
+	add("configuration.schedule", ac.Schedule, bc.Schedule)
+	add("configuration.optimizationInterval", ac.OptimizationInterval, bc.OptimizationInterval)
+	add("configuration.stackHint", ac.StackHint, bc.StackHint)
+	add("configuration.optimizer", ac.Optimizer, bc.Optimizer)
+	add("configuration.controller", summaryController(ac.Controller), summaryController(bc.Controller))
+	add("configuration.os", ac.OS, bc.OS)
+	add("configuration.arch", ac.Arch, bc.Arch)
+	add("configuration.cpuCount", ac.CPUCount, bc.CPUCount)
+	add("configuration.gomaxprocs", ac.MaxProcs, bc.MaxProcs)
+	add("configuration.goVersion", ac.GoVersion, bc.GoVersion)
+
+	as, bs := a.Stats, b.Stats
+	add("stats.progress.iterations", as.Progress.Iterations, bs.Progress.Iterations)
+	add("stats.progress.completed", as.Progress.Completed, bs.Progress.Completed)
+	add("stats.duration", as.Duration, bs.Duration)
+	add("stats.throughput", as.Throughput, bs.Throughput)
+	add("stats.peakRoutines", as.PeakRoutines, bs.PeakRoutines)
+	add("stats.stackGrowth", as.StackGrowth, bs.StackGrowth)
+	add("stats.gcPause", as.GCPause, bs.GCPause)
+	add("stats.gcCPUFraction", as.GCCPUFraction, bs.GCCPUFraction)
+	add("errors.count", a.Errors.Count, b.Errors.Count)
+
+	return differences
+}
+
+// MARK: Public methods
+
+// RecordError adds err to the summary's errors. Execution errors returned by
+// ExecuteWithRetry and ExecuteKeys are recorded once per failed operation.
+func (s *RunSummary) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	if e, ok := err.(*ExecutionError); ok {
+		for _, failure := range e.Failures {
+			s.RecordError(failure)
+		}
+		return
+	}
+
+	s.Errors.Count++
+	if len(s.Errors.Messages) < maxSummaryErrors {
+		s.Errors.Messages = append(s.Errors.Messages, err.Error())
+	}
+}
+
+// WriteFile atomically writes the summary as JSON to the file at path.
+func (s *RunSummary) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(path, data)
+}
+
+// MARK: Private methods
+
+// begin records the start of an execution. Statistics that can only be read
+// by stopping the world are recorded if memStats is true.
+func (r *executionRecord) begin(memStats bool) {
+	r.started = time.Now()
+	r.finished = time.Time{}
+	atomic.StoreInt64(&r.peakRoutines, 0)
+	r.err = nil
+	r.probesDropped = 0
+	r.probeOverhead = 0
+	r.stackStart, r.stackMeasured = stackInuse(memStats)
+	r.stackGrowth = 0
+	r.gcStart, r.gcMeasured = readGCSample(memStats)
+	r.gcPause = 0
+	r.gcCPUFraction = 0.0
+}
+
+// end records the end of an execution.
+func (r *executionRecord) end() {
+	r.finished = time.Now()
+	if r.stackMeasured {
+		stack, _ := stackInuse(true)
+		r.stackGrowth = stack - r.stackStart
+	}
+
+	if r.gcMeasured {
+		gc, _ := readGCSample(true)
+		r.gcPause = time.Duration(gc.pause - r.gcStart.pause)
+		r.gcCPUFraction = gc.cpuFractionSince(r.gcStart)
+	}
+}
+
+// observeRoutines records that n routines are executing.
+func (r *executionRecord) observeRoutines(n int) {
+	for {
+		peak := atomic.LoadInt64(&r.peakRoutines)
+		if int64(n) <= peak || atomic.CompareAndSwapInt64(&r.peakRoutines, peak, int64(n)) {
+			return
+		}
+	}
+}
+
+// summary returns a run summary of the recorded execution with the specified
+// configuration and progress.
+func (r *executionRecord) summary(configuration SummaryConfiguration, progress Progress) *RunSummary {
+	configuration.OS = runtime.GOOS
+	configuration.Arch = runtime.GOARCH
+	configuration.CPUCount = runtime.NumCPU()
+	configuration.MaxProcs = runtime.GOMAXPROCS(0)
+	configuration.GoVersion = runtime.Version()
+
+	s := &RunSummary{
+		Configuration: configuration,
+		Stats: SummaryStats{
+			Progress:            progress,
+			Started:             r.started,
+			Finished:            r.finished,
+			PeakRoutines:        int(atomic.LoadInt64(&r.peakRoutines)),
+			ProbeSamplesDropped: r.probesDropped,
+			ProbeOverhead:       r.probeOverhead,
+			StackGrowth:         r.stackGrowth,
+			GCPause:             r.gcPause,
+			GCCPUFraction:       r.gcCPUFraction,
+		},
+	}
+
+	if !r.finished.IsZero() {
+		s.Stats.Duration = r.finished.Sub(r.started)
+		if seconds := s.Stats.Duration.Seconds(); seconds > 0.0 {
+			s.Stats.Throughput = float64(progress.Completed) / seconds
+		}
+	}
+
+	s.RecordError(r.err)
+	return s
+}
+
+// MARK: Private functions
+
+// summaryNumber returns x as a float64 if it is numeric.
+func summaryNumber(x interface{}) (float64, bool) {
+	switch v := x.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case time.Duration:
+		return float64(v), true
+	default:
+		return 0.0, false
+	}
+}
+
+// summaryController returns a comparable description of a controller
+// configuration.
+func summaryController(c *ControllerConfiguration) string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+v", *c)
+}
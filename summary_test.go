@@ -0,0 +1,108 @@
+package parallel
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessSummary(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleGuided)
+	p.Execute(1000, func(i int) {})
+
+	s := p.Summary()
+	if s.Configuration.Process != "FixedProcess" || s.Configuration.Schedule != "guided" {
+		t.Errorf("Configuration, %+v, should describe a guided fixed process.", s.Configuration)
+	}
+
+	if s.Stats.Progress.Completed != 1000 || s.Stats.PeakRoutines != 4 {
+		t.Errorf("Stats, %+v, should show 1000 iterations on 4 routines.", s.Stats)
+	}
+
+	if s.Stats.Duration <= 0 || s.Stats.Throughput <= 0.0 {
+		t.Errorf("Stats, %+v, should have a positive duration and throughput.", s.Stats)
+	}
+}
+
+func TestVariableProcessSummary(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, c, true)
+	p.Execute(200, func(i int) {
+		time.Sleep(100 * time.Microsecond)
+	})
+
+	s := p.Summary()
+	if s.Configuration.Controller == nil || s.Configuration.MaxRoutines != 8 {
+		t.Errorf("Configuration, %+v, should describe the variable process.", s.Configuration)
+	}
+
+	if len(s.ControllerHistory) == 0 {
+		t.Errorf("A probed process' summary should contain the controller history.")
+	}
+}
+
+func TestSummaryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewFixedProcess(2)
+	p.Execute(100, func(i int) {})
+
+	s := p.Summary()
+	s.RecordError(&ExecutionError{
+		Failures:   []*OperationError{{Index: 1, Attempts: 1, Err: errors.New("a")}, {Index: 2, Attempts: 1, Err: errors.New("b")}},
+		Iterations: 100,
+	})
+
+	path := filepath.Join(dir, "summary.json")
+	if err = s.WriteFile(path); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	r, err := ReadSummary(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.Errors.Count != 2 || len(r.Errors.Messages) != 2 {
+		t.Errorf("Errors, %+v, should contain both failures.", r.Errors)
+	}
+
+	if r.Stats.Progress != s.Stats.Progress || r.Configuration.Routines != 2 {
+		t.Errorf("Summary, %+v, should match the written summary.", r)
+	}
+}
+
+func TestDiffSummaries(t *testing.T) {
+	a := &RunSummary{}
+	a.Configuration.Routines = 2
+	a.Stats.Throughput = 100.0
+
+	b := &RunSummary{}
+	b.Configuration.Routines = 2
+	b.Configuration.Schedule = "static"
+	b.Stats.Throughput = 150.0
+
+	differences := DiffSummaries(a, b)
+	if len(differences) != 2 {
+		t.Fatalf("Differences, %+v, should contain the schedule and throughput.", differences)
+	}
+
+	if differences[0].Field != "configuration.schedule" || !math.IsNaN(differences[0].Change) {
+		t.Errorf("Difference, %+v, should be a non-numeric schedule change.", differences[0])
+	}
+
+	if differences[1].Field != "stats.throughput" || math.Abs(differences[1].Change-0.5) > 1e-9 {
+		t.Errorf("Difference, %+v, should be a 50%% throughput increase.", differences[1])
+	}
+}
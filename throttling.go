@@ -0,0 +1,60 @@
+package parallel
+
+import "time"
+
+// throttlingReporter types report the time the program's CPU quota throttled
+// it between successive calls to their throttled method. While a program is
+// throttled its usage drops, but the drop isn't spare capacity that more
+// routines could use.
+type throttlingReporter struct {
+	// The file the throttled time is read from, or empty if it can't be
+	// read.
+	path string
+
+	// The total throttled time at the last measurement.
+	last time.Duration
+}
+
+// MARK: Initializers
+
+// newThrottlingReporter creates and returns a new throttling reporter.
+func newThrottlingReporter() *throttlingReporter {
+	r := &throttlingReporter{path: cpuStatPath()}
+	r.reset()
+	return r
+}
+
+// MARK: Private methods
+
+// throttled returns the time the program was throttled since the last call to
+// throttled or reset.
+func (r *throttlingReporter) throttled() time.Duration {
+	total, ok := throttledTime(r.path)
+	if !ok {
+		return 0
+	}
+
+	d := total - r.last
+	r.last = total
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// reset restarts the reporter's measurements.
+func (r *throttlingReporter) reset() {
+	r.last, _ = throttledTime(r.path)
+}
+
+// MARK: Private functions
+
+// throttledRoutines returns m limited to current routines if the program was
+// throttled, so that no routines are added while the program is using all of
+// its CPU quota.
+func throttledRoutines(m int, current int, throttled time.Duration) int {
+	if throttled > 0 {
+		return minInt(m, current)
+	}
+	return m
+}
@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgroupRoot is the directory cgroup hierarchies are mounted in.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// MARK: Private functions
+
+// cpuStatPath returns the path of the cpu.stat file of the program's cgroup
+// that reports throttling, or an empty string if there isn't one. Both the
+// unified hierarchy of cgroup v2 and the cpu controller of cgroup v1 are
+// searched.
+func cpuStatPath() string {
+	file, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var candidates []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines have the form hierarchy-ID:controller-list:cgroup-path.
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		controllers, path := fields[1], fields[2]
+		if controllers == "" {
+			candidates = append(candidates,
+				filepath.Join(cgroupRoot, path, "cpu.stat"),
+				filepath.Join(cgroupRoot, "unified", path, "cpu.stat"),
+			)
+			continue
+		}
+
+		for _, controller := range strings.Split(controllers, ",") {
+			if controller == "cpu" {
+				candidates = append(candidates,
+					filepath.Join(cgroupRoot, controllers, path, "cpu.stat"),
+					filepath.Join(cgroupRoot, "cpu", path, "cpu.stat"),
+				)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := throttledTime(candidate); ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// throttledTime returns the total time the cgroup whose cpu.stat file is at
+// path has been throttled. cgroup v2 reports it as throttled_usec and cgroup
+// v1 as throttled_time in nanoseconds.
+func throttledTime(path string) (time.Duration, bool) {
+	if path == "" {
+		return 0, false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		var unit time.Duration
+		switch fields[0] {
+		case "throttled_usec":
+			unit = time.Microsecond
+		case "throttled_time":
+			unit = time.Nanosecond
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(value) * unit, true
+	}
+
+	return 0, false
+}
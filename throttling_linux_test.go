@@ -0,0 +1,43 @@
+package parallel
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestThrottledTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatalf("Error creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		contents string
+		expected time.Duration
+		ok       bool
+	}{
+		{"usage_usec 100\nnr_throttled 2\nthrottled_usec 1500\n", 1500 * time.Microsecond, true},
+		{"nr_periods 10\nnr_throttled 2\nthrottled_time 2500000\n", 2500 * time.Microsecond, true},
+		{"usage_usec 100\nuser_usec 50\n", 0, false},
+	} {
+		path := filepath.Join(dir, "cpu.stat")
+		if err = ioutil.WriteFile(path, []byte(test.contents), 0644); err != nil {
+			t.Fatalf("Error writing %s: %v", path, err)
+		}
+
+		d, ok := throttledTime(path)
+		if d != test.expected || ok != test.ok {
+			t.Errorf("Throttled time of %q, %s (%t), should be %s (%t).", test.contents, d, ok, test.expected, test.ok)
+		}
+	}
+
+	if _, ok := throttledTime(""); ok {
+		t.Errorf("A missing cpu.stat file shouldn't report a throttled time.")
+	}
+}
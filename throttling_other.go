@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package parallel
+
+import "time"
+
+// MARK: Private functions
+
+// cpuStatPath returns an empty string, since CPU quotas are only read from
+// Linux cgroups.
+func cpuStatPath() string {
+	return ""
+}
+
+// throttledTime reports that the throttled time can't be read.
+func throttledTime(path string) (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,29 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestThrottledRoutines(t *testing.T) {
+	if n := throttledRoutines(8, 4, 0); n != 8 {
+		t.Errorf("Routines, %d, should be 8 when the program wasn't throttled.", n)
+	}
+
+	if n := throttledRoutines(8, 4, time.Millisecond); n != 4 {
+		t.Errorf("Routines, %d, should be held at 4 when the program was throttled.", n)
+	}
+
+	if n := throttledRoutines(2, 4, time.Millisecond); n != 2 {
+		t.Errorf("Routines, %d, should still be removed when the program was throttled.", n)
+	}
+}
+
+func TestThrottlingReporter(t *testing.T) {
+	r := newThrottlingReporter()
+	if d := r.throttled(); d < 0 {
+		t.Errorf("Throttled time, %s, should not be negative.", d)
+	}
+}
@@ -0,0 +1,35 @@
+package parallel
+
+import "time"
+
+// throughputMeter types measure the number of iterations begun per second
+// between successive measurements.
+type throughputMeter struct {
+	// The number of iterations that had begun, and when, at the last
+	// measurement.
+	started int
+	time    time.Time
+}
+
+// MARK: Private methods
+
+// reset restarts the meter's measurements from now.
+func (m *throughputMeter) reset(now time.Time) {
+	m.started = 0
+	m.time = now
+}
+
+// measure returns the number of iterations begun per second since the last
+// measurement given that started had begun by now. It returns false if no time
+// has passed.
+func (m *throughputMeter) measure(started int, now time.Time) (float64, bool) {
+	elapsed := now.Sub(m.time).Seconds()
+	if elapsed <= 0.0 {
+		return 0.0, false
+	}
+
+	throughput := float64(started-m.started) / elapsed
+	m.started = started
+	m.time = now
+	return throughput, true
+}
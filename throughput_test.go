@@ -0,0 +1,63 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestThroughputMeter(t *testing.T) {
+	var m throughputMeter
+	now := time.Now()
+	m.reset(now)
+
+	if _, ok := m.measure(10, now); ok {
+		t.Errorf("A measurement should not be made when no time has passed.")
+	}
+
+	now = now.Add(2 * time.Second)
+	if throughput, ok := m.measure(100, now); !ok || throughput != 50.0 {
+		t.Errorf("Throughput, %f, should be 50.", throughput)
+	}
+
+	now = now.Add(time.Second)
+	if throughput, _ := m.measure(130, now); throughput != 30.0 {
+		t.Errorf("Throughput, %f, should be 30.", throughput)
+	}
+}
+
+func TestVariableProcessThroughputTarget(t *testing.T) {
+	c := NewControllerConfiguration(0.0, 0.5, 0.0, 1.0, 1.0)
+	c.ThroughputTarget = 4000.0
+	p := NewVariableProcess(5*time.Millisecond, 1, 32, c, false)
+	p.SetOptimizer(OptimizerThroughput)
+
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(time.Millisecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	// A single routine can't execute more than 1000 iterations per second.
+	if s := p.Summary(); s.Stats.PeakRoutines < 2 {
+		t.Errorf("Peak routines, %d, should increase towards the throughput target.", s.Stats.PeakRoutines)
+	}
+}
+
+func TestMisuseThroughputTarget(t *testing.T) {
+	c := NewControllerConfiguration(0.0, 0.5, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetOptimizer(OptimizerThroughput)
+
+	expectMisuse(t, "target must be positive", func() {
+		p.Execute(10, func(i int) {})
+	})
+}
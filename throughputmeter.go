@@ -0,0 +1,146 @@
+package parallel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultThroughputWindow is the window over which the throughput meters of
+// processes measure.
+const defaultThroughputWindow = 10 * time.Second
+
+// throughputMeterResolution is the number of samples a throughput meter keeps
+// per window.
+const throughputMeterResolution = 16
+
+// ThroughputMeter types measure the number of iterations completed per second
+// over a sliding window. Every process updates a meter as its iterations
+// complete, and meters can be created to measure other work.
+type ThroughputMeter struct {
+	// The number of iterations completed. It is updated atomically and is the
+	// first field so that it is 64-bit aligned.
+	count int64
+	_     cacheLinePad
+
+	// A mutex to protect the window, clock and samples.
+	mutex sync.Mutex
+
+	// The duration over which the rate is measured.
+	window time.Duration
+
+	// The clock the meter tells time with.
+	clock Clock
+
+	// Samples of the count in the order they were taken. The first sample is
+	// the last one taken at or before the start of the window.
+	samples []throughputSample
+}
+
+// throughputSample types hold the count of a meter at a point in time.
+type throughputSample struct {
+	time  time.Time
+	count int64
+}
+
+// MARK: Initializers
+
+// NewThroughputMeter creates and returns a new throughput meter that measures
+// over the specified window.
+func NewThroughputMeter(window time.Duration) *ThroughputMeter {
+	if window <= 0 {
+		misuse("NewThroughputMeter called with a window of %s; the window must be positive", window)
+	}
+	return newThroughputMeter(window, SystemClock{})
+}
+
+// newThroughputMeter creates and returns a new throughput meter that measures
+// over window with clock.
+func newThroughputMeter(window time.Duration, clock Clock) *ThroughputMeter {
+	m := &ThroughputMeter{
+		window: window,
+	}
+	m.setClock(clock)
+	return m
+}
+
+// MARK: Public methods
+
+// Add records that n iterations have completed.
+func (m *ThroughputMeter) Add(n int) {
+	atomic.AddInt64(&m.count, int64(n))
+}
+
+// Count returns the total number of iterations the meter has recorded.
+func (m *ThroughputMeter) Count() int64 {
+	return atomic.LoadInt64(&m.count)
+}
+
+// Window returns the duration over which the meter measures.
+func (m *ThroughputMeter) Window() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.window
+}
+
+// Rate returns the number of iterations completed per second over the
+// meter's window. Until a full window has passed, the rate is measured from
+// when the meter was created. The meter samples its count when its rate is
+// requested, so if the rate is requested less often than once per window it
+// is measured over the time since the last request.
+func (m *ThroughputMeter) Rate() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.clock.Now()
+	count := m.Count()
+	m.observe(now, count)
+
+	baseline := m.samples[0]
+	elapsed := now.Sub(baseline.time).Seconds()
+	if elapsed <= 0.0 {
+		return 0.0
+	}
+	return float64(count-baseline.count) / elapsed
+}
+
+// MARK: Private methods
+
+// sample samples the meter's count. Processes that tick sample their meters
+// so that their rate stays within the window.
+func (m *ThroughputMeter) sample() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.observe(m.clock.Now(), m.Count())
+}
+
+// setClock sets the clock the meter tells time with and restarts its
+// measurements.
+func (m *ThroughputMeter) setClock(clock Clock) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.clock = clock
+	m.samples = append(m.samples[:0], throughputSample{
+		time:  clock.Now(),
+		count: m.Count(),
+	})
+}
+
+// observe records that the meter counted count at now and discards the
+// samples that are no longer needed. The mutex must be held.
+func (m *ThroughputMeter) observe(now time.Time, count int64) {
+	last := m.samples[len(m.samples)-1]
+	if now.Sub(last.time) >= m.window/throughputMeterResolution {
+		m.samples = append(m.samples, throughputSample{time: now, count: count})
+	}
+
+	start := now.Add(-m.window)
+	i := 0
+	for i+1 < len(m.samples) && !m.samples[i+1].time.After(start) {
+		i++
+	}
+	if i > 0 {
+		m.samples = append(m.samples[:0], m.samples[i:]...)
+	}
+}
@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestThroughputMeterRate(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	m := newThroughputMeter(10*time.Second, clock)
+
+	if m.Rate() != 0.0 {
+		t.Errorf("Rate, %f, should be 0 before time passes.", m.Rate())
+	}
+
+	// 100 iterations per second for the first ten seconds.
+	for i := 0; i < 10; i++ {
+		m.Add(100)
+		clock.Advance(time.Second)
+		m.sample()
+	}
+	expectRate(t, m, 100.0)
+
+	// 300 iterations per second for the next ten seconds replace the first
+	// ten seconds in the window.
+	for i := 0; i < 10; i++ {
+		m.Add(300)
+		clock.Advance(time.Second)
+		m.sample()
+	}
+	expectRate(t, m, 300.0)
+
+	if m.Count() != 4000 {
+		t.Errorf("Count, %d, should be 4000.", m.Count())
+	}
+	if len(m.samples) > throughputMeterResolution+2 {
+		t.Errorf("Samples, %d, should be bounded by the meter's resolution.", len(m.samples))
+	}
+}
+
+func TestThroughputMeterUnsampled(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	m := newThroughputMeter(time.Second, clock)
+
+	m.Add(50)
+	clock.Advance(5 * time.Second)
+	expectRate(t, m, 10.0)
+}
+
+func TestProcessThroughput(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	fixed := NewFixedProcess(2)
+	variable := NewVariableProcess(time.Millisecond, 1, 2, c, false)
+	forkJoin := NewForkJoinProcess(2)
+	batch := NewBatchProcess(NewFixedProcess(2), 7)
+	ordered := NewOrderedProcess(NewFixedProcess(2), 4)
+	priority := NewPriorityProcess(NewFixedProcess(2), func(i int) int { return -i })
+	dag := NewDAGProcess(NewFixedProcess(2))
+	for i := 0; i < 100; i++ {
+		dag.AddTask(fmt.Sprintf("task %d", i), nil, func() {})
+	}
+
+	operation := func(i int) {}
+	processes := []struct {
+		name    string
+		meter   *ThroughputMeter
+		execute func()
+	}{
+		{"FixedProcess", fixed.Throughput(), func() { fixed.Execute(100, operation) }},
+		{"VariableProcess", variable.Throughput(), func() { variable.Execute(100, operation) }},
+		{"ForkJoinProcess", forkJoin.Throughput(), func() { forkJoin.Execute(100, operation) }},
+		{"BatchProcess", batch.Throughput(), func() { batch.Execute(100, operation) }},
+		{"OrderedProcess", ordered.Throughput(), func() {
+			ordered.ExecuteOrdered(100, func(i int) interface{} { return i }, func(i int, value interface{}) {})
+		}},
+		{"PriorityProcess", priority.Throughput(), func() { priority.Execute(100, operation) }},
+		{"DAGProcess", dag.Throughput(), func() { dag.Execute() }},
+	}
+
+	for _, p := range processes {
+		p.execute()
+		if p.meter.Count() != 100 {
+			t.Errorf("The %s's meter counted %d iterations instead of 100.", p.name, p.meter.Count())
+		}
+		if !(p.meter.Rate() > 0.0) {
+			t.Errorf("The %s's rate should be positive.", p.name)
+		}
+	}
+}
+
+// MARK: Helpers
+
+// expectRate fails the test if m's rate isn't within 1% of expected.
+func expectRate(t *testing.T, m *ThroughputMeter, expected float64) {
+	t.Helper()
+	if rate := m.Rate(); math.Abs(rate-expected) > expected/100.0 {
+		t.Errorf("Rate, %f, should be %f.", rate, expected)
+	}
+}
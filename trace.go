@@ -0,0 +1,320 @@
+package parallel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// traceMagic begins every trace.
+const traceMagic = "PTRC\x01"
+
+// TraceEventKind types identify the kind of a trace event.
+type TraceEventKind byte
+
+const (
+	// TraceTick is recorded each time a variable process' ticker fires.
+	TraceTick TraceEventKind = iota + 1
+
+	// TraceUsage is recorded at each optimization of a variable process with
+	// the usage its controller responds to, before smoothing.
+	TraceUsage
+
+	// TraceOptimize is recorded at each optimization of a variable process
+	// with the controller's sample.
+	TraceOptimize
+
+	// TraceRoutines is recorded when an optimization adds or removes
+	// routines.
+	TraceRoutines
+
+	// TraceClaim is recorded when a routine claims a chunk of iterations.
+	TraceClaim
+)
+
+// TraceEvent types are events read from a trace. Only the fields of the
+// event's kind are set.
+type TraceEvent struct {
+	// The kind of the event.
+	Kind TraceEventKind
+
+	// The time of the event since the trace's first event.
+	Time time.Duration
+
+	// The usage of a TraceUsage event.
+	Usage float64
+
+	// The controller's sample of a TraceOptimize event.
+	Sample ControllerSample
+
+	// The number of routines added, or the negated number removed, of a
+	// TraceRoutines event.
+	Delta int
+
+	// The routine of a TraceClaim event and the range of iterations, [Start,
+	// End), it claimed.
+	Routine int
+	Start   int
+	End     int
+}
+
+// TraceRecorder types record the scheduling events of fixed and variable
+// processes to a compact binary trace, so that the behavior of a process can
+// be replayed and inspected after the fact.
+type TraceRecorder struct {
+	// A mutex to protect the writer and the time of the last event.
+	mutex sync.Mutex
+
+	// The writer the trace is buffered to.
+	writer *bufio.Writer
+
+	// The time of the first event, and the time of the last event since the
+	// first.
+	start time.Time
+	last  time.Duration
+
+	// The first error encountered while writing.
+	err error
+
+	// A buffer for encoding events.
+	buffer []byte
+}
+
+// MARK: Initializers
+
+// NewTraceRecorder creates and returns a new recorder writing a trace to w.
+// Events are buffered, so Flush must be called once recording is done.
+func NewTraceRecorder(w io.Writer) *TraceRecorder {
+	r := &TraceRecorder{
+		writer: bufio.NewWriter(w),
+		buffer: make([]byte, 0, 64),
+	}
+	_, r.err = r.writer.WriteString(traceMagic)
+	return r
+}
+
+// MARK: Public functions
+
+// ReadTrace reads the events of a trace written by a trace recorder.
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	reader := bufio.NewReader(r)
+
+	magic := make([]byte, len(traceMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != traceMagic {
+		return nil, errors.New("parallel: not a trace")
+	}
+
+	var events []TraceEvent
+	var last time.Duration
+	for {
+		kind, err := reader.ReadByte()
+		if err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+
+		delta, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return events, traceReadError(err)
+		}
+		last += time.Duration(delta)
+
+		event := TraceEvent{Kind: TraceEventKind(kind), Time: last}
+		switch event.Kind {
+		case TraceTick:
+		case TraceUsage:
+			event.Usage, err = readTraceFloat(reader)
+		case TraceOptimize:
+			values := make([]float64, 4)
+			for i := range values {
+				if values[i], err = readTraceFloat(reader); err != nil {
+					break
+				}
+			}
+			event.Sample = ControllerSample{Usage: values[0], Error: values[1], Output: values[2], Routines: values[3]}
+		case TraceRoutines:
+			var delta int64
+			delta, err = binary.ReadVarint(reader)
+			event.Delta = int(delta)
+		case TraceClaim:
+			values := make([]uint64, 3)
+			for i := range values {
+				if values[i], err = binary.ReadUvarint(reader); err != nil {
+					break
+				}
+			}
+			event.Routine, event.Start, event.End = int(values[0]), int(values[1]), int(values[2])
+		default:
+			return events, fmt.Errorf("parallel: unknown trace event kind %d", kind)
+		}
+		if err != nil {
+			return events, traceReadError(err)
+		}
+
+		events = append(events, event)
+	}
+}
+
+// ReplayTrace re-drives a controller using configuration with the usage
+// recorded in a trace's TraceUsage events, and returns the controller's sample
+// at each optimization. Replaying with the configuration the process used
+// reproduces its TraceOptimize samples, up to the limits that
+// SimulateController doesn't apply, and replaying with another shows how it
+// would have responded instead.
+func ReplayTrace(configuration *ControllerConfiguration, events []TraceEvent, cpuCount int, maxRoutines int) []ControllerSample {
+	var usage []float64
+	for _, event := range events {
+		if event.Kind == TraceUsage {
+			usage = append(usage, event.Usage)
+		}
+	}
+	return SimulateController(configuration, usage, cpuCount, maxRoutines)
+}
+
+// MARK: Public methods
+
+// Flush writes any buffered events and returns the first error encountered
+// while writing the trace.
+func (r *TraceRecorder) Flush() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err == nil {
+		r.err = r.writer.Flush()
+	}
+	return r.err
+}
+
+// String returns the name of the event kind.
+func (k TraceEventKind) String() string {
+	switch k {
+	case TraceTick:
+		return "tick"
+	case TraceUsage:
+		return "usage"
+	case TraceOptimize:
+		return "optimize"
+	case TraceRoutines:
+		return "routines"
+	case TraceClaim:
+		return "claim"
+	}
+	return fmt.Sprintf("TraceEventKind(%d)", byte(k))
+}
+
+// MARK: Private methods
+
+// tick records a tick at now.
+func (r *TraceRecorder) tick(now time.Time) {
+	r.record(TraceTick, now, nil)
+}
+
+// usage records the usage a controller responded to at now.
+func (r *TraceRecorder) usage(now time.Time, usage float64) {
+	r.record(TraceUsage, now, func(b []byte) []byte {
+		return appendTraceFloat(b, usage)
+	})
+}
+
+// optimize records a controller's sample at now.
+func (r *TraceRecorder) optimize(now time.Time, sample ControllerSample) {
+	r.record(TraceOptimize, now, func(b []byte) []byte {
+		b = appendTraceFloat(b, sample.Usage)
+		b = appendTraceFloat(b, sample.Error)
+		b = appendTraceFloat(b, sample.Output)
+		return appendTraceFloat(b, sample.Routines)
+	})
+}
+
+// routines records that delta routines were added, or removed if delta is
+// negative, at now.
+func (r *TraceRecorder) routines(now time.Time, delta int) {
+	r.record(TraceRoutines, now, func(b []byte) []byte {
+		return appendTraceVarint(b, int64(delta))
+	})
+}
+
+// claim records that routine claimed the iterations [start, end) at now.
+func (r *TraceRecorder) claim(now time.Time, routine int, start int, end int) {
+	r.record(TraceClaim, now, func(b []byte) []byte {
+		b = appendTraceUvarint(b, uint64(routine))
+		b = appendTraceUvarint(b, uint64(start))
+		return appendTraceUvarint(b, uint64(end))
+	})
+}
+
+// record writes an event of the specified kind at now, with the payload
+// appended by payload.
+func (r *TraceRecorder) record(kind TraceEventKind, now time.Time, payload func([]byte) []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	if r.start.IsZero() {
+		r.start = now
+	}
+
+	// Events are written in the order they're recorded, so an event recorded
+	// with an earlier time than the last is written at the last's time.
+	t := now.Sub(r.start)
+	if t < r.last {
+		t = r.last
+	}
+
+	b := append(r.buffer[:0], byte(kind))
+	b = appendTraceUvarint(b, uint64(t-r.last))
+	if payload != nil {
+		b = payload(b)
+	}
+	r.last = t
+	r.buffer = b
+
+	_, r.err = r.writer.Write(b)
+}
+
+// MARK: Private functions
+
+// appendTraceFloat appends the bits of f to b.
+func appendTraceFloat(b []byte, f float64) []byte {
+	var bits [8]byte
+	binary.LittleEndian.PutUint64(bits[:], math.Float64bits(f))
+	return append(b, bits[:]...)
+}
+
+// appendTraceUvarint appends the varint encoding of x to b.
+func appendTraceUvarint(b []byte, x uint64) []byte {
+	var buffer [binary.MaxVarintLen64]byte
+	return append(b, buffer[:binary.PutUvarint(buffer[:], x)]...)
+}
+
+// appendTraceVarint appends the varint encoding of x to b.
+func appendTraceVarint(b []byte, x int64) []byte {
+	var buffer [binary.MaxVarintLen64]byte
+	return append(b, buffer[:binary.PutVarint(buffer[:], x)]...)
+}
+
+// readTraceFloat reads a float written by appendTraceFloat.
+func readTraceFloat(r io.Reader) (float64, error) {
+	var bits [8]byte
+	if _, err := io.ReadFull(r, bits[:]); err != nil {
+		return 0.0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(bits[:])), nil
+}
+
+// traceReadError returns the error of reading a truncated event.
+func traceReadError(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
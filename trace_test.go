@@ -0,0 +1,146 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestTraceRecorderRoundTrip(t *testing.T) {
+	var buffer bytes.Buffer
+	r := NewTraceRecorder(&buffer)
+
+	start := time.Unix(100, 0)
+	r.tick(start)
+	r.usage(start.Add(time.Millisecond), 1.5)
+	r.optimize(start.Add(time.Millisecond), ControllerSample{Usage: 1.5, Error: 0.25, Output: 3.2, Routines: 4.0})
+	r.routines(start.Add(time.Millisecond), 3)
+	r.routines(start.Add(2*time.Millisecond), -2)
+	r.claim(start.Add(3*time.Millisecond), 1, 10, 20)
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	events, err := ReadTrace(&buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []TraceEvent{
+		{Kind: TraceTick},
+		{Kind: TraceUsage, Time: time.Millisecond, Usage: 1.5},
+		{Kind: TraceOptimize, Time: time.Millisecond, Sample: ControllerSample{Usage: 1.5, Error: 0.25, Output: 3.2, Routines: 4.0}},
+		{Kind: TraceRoutines, Time: time.Millisecond, Delta: 3},
+		{Kind: TraceRoutines, Time: 2 * time.Millisecond, Delta: -2},
+		{Kind: TraceClaim, Time: 3 * time.Millisecond, Routine: 1, Start: 10, End: 20},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Events, %d, should be %d.", len(events), len(expected))
+	}
+	for i, event := range events {
+		if event != expected[i] {
+			t.Errorf("Event %d, %+v, should be %+v.", i, event, expected[i])
+		}
+	}
+}
+
+func TestReadTraceErrors(t *testing.T) {
+	if _, err := ReadTrace(bytes.NewReader([]byte("not a trace"))); err == nil {
+		t.Errorf("Reading something other than a trace should fail.")
+	}
+
+	var buffer bytes.Buffer
+	r := NewTraceRecorder(&buffer)
+	r.usage(time.Now(), 1.0)
+	r.Flush()
+
+	truncated := buffer.Bytes()[:buffer.Len()-1]
+	if _, err := ReadTrace(bytes.NewReader(truncated)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Error, %v, should be %v.", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestVariableProcessTraceReplay(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	var buffer bytes.Buffer
+	p.SetTraceRecorder(NewTraceRecorder(&buffer))
+	p.Execute(100, func(i int) {
+		time.Sleep(200 * time.Microsecond)
+	})
+	if err := p.TraceRecorder().Flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	events, err := ReadTrace(&buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	counts := make(map[TraceEventKind]int)
+	claimed := make([]bool, 100)
+	var optimizations []ControllerSample
+	for _, event := range events {
+		counts[event.Kind]++
+		switch event.Kind {
+		case TraceClaim:
+			claimed[event.Start] = true
+		case TraceOptimize:
+			optimizations = append(optimizations, event.Sample)
+		}
+	}
+
+	if counts[TraceTick] == 0 || counts[TraceUsage] == 0 || counts[TraceUsage] != counts[TraceOptimize] {
+		t.Errorf("Event counts, %v, should contain ticks and a usage for each optimization.", counts)
+	}
+	for i, ok := range claimed {
+		if !ok {
+			t.Errorf("Iteration %d should have been claimed.", i)
+			break
+		}
+	}
+
+	// With the process' configuration, the replay reproduces the recorded
+	// controller outputs.
+	replayed := ReplayTrace(c, events, 0, 4)
+	if len(replayed) != len(optimizations) {
+		t.Fatalf("Replayed samples, %d, should be %d.", len(replayed), len(optimizations))
+	}
+	for i := range replayed {
+		if math.Abs(replayed[i].Output-optimizations[i].Output) > 1e-9 {
+			t.Errorf("Replayed output %d, %f, should be %f.", i, replayed[i].Output, optimizations[i].Output)
+			break
+		}
+	}
+}
+
+func TestFixedProcessTrace(t *testing.T) {
+	p := NewFixedProcess(4)
+	p.SetSchedule(ScheduleStatic)
+
+	var buffer bytes.Buffer
+	p.SetTraceRecorder(NewTraceRecorder(&buffer))
+	p.Execute(100, func(i int) {})
+	p.TraceRecorder().Flush()
+
+	events, err := ReadTrace(&buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	total := 0
+	for _, event := range events {
+		if event.Kind != TraceClaim {
+			t.Errorf("Event %+v should be a claim.", event)
+		}
+		total += event.End - event.Start
+	}
+	if total != 100 {
+		t.Errorf("Claimed iterations, %d, should be 100.", total)
+	}
+}
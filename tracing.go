@@ -0,0 +1,29 @@
+package parallel
+
+import (
+	"context"
+	"time"
+)
+
+// ExecutionTracer types trace the executions of a variable process, such as
+// with an OpenTelemetry tracer, so services can see where their parallel
+// sections spend time.
+type ExecutionTracer interface {
+	// StartExecution is called when an execution of the specified number of
+	// iterations begins, and returns the span tracing it. The context is the
+	// one passed to ExecuteContext, or context.Background for other
+	// executions.
+	StartExecution(ctx context.Context, iterations int) ExecutionSpan
+}
+
+// ExecutionSpan types trace a single execution of a variable process.
+type ExecutionSpan interface {
+	// Optimized is called at each optimization of the execution with the
+	// controller's signals and the number of routines given to the
+	// execution.
+	Optimized(sample ControllerSample)
+
+	// End is called when the execution ends with the number of routines it
+	// finished with and how long it ran for.
+	End(routines int, duration time.Duration)
+}
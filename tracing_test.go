@@ -0,0 +1,116 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestVariableProcessTracer(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	tracer := &recordingTracer{}
+	p.SetTracer(tracer)
+	if p.Tracer() != tracer {
+		t.Errorf("The process' tracer should be the one it was given.")
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, true)
+	if err := p.ExecuteContext(ctx, 100, func(ctx context.Context, i int) {
+		time.Sleep(200 * time.Microsecond)
+	}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Spans, %d, should be 1.", len(tracer.spans))
+	}
+
+	span := tracer.spans[0]
+	if span.ctx.Value(key{}) != true {
+		t.Errorf("The span should be started with the execution's context.")
+	}
+	if span.iterations != 100 {
+		t.Errorf("Iterations, %d, should be 100.", span.iterations)
+	}
+	if !span.ended {
+		t.Errorf("The span should be ended.")
+	}
+	if span.routines < 1 {
+		t.Errorf("Routines, %d, should be at least 1.", span.routines)
+	}
+	if span.duration < 20*time.Millisecond {
+		t.Errorf("Duration, %s, should be at least 20ms.", span.duration)
+	}
+	for i, sample := range span.samples {
+		if sample.Routines < 1.0 {
+			t.Errorf("Sample %d, %+v, should give at least one routine.", i, sample)
+		}
+	}
+}
+
+func TestVariableProcessTracerBackgroundContext(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	tracer := &recordingTracer{}
+	p.SetTracer(tracer)
+	p.Execute(10, func(i int) {})
+	p.Execute(0, func(i int) {})
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Spans, %d, should be 1.", len(tracer.spans))
+	}
+	if tracer.spans[0].ctx != context.Background() {
+		t.Errorf("The span should be started with the background context.")
+	}
+}
+
+// MARK: Helpers
+
+// recordingTracer types record the spans they start.
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordingSpan
+}
+
+func (r *recordingTracer) StartExecution(ctx context.Context, iterations int) ExecutionSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := &recordingSpan{tracer: r, ctx: ctx, iterations: iterations}
+	r.spans = append(r.spans, s)
+	return s
+}
+
+// recordingSpan types record the events of a traced execution.
+type recordingSpan struct {
+	tracer     *recordingTracer
+	ctx        context.Context
+	iterations int
+	samples    []ControllerSample
+	ended      bool
+	routines   int
+	duration   time.Duration
+}
+
+func (s *recordingSpan) Optimized(sample ControllerSample) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.samples = append(s.samples, sample)
+}
+
+func (s *recordingSpan) End(routines int, duration time.Duration) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.ended = true
+	s.routines = routines
+	s.duration = duration
+}
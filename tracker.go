@@ -0,0 +1,156 @@
+package parallel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ExperimentTracker types record runs in an experiment tracker.
+type ExperimentTracker interface {
+	// LogSummary records a run summary.
+	LogSummary(summary *RunSummary) error
+
+	// LogSeries records a named series of values, such as a probe's signal.
+	LogSeries(name string, values []float64) error
+}
+
+// HTTPTracker types record runs by posting JSON to an HTTP endpoint, which can
+// forward them to MLflow, Weights & Biases or a similar tracker.
+//
+// Summaries are posted as {"kind": "summary", "summary": {...}} and series as
+// {"kind": "series", "name": "...", "values": [...]}. Responses with a status
+// outside of the 2xx range are reported as errors.
+type HTTPTracker struct {
+	// The URL that records are posted to.
+	URL string
+
+	// Headers added to every request, such as an authorization token.
+	Header http.Header
+
+	// The client used to post records. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// trackerRecord types are the JSON bodies posted by an HTTP tracker.
+type trackerRecord struct {
+	Kind    string      `json:"kind"`
+	Summary *RunSummary `json:"summary,omitempty"`
+	Name    string      `json:"name,omitempty"`
+	Values  []float64   `json:"values,omitempty"`
+}
+
+// MARK: Initializers
+
+// NewHTTPTracker creates and returns a new HTTP tracker that posts records to
+// url.
+func NewHTTPTracker(url string) *HTTPTracker {
+	return &HTTPTracker{
+		URL:    url,
+		Header: make(http.Header),
+	}
+}
+
+// MARK: Public functions
+
+// Track records summary with tracker, followed by each signal of its
+// controller history as a series named usage, error, output and routines.
+func Track(tracker ExperimentTracker, summary *RunSummary) error {
+	if err := tracker.LogSummary(summary); err != nil {
+		return err
+	}
+
+	if len(summary.ControllerHistory) == 0 {
+		return nil
+	}
+
+	n := len(summary.ControllerHistory)
+	usage := make([]float64, n)
+	errors := make([]float64, n)
+	outputs := make([]float64, n)
+	routines := make([]float64, n)
+	for i, sample := range summary.ControllerHistory {
+		usage[i] = sample.Usage
+		errors[i] = sample.Error
+		outputs[i] = sample.Output
+		routines[i] = sample.Routines
+	}
+
+	for _, series := range []struct {
+		name   string
+		values []float64
+	}{
+		{"usage", usage},
+		{"error", errors},
+		{"output", outputs},
+		{"routines", routines},
+	} {
+		if err := tracker.LogSeries(series.name, series.values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MARK: Public methods
+
+// LogSummary posts summary to the tracker's URL.
+func (t *HTTPTracker) LogSummary(summary *RunSummary) error {
+	return t.post(trackerRecord{
+		Kind:    "summary",
+		Summary: summary,
+	})
+}
+
+// LogSeries posts the named series of values to the tracker's URL.
+func (t *HTTPTracker) LogSeries(name string, values []float64) error {
+	return t.post(trackerRecord{
+		Kind:   "series",
+		Name:   name,
+		Values: values,
+	})
+}
+
+// MARK: Private methods
+
+// post posts record to the tracker's URL as JSON.
+func (t *HTTPTracker) post(record trackerRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, t.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range t.Header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("parallel: tracker responded with %s", response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package parallel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestHTTPTracker(t *testing.T) {
+	var mutex sync.Mutex
+	var records []trackerRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var record trackerRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mutex.Lock()
+		records = append(records, record)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	summary := &RunSummary{
+		ControllerHistory: []ControllerSample{{Usage: 1.0, Routines: 2.0}, {Usage: 2.0, Routines: 3.0}},
+	}
+	summary.Stats.Progress.Completed = 42
+
+	tracker := NewHTTPTracker(server.URL)
+	if err := Track(tracker, summary); err == nil {
+		t.Errorf("Unauthorized requests should return an error.")
+	}
+
+	tracker.Header.Set("Authorization", "token")
+	if err := Track(tracker, summary); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(records) != 5 {
+		t.Fatalf("Record count, %d, should be 5.", len(records))
+	}
+
+	if records[0].Kind != "summary" || records[0].Summary.Stats.Progress.Completed != 42 {
+		t.Errorf("Record, %+v, should be the summary.", records[0])
+	}
+
+	if records[4].Kind != "series" || records[4].Name != "routines" || len(records[4].Values) != 2 || records[4].Values[1] != 3.0 {
+		t.Errorf("Record, %+v, should be the routines series.", records[4])
+	}
+}
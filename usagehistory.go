@@ -0,0 +1,120 @@
+package parallel
+
+import "time"
+
+// defaultUsageHistoryLength is the number of usage samples a variable process
+// retains unless configured otherwise.
+const defaultUsageHistoryLength = 256
+
+// UsageSample types contain a single CPU usage measurement of a variable
+// process' reporter.
+type UsageSample struct {
+	// The time the usage was measured.
+	Time time.Time `json:"time"`
+
+	// The time since the previous measurement.
+	Interval time.Duration `json:"interval"`
+
+	// The number of CPUs used by the program over the interval, before the
+	// garbage collector's usage is subtracted or the signal is smoothed. If
+	// the process takes several samples per optimization, this is their
+	// median.
+	Usage float64 `json:"usage"`
+
+	// The time the program's CPU quota throttled it over the interval. It is
+	// only measured in Linux cgroups with a CPU quota.
+	Throttled time.Duration `json:"throttled,omitempty"`
+
+	// The joules used by the machine over the interval. It is only measured
+	// by processes using the energy optimizer.
+	Energy float64 `json:"energy,omitempty"`
+}
+
+// usageHistory types retain the most recent usage samples in a ring buffer.
+type usageHistory struct {
+	// The retained samples.
+	samples []UsageSample
+
+	// The index the next sample is written to.
+	next int
+
+	// The number of retained samples.
+	count int
+
+	// The time of the last sample, or of the last reset.
+	last time.Time
+}
+
+// MARK: Initializers
+
+// newUsageHistory creates and returns a new usage history retaining length
+// samples.
+func newUsageHistory(length int) *usageHistory {
+	return &usageHistory{
+		samples: make([]UsageSample, length),
+		last:    time.Now(),
+	}
+}
+
+// MARK: Private methods
+
+// record adds the usage, throttled time and energy measured at now to the
+// history, overwriting the oldest sample if the history is full.
+func (h *usageHistory) record(now time.Time, usage float64, throttled time.Duration, energy float64) {
+	interval := now.Sub(h.last)
+	h.last = now
+
+	if len(h.samples) == 0 {
+		return
+	}
+
+	h.samples[h.next] = UsageSample{
+		Time:      now,
+		Interval:  interval,
+		Usage:     usage,
+		Throttled: throttled,
+		Energy:    energy,
+	}
+	h.next = (h.next + 1) % len(h.samples)
+	h.count = minInt(h.count+1, len(h.samples))
+}
+
+// history returns a copy of the retained samples, oldest first.
+func (h *usageHistory) history() []UsageSample {
+	samples := make([]UsageSample, h.count)
+	start := h.next - h.count
+	if start < 0 {
+		start += len(h.samples)
+	}
+
+	for i := range samples {
+		samples[i] = h.samples[(start+i)%len(h.samples)]
+	}
+
+	return samples
+}
+
+// reset discards the retained samples. The next sample's interval is measured
+// from now.
+func (h *usageHistory) reset(now time.Time) {
+	h.next = 0
+	h.count = 0
+	h.last = now
+}
+
+// resize changes the number of samples the history retains, keeping the most
+// recent ones.
+func (h *usageHistory) resize(length int) {
+	samples := h.history()
+	if len(samples) > length {
+		samples = samples[len(samples)-length:]
+	}
+
+	h.samples = make([]UsageSample, length)
+	copy(h.samples, samples)
+	h.count = len(samples)
+	h.next = 0
+	if length > 0 {
+		h.next = h.count % length
+	}
+}
@@ -0,0 +1,90 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestUsageHistoryWraps(t *testing.T) {
+	h := newUsageHistory(3)
+	start := time.Now()
+	h.reset(start)
+	for i := 1; i <= 5; i++ {
+		h.record(start.Add(time.Duration(i)*time.Second), float64(i), 0, 0.0)
+	}
+
+	samples := h.history()
+	if len(samples) != 3 {
+		t.Fatalf("History length, %d, should be 3.", len(samples))
+	}
+
+	for i, sample := range samples {
+		if sample.Usage != float64(i+3) {
+			t.Errorf("Sample %d usage, %f, should be %d.", i, sample.Usage, i+3)
+		}
+
+		if sample.Interval != time.Second {
+			t.Errorf("Sample %d interval, %s, should be 1s.", i, sample.Interval)
+		}
+	}
+}
+
+func TestUsageHistoryResize(t *testing.T) {
+	h := newUsageHistory(4)
+	start := time.Now()
+	for i := 1; i <= 4; i++ {
+		h.record(start.Add(time.Duration(i)*time.Second), float64(i), 0, 0.0)
+	}
+
+	h.resize(2)
+	samples := h.history()
+	if len(samples) != 2 || samples[0].Usage != 3.0 || samples[1].Usage != 4.0 {
+		t.Errorf("History, %+v, should keep the two most recent samples.", samples)
+	}
+
+	h.record(start.Add(5*time.Second), 5.0, 0, 0.0)
+	samples = h.history()
+	if len(samples) != 2 || samples[0].Usage != 4.0 || samples[1].Usage != 5.0 {
+		t.Errorf("History, %+v, should contain samples 4 and 5.", samples)
+	}
+
+	h.resize(0)
+	h.record(start.Add(6*time.Second), 6.0, 0, 0.0)
+	if samples = h.history(); len(samples) != 0 {
+		t.Errorf("Disabled history, %+v, should be empty.", samples)
+	}
+}
+
+func TestVariableProcessUsageHistory(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	p.Execute(100, func(i int) {
+		time.Sleep(time.Millisecond)
+	})
+
+	samples := p.UsageHistory()
+	if len(samples) == 0 {
+		t.Fatalf("The process should record its usage history.")
+	}
+
+	for i, sample := range samples {
+		if sample.Interval <= 0 || sample.Usage < 0.0 {
+			t.Errorf("Sample %d, %+v, should have a positive interval and usage.", i, sample)
+		}
+
+		if i > 0 && sample.Time.Before(samples[i-1].Time) {
+			t.Errorf("Sample %d should not precede sample %d.", i, i-1)
+		}
+	}
+
+	p.SetUsageHistoryLength(0)
+	p.Execute(10, func(i int) {
+		time.Sleep(time.Millisecond)
+	})
+	if samples = p.UsageHistory(); len(samples) != 0 {
+		t.Errorf("History, %+v, should be empty when disabled.", samples)
+	}
+}
@@ -0,0 +1,261 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// variableExecution types hold the state of a single execution of a variable
+// process. A variable process may run several executions at once, sharing its
+// controller between them.
+type variableExecution struct {
+	// The number of iterations that have begun, the number of goroutines the
+	// execution should use and the number of routines to remove after
+	// optimizing. Each is updated by every routine and is padded onto its own
+	// cache line.
+	iteration   safeInt
+	_           cacheLinePad
+	numRoutines int64
+	_           cacheLinePad
+	numToRemove int64
+	_           cacheLinePad
+
+	// The number of routines the execution has started.
+	startedRoutines int64
+
+	// Incremented each time parked routines are woken, so that spinning
+	// routines notice without taking the mutex.
+	generation int64
+
+	// Set to 1 once the execution has been stopped.
+	stopped int32
+
+	// A mutex to protect the number of goroutines and of active and parked
+	// routines, and the condition parked routines wait on.
+	mutex sync.Mutex
+	cond  *sync.Cond
+
+	// The number of the execution's goroutines that haven't returned, and a
+	// channel that is closed when the last of them returns. Optimizations
+	// scale the execution by adjusting the count under the mutex, so waiting
+	// for the execution never races with routines being added.
+	goroutines int
+	done       chan struct{}
+
+	// The number of routines that are running. Once it reaches zero the
+	// execution is finishing and no more routines are added.
+	active int
+
+	// The number of routines that were removed by an optimization and are
+	// parked, and the number of them that have been woken but haven't resumed.
+	parked  int
+	wakeups int
+
+	// The total number of iterations.
+	iterations int
+
+	// The sharded counter handing out the iterations, or nil if they are
+	// claimed from the iteration counter.
+	shards *shardedCounter
+
+	// The operation function called for each iteration, or the operation
+	// called with the context of each routine if operation is nil.
+	operation        Operation
+	routineOperation RoutineOperation
+
+	// The estimator scaling routines to meet the execution's deadline, or nil
+	// if the execution has no deadline.
+	deadline *deadlineEstimator
+
+	// The execution's membership in the process' group.
+	groupMember *groupMember
+
+	// The context the execution was started with, or nil, and the span
+	// tracing the execution, or nil if the process has no tracer.
+	ctx  context.Context
+	span ExecutionSpan
+}
+
+// MARK: Initializers
+
+// newVariableExecution creates and returns a new execution of the specified
+// number of iterations.
+func newVariableExecution(iterations int, operation Operation, deadline *deadlineEstimator) *variableExecution {
+	x := &variableExecution{
+		iterations: iterations,
+		operation:  operation,
+		deadline:   deadline,
+		done:       make(chan struct{}),
+	}
+	x.cond = sync.NewCond(&x.mutex)
+	return x
+}
+
+// MARK: Private methods
+
+// routines returns the number of routines the execution is using.
+func (x *variableExecution) routines() int {
+	return int(atomic.LoadInt64(&x.numRoutines))
+}
+
+// routineIndex returns the index of a routine that is starting, counting the
+// routines the execution has started.
+func (x *variableExecution) routineIndex() int {
+	return int(atomic.AddInt64(&x.startedRoutines, 1) - 1)
+}
+
+// context returns the context the execution was started with.
+func (x *variableExecution) context() context.Context {
+	if x.ctx == nil {
+		return context.Background()
+	}
+	return x.ctx
+}
+
+// next claims an iteration for the routine-th routine and returns false once
+// no iterations remain.
+func (x *variableExecution) next(routine int) (int, bool) {
+	if x.shards != nil {
+		return x.shards.claim(routine)
+	}
+
+	i := x.iteration.getAndAdd(1)
+	return i, i < x.iterations
+}
+
+// started returns the number of iterations that have begun.
+func (x *variableExecution) started() int {
+	if x.shards != nil {
+		return x.shards.started()
+	}
+	return minInt(x.iteration.get(), x.iterations)
+}
+
+// remaining returns the number of iterations that haven't begun.
+func (x *variableExecution) remaining() int {
+	return x.iterations - x.started()
+}
+
+// operationFor returns the operation the routine-th routine calls for each
+// iteration.
+func (x *variableExecution) operationFor(routine int) Operation {
+	if x.operation != nil {
+		return x.operation
+	}
+	return routineOperation(x.routineOperation, routine, &x.stopped)
+}
+
+// stop prevents any further iterations from beginning.
+func (x *variableExecution) stop() {
+	atomic.StoreInt32(&x.stopped, 1)
+	if x.shards != nil {
+		x.shards.stop()
+	}
+	x.iteration.set(x.iterations)
+}
+
+// add adds n running routines to the execution by waking parked routines and
+// returns the number of routines that must be started, and whether any may be.
+// Routines can't be added once every routine has exited.
+func (x *variableExecution) add(n int) (int, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.active == 0 {
+		return 0, false
+	}
+
+	woken := minInt(n, x.parked-x.wakeups)
+	x.wakeups += woken
+	for i := 0; i < woken; i++ {
+		x.cond.Signal()
+	}
+	if woken > 0 {
+		atomic.AddInt64(&x.generation, 1)
+	}
+
+	x.active += n
+	x.goroutines += n - woken
+	atomic.AddInt64(&x.numRoutines, int64(n))
+	return n - woken, true
+}
+
+// parkedRoutines returns the number of parked routines that haven't been
+// woken.
+func (x *variableExecution) parkedRoutines() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.parked - x.wakeups
+}
+
+// park parks a routine that was removed by an optimization, waiting with the
+// specified strategy. It returns true when a later optimization wakes the
+// routine, or false once the execution's other routines have exited and the
+// routine should exit too.
+func (x *variableExecution) park(strategy WaitStrategy) bool {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.active--
+	x.parked++
+	if x.active == 0 {
+		x.wakeAll()
+	}
+
+	spun := false
+	for x.wakeups == 0 && x.active > 0 {
+		if strategy == WaitPark || (strategy == WaitSpinThenPark && spun) {
+			x.cond.Wait()
+			continue
+		}
+
+		generation := atomic.LoadInt64(&x.generation)
+		x.mutex.Unlock()
+		strategy.spin(&x.generation, generation)
+		x.mutex.Lock()
+		spun = true
+	}
+
+	x.parked--
+	if x.wakeups > 0 {
+		x.wakeups--
+		return true
+	}
+	return false
+}
+
+// exit records that a running routine exited. Parked routines exit with the
+// last running routine.
+func (x *variableExecution) exit() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.active--
+	if x.active == 0 {
+		x.wakeAll()
+	}
+}
+
+// returned records that one of the execution's goroutines returned.
+func (x *variableExecution) returned() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.goroutines--
+	if x.goroutines == 0 {
+		close(x.done)
+	}
+}
+
+// wait waits for every one of the execution's goroutines to return.
+func (x *variableExecution) wait() {
+	<-x.done
+}
+
+// wakeAll wakes every parked routine so that they exit. The mutex must be
+// held.
+func (x *variableExecution) wakeAll() {
+	x.cond.Broadcast()
+	atomic.AddInt64(&x.generation, 1)
+}
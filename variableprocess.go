@@ -1,95 +1,256 @@
 package parallel
 
 import (
+	"context"
 	"math"
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/colinc86/probes"
 )
 
 // VariableProcess types execute a specified number of operations on a variable
 // number of goroutines.
 type VariableProcess struct {
-
-	// The CPU probe.
-	CPUProbe *probes.Probe
-
-	// The error probe.
-	ErrorProbe *probes.Probe
-
-	// The PID output probe.
-	PIDProbe *probes.Probe
-
-	// The routine probe.
-	RoutineProbe *probes.Probe
+	// The maximum number of goroutines to use when optimizing. It is updated
+	// atomically, so it comes first to be 64-bit aligned.
+	maxRoutines safeInt
 
 	// The number of iterations between optimizations.
 	optimizationInterval time.Duration
 
-	// The process' wait group to use when waiting for goroutines to
-	// finish their execution.
-	group sync.WaitGroup
+	// The clock the process tells time with.
+	clock Clock
 
-	// The ticker responsible for triggering an optimization.
-	ticker *time.Ticker
-
-	// The number of goroutines the process should use when divvying up
-	// operations.
-	numRoutines int64
+	// The ticker responsible for triggering an optimization, and a channel
+	// closed to stop the goroutine receiving its ticks. Both are nil while the
+	// process isn't executing.
+	ticker     Ticker
+	tickerDone chan struct{}
 
 	// The initial number of goroutines that should be used when Execute is
 	// called.
 	initialRoutines int
 
-	// The maximum number of goroutines to use when optimizing.
-	maxRoutines safeInt
+	// The executions that are currently running.
+	executions []*variableExecution
 
-	// The number of iterations in the current execution that have begun.
-	iteration safeInt
+	// Whether or not Stop was called since the first of the running
+	// executions began.
+	stopped bool
 
-	// The total number of iterations specified by the last call to Execute.
-	iterations int
+	// A mutex to protect the running executions and the ticker.
+	executionsMutex sync.Mutex
 
-	// The operation function called for each iteration of the process.
-	operation Operation
+	// The CPU reporter used to calculate CPU throughput.
+	reporter usageReporter
 
-	// The number of routines to remove after optimizing.
-	numToRemove int64
+	// The number of usage samples taken per optimization, and the samples
+	// taken since the last optimization.
+	samplesPerOptimization int
+	usageSamples           []float64
 
-	// The CPU reporter used to calculate CPU throughput.
-	reporter *reporter
+	// The source the reporter measures CPU usage from.
+	reporterSource ReporterSource
+
+	// The number of CPUs usage is normalized by, or zero to use GOMAXPROCS.
+	cpuCount int
+
+	// The time at the start of each execution during which usage is sampled
+	// but not acted upon, and when the running executions started.
+	warmUp      time.Duration
+	warmUpStart time.Time
+
+	// The reporter of the garbage collector's CPU usage, and whether or not
+	// it is subtracted from the usage the controller responds to.
+	gc         *gcReporter
+	subtractGC bool
+
+	// The smoother of the usage the controller responds to.
+	smoother usageSmoother
+
+	// The most recent samples of the reporter's usage.
+	history *usageHistory
+
+	// The reporter of goroutine scheduling latencies.
+	scheduling schedulingReporter
+
+	// The reporter of the time the program's CPU quota throttled it.
+	throttling *throttlingReporter
 
 	// A PID controller for controlling the number of goroutines.
-	controller *controller
+	controller *PIDController
 
 	// A mutex to protect against simultaneous read/write to controller variables.
 	controllerMutex sync.Mutex
 
+	// The optimizer choosing the number of routines.
+	optimizer Optimizer
+
+	// The optimizer used when the process' optimizer is OptimizerAIMD.
+	aimd aimdOptimizer
+
+	// The meter measuring throughput for the throughput optimizer.
+	meter throughputMeter
+
+	// The optimizer used when the process' optimizer is OptimizerLatency, and
+	// the recorder of its operations' latencies. The recorder is nil for other
+	// optimizers.
+	latency   latencyOptimizer
+	latencies *latencyRecorder
+
+	// The histogram of every operation's latency, or nil if latencies aren't
+	// recorded.
+	histogram *LatencyHistogram
+
+	// The meter measuring the process' throughput.
+	throughput *ThroughputMeter
+
+	// The optimizer used when the process' optimizer is OptimizerEnergy, and
+	// the reporter of the machine's energy usage. The reporter is nil for
+	// other optimizers or if the energy can't be read.
+	energy         energyOptimizer
+	energyReporter energyReporter
+
+	// The number of iterations begun by executions that have ended.
+	endedIterations int
+
+	// Whether or not executions start where the previous one converged.
+	warmStart bool
+
+	// The cache the process' converged state is stored in between runs of
+	// the program, or nil, and the workload it is stored for.
+	calibration         *CalibrationCache
+	calibrationWorkload string
+
+	// Whether or not GOMAXPROCS follows the number of routines while the
+	// process executes, and the process' claim on it while it does.
+	adjustMaxProcs bool
+	maxProcs       *maxProcsClaim
+
+	// The number of routines the optimizer called for at the last
+	// optimization, or zero if the process hasn't optimized.
+	convergedRoutines int
+
+	// Whether or not the controller is tuned at the start of each execution.
+	autoTune bool
+
+	// The relay test tuning the controller, or nil if it isn't being tuned.
+	tuner *autoTuner
+
+	// The number of bytes each routine grows its stack to before executing.
+	stackHint int
+
+	// The name the process' routines are labelled with in profiles.
+	name string
+
+	// The number of shards each execution's iterations are claimed from, or
+	// zero if they are claimed from a single counter.
+	counterShards int
+
+	// How removed routines wait to be added back.
+	waitStrategy WaitStrategy
+
+	// The time without a completed iteration after which the process is
+	// stalled, or zero, and the handler called when it is.
+	stallTimeout time.Duration
+	stallHandler StallHandler
+
+	// The NUMA node routines are bound to, or -1, and the node's CPUs.
+	numaNode int
+	numaCPUs []int
+
+	// The CPUs routines are pinned to in turn, or nil.
+	cpuAffinity []int
+
+	// The goroutines prepared to run the process' routines, or nil.
+	pool *routinePool
+
 	// Whether or not the controller should be probed.
 	probeController bool
+
+	// The number of samples the controller history keeps, or zero if it is
+	// unbounded.
+	probeSignalLength int
+
+	// The sink recording the controller's history, or nil if the controller
+	// isn't probed, and the sink set by the user, if any.
+	controllerHistory *controllerHistory
+	metricsSink       MetricsSink
+
+	// The tracer tracing the process' executions, or nil.
+	tracer ExecutionTracer
+
+	// The recorder tracing the process' scheduling events, or nil.
+	trace *TraceRecorder
+
+	// The stats of the routines of the current or last run of executions.
+	routineStats routineStatsRecorder
+
+	// The listeners told about the process' scaling decisions.
+	listeners listenerSet
+
+	// The stream of the process' optimizations, and the number of
+	// optimizations in the current run of executions.
+	events eventStream
+	ticks  int
+
+	// The publisher of the controller's samples to the process' sinks while
+	// the process is executing.
+	publisher *probePublisher
+
+	// The claimed and completed iteration counts of the running executions.
+	progress *progressCounter
+
+	// The group whose goroutine budget the process' routines draw from.
+	processGroup *ProcessGroup
+
+	// The barrier pausing the process' routines for checkpoints.
+	checkpoints *checkpointBarrier
+
+	// The record of the last execution.
+	record executionRecord
+
+	// The timer of the current or last run of executions.
+	timer executionTimer
+
+	// The controller's signals at the last optimization, or nil if the
+	// process hasn't optimized during the current run of executions.
+	lastSample *ControllerSample
 }
 
 // MARK: Initializers
 
 // NewVariableProcess creates and returns a new parallel process with the
-// specified optimization interval.
+// specified optimization interval. If probeController is true, the process
+// records the controller's samples in its controller history.
 func NewVariableProcess(interval time.Duration, initialRoutines int, maxRoutines int, controllerConfiguration *ControllerConfiguration, probeController bool) *VariableProcess {
+	checkInterval("NewVariableProcess", interval)
+	checkRoutines("NewVariableProcess", "initialRoutines", initialRoutines)
+	checkRoutines("NewVariableProcess", "maxRoutines", maxRoutines)
+	if controllerConfiguration == nil {
+		misuse("NewVariableProcess called with a nil controller configuration")
+	}
+
 	p := &VariableProcess{
-		optimizationInterval: interval,
-		initialRoutines:      initialRoutines,
-		maxRoutines:          safeInt{value: maxRoutines},
-		reporter:             newReporter(),
-		controller:           newController(controllerConfiguration),
-		probeController:      probeController,
+		optimizationInterval:   interval,
+		initialRoutines:        initialRoutines,
+		maxRoutines:            safeInt{value: int64(maxRoutines)},
+		clock:                  SystemClock{},
+		samplesPerOptimization: 1,
+		numaNode:               -1,
+		reporter:               newReporter(SystemClock{}),
+		gc:                     newGCReporter(),
+		history:                newUsageHistory(defaultUsageHistoryLength),
+		throttling:             newThrottlingReporter(),
+		throughput:             newThroughputMeter(defaultThroughputWindow, SystemClock{}),
+		controller:             newController(controllerConfiguration),
+		probeController:        probeController,
+		progress:               &progressCounter{},
+		checkpoints:            newCheckpointBarrier(),
 	}
 
 	if probeController {
-		p.CPUProbe = probes.NewProbe()
-		p.ErrorProbe = probes.NewProbe()
-		p.PIDProbe = probes.NewProbe()
-		p.RoutineProbe = probes.NewProbe()
+		p.controllerHistory = newControllerHistory()
 	}
 
 	return p
@@ -98,52 +259,169 @@ func NewVariableProcess(interval time.Duration, initialRoutines int, maxRoutines
 // MARK: Public methods
 
 // Execute executes the parallel process for the specified number of operations
-// while optimizing every interval iterations.
+// while optimizing every interval iterations. Execute returns immediately if
+// iterations is zero, and never runs more routines than there are iterations.
+//
+// Execute may be called again while the process is executing. The executions
+// share the process' controller, which divides the routines it calls for
+// between them in proportion to their remaining iterations.
 func (p *VariableProcess) Execute(iterations int, operation Operation) {
-	if p.probeController {
-		p.CPUProbe.Activate()
-		p.ErrorProbe.Activate()
-		p.PIDProbe.Activate()
-		p.RoutineProbe.Activate()
-	}
-
-	p.iterations = iterations
-	p.operation = operation
-	p.reset()
+	checkOperation("VariableProcess.Execute", operation == nil)
+	p.execute(newVariableExecution(iterations, operation, nil))
+}
 
-	p.group.Add(p.initialRoutines)
-	for n := 0; n < p.initialRoutines; n++ {
-		go p.runRoutine()
-	}
+// ExecuteRoutines executes the variable process for the specified number of
+// operations, passing each operation the context of the routine executing it.
+// A routine removed by an optimization keeps its context while it is parked,
+// so its scratch storage survives being added back.
+func (p *VariableProcess) ExecuteRoutines(iterations int, operation RoutineOperation) {
+	checkOperation("VariableProcess.ExecuteRoutines", operation == nil)
+	x := newVariableExecution(iterations, nil, nil)
+	x.routineOperation = operation
+	p.execute(x)
+}
 
-	go p.beginOptimizing()
+// ExecuteDeadline executes the variable process for the specified number of
+// operations, scaling the number of routines to finish by deadline instead of
+// to saturate the CPUs. The number of routines is based on the throughput
+// measured at each optimization and is still limited by the process' maximum
+// number of routines.
+//
+// The deadline is a target rather than a limit; operations keep executing
+// after it passes. Use ExecuteContext with a context deadline to stop them.
+func (p *VariableProcess) ExecuteDeadline(deadline time.Time, iterations int, operation Operation) {
+	checkOperation("VariableProcess.ExecuteDeadline", operation == nil)
+	p.execute(newVariableExecution(iterations, operation, &deadlineEstimator{deadline: deadline}))
+}
 
-	p.group.Wait()
-	p.ticker.Stop()
+// ExecuteContext executes the variable process for the specified number of
+// operations, passing ctx to each operation. Routines added by the optimizer
+// receive the same context. The execution stops when ctx is done and the
+// context's error is returned. Other executions of the process are unaffected.
+func (p *VariableProcess) ExecuteContext(ctx context.Context, iterations int, operation ContextOperation) error {
+	checkOperation("VariableProcess.ExecuteContext", operation == nil)
 
-	if p.probeController {
-		p.CPUProbe.Flush()
-		p.ErrorProbe.Flush()
-		p.PIDProbe.Flush()
-		p.RoutineProbe.Flush()
+	x := newVariableExecution(iterations, nil, nil)
+	x.ctx = ctx
+	err := runContext(ctx, func(op Operation) {
+		x.operation = op
+		p.execute(x)
+	}, x.stop, operation)
 
-		p.CPUProbe.Deactivate()
-		p.ErrorProbe.Deactivate()
-		p.PIDProbe.Deactivate()
-		p.RoutineProbe.Deactivate()
+	if err != nil {
+		p.record.err = err
 	}
+	return err
 }
 
-// Stop stops the variable process after all of the current operations have
-// finished executing.
+// Stop stops every execution of the variable process after all of the current
+// operations have finished executing.
 func (p *VariableProcess) Stop() {
-	p.iteration.set(p.iterations)
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	p.stopped = true
+	for _, x := range p.executions {
+		x.stop()
+	}
 }
 
 // NumRoutines returns the number of routines that the variable processes is
-// currently using.
+// currently using across all of its executions.
 func (p *VariableProcess) NumRoutines() int {
-	return int(atomic.LoadInt64(&p.numRoutines))
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	return p.numRoutines()
+}
+
+// Progress returns the progress of the current or last execution. Routines
+// reconcile their counts periodically, so the progress of a running execution
+// may lag slightly behind; the progress of a finished execution is exact. The
+// progress of executions that run at the same time is combined.
+func (p *VariableProcess) Progress() Progress {
+	return p.progress.snapshot()
+}
+
+// CheckpointNow pauses the process' routines before they claim any more
+// iterations, waits for the iterations in flight to finish, and calls writer
+// with the exact progress of the execution before resuming. Every claimed
+// iteration has completed when writer is called, so with ScheduleDynamic the
+// completed iterations are exactly the first Completed indices. Schedules that
+// hand out large chunks wait for the routines' current chunks to finish. If the
+// process isn't executing, writer receives the progress of the last execution.
+// It must not be called from the process' operations.
+func (p *VariableProcess) CheckpointNow(writer CheckpointWriter) error {
+	checkOperation("VariableProcess.CheckpointNow", writer == nil)
+	return p.checkpoints.checkpoint(p.progress, writer)
+}
+
+// Stats returns a snapshot of the current or last execution, combining
+// executions that run at the same time. The completed iterations may lag
+// behind those of a running execution like its progress.
+func (p *VariableProcess) Stats() ProcessStats {
+	p.executionsMutex.Lock()
+	routines := p.numRoutines()
+	var sample *ControllerSample
+	if p.lastSample != nil {
+		s := *p.lastSample
+		sample = &s
+	}
+	p.executionsMutex.Unlock()
+
+	progress := p.Progress()
+	return ProcessStats{
+		Routines:   routines,
+		Completed:  progress.Completed,
+		Iterations: progress.Iterations,
+		Elapsed:    p.timer.elapsed(),
+		Controller: sample,
+	}
+}
+
+// RoutineStats returns the number of iterations each routine of the current
+// or last run of executions completed and how long it was busy, so the
+// distribution of work can be checked. Routines are included once they exit,
+// including those removed while optimizing.
+func (p *VariableProcess) RoutineStats() []RoutineStats {
+	return p.routineStats.snapshot()
+}
+
+// ControllerState returns the state of the process' PID controller.
+func (p *VariableProcess) ControllerState() PIDState {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.controller.State()
+}
+
+// Summary returns a summary of the last execution. Errors returned by
+// ExecuteContext are included; other errors can be added with RecordError. If
+// the process probes its controller, the summary contains the controller's
+// history.
+func (p *VariableProcess) Summary() *RunSummary {
+	s := p.record.summary(SummaryConfiguration{
+		Process:              "VariableProcess",
+		Routines:             p.initialRoutines,
+		MaxRoutines:          p.GetMaxRoutines(),
+		OptimizationInterval: p.optimizationInterval,
+		Optimizer:            p.Optimizer().String(),
+		Controller:           p.GetControllerConfiguration(),
+		StackHint:            p.stackHint,
+	}, p.Progress())
+
+	s.ControllerHistory = p.ControllerHistory()
+	s.Stats.Routines = p.RoutineStats()
+	return s
+}
+
+// ControllerHistory returns the controller's samples at each optimization of
+// the current or last run of executions, oldest first, or nil if the process
+// doesn't probe its controller. SetProbeSignalLength bounds the number of
+// samples kept.
+func (p *VariableProcess) ControllerHistory() []ControllerSample {
+	if p.controllerHistory == nil {
+		return nil
+	}
+	return p.controllerHistory.snapshot()
 }
 
 // GetOptimizationInterval returns the interval of the process' ticker.
@@ -152,11 +430,21 @@ func (p *VariableProcess) GetOptimizationInterval() time.Duration {
 }
 
 // SetOptimizationInterval sets the optimization interval and restarts the
-// process' ticker.
+// process' ticker. The interval must be positive.
 func (p *VariableProcess) SetOptimizationInterval(interval time.Duration) {
-	p.ticker.Stop()
+	checkInterval("VariableProcess.SetOptimizationInterval", interval)
+
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	if p.ticker == nil {
+		p.optimizationInterval = interval
+		return
+	}
+
+	p.stopTicker()
 	p.optimizationInterval = interval
-	go p.beginOptimizing()
+	p.startTicker()
 }
 
 // GetMaxRoutines returns the maximum number of goroutines to use when
@@ -168,6 +456,7 @@ func (p *VariableProcess) GetMaxRoutines() int {
 // SetMaxRoutines sets the maximum number of goroutines to use when optimizing.
 // Must be greater than 0.
 func (p *VariableProcess) SetMaxRoutines(n int) {
+	checkRoutines("VariableProcess.SetMaxRoutines", "n", n)
 	p.maxRoutines.set(n)
 }
 
@@ -178,106 +467,1388 @@ func (p *VariableProcess) GetControllerConfiguration() *ControllerConfiguration
 	return p.controller.configuration.Copy()
 }
 
-// SetControllerConfiguration sets the PID controller coefficients.
+// SetControllerConfiguration sets the PID controller coefficients. The
+// configuration can be changed while the process is executing; the
+// controller's integral term is rescaled so that the number of routines
+// doesn't jump when the gains change.
 func (p *VariableProcess) SetControllerConfiguration(configuration *ControllerConfiguration) {
+	if configuration == nil {
+		misuse("VariableProcess.SetControllerConfiguration called with a nil configuration")
+	}
+
 	p.controllerMutex.Lock()
 	defer p.controllerMutex.Unlock()
 
-	p.controller.configuration = configuration
+	p.controller.SetConfiguration(configuration)
+}
+
+// ReporterSource returns the source the process measures its CPU usage from.
+func (p *VariableProcess) ReporterSource() ReporterSource {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.reporterSource
+}
+
+// SetReporterSource sets the source the process measures its CPU usage from.
+// The default, ReporterSourceClock, measures the CPU time used by the whole
+// program. ReporterSourceRuntimeMetrics doesn't need cgo and reflects the
+// runtime's view of the program, including garbage collection, but only
+// changes once per garbage collection cycle. ReporterSourceSystem measures the
+// whole machine, so the process leaves CPUs to the other processes on its
+// host. It must not be called while the process is executing.
+func (p *VariableProcess) SetReporterSource(source ReporterSource) {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.reporterSource = source
+	p.reporter = newUsageReporter(source, p.clock)
+}
+
+// WarmUp returns the time at the start of each execution during which the
+// process samples its usage without changing its number of routines.
+func (p *VariableProcess) WarmUp() time.Duration {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.warmUp
+}
+
+// SetWarmUp sets the time at the start of each execution during which the
+// process samples its usage without changing its number of routines. Usage
+// measured while an execution starts up, before its operations reach a steady
+// state, is often far from representative and can send the controller to the
+// maximum number of routines at the first optimization. The samples are still
+// recorded in the usage history and smoothed. The default, zero, optimizes
+// from the first interval. It must not be called while the process is
+// executing.
+func (p *VariableProcess) SetWarmUp(d time.Duration) {
+	if d < 0 {
+		misuse("VariableProcess.SetWarmUp called with %s; the warm up must not be negative", d)
+	}
+
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.warmUp = d
+}
+
+// UsageSamples returns the number of times the process samples its CPU usage
+// per optimization.
+func (p *VariableProcess) UsageSamples() int {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.samplesPerOptimization
+}
+
+// SetUsageSamples sets the number of times the process samples its CPU usage
+// per optimization. The samples are evenly spaced over the optimization
+// interval and the controller responds to their median, so a single unlucky
+// sample doesn't dominate the decision. The default is one sample. It must not
+// be called while the process is executing.
+func (p *VariableProcess) SetUsageSamples(n int) {
+	if n < 1 {
+		misuse("VariableProcess.SetUsageSamples called with n = %d; at least one sample must be taken", n)
+	}
+
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.samplesPerOptimization = n
+}
+
+// MetricsSink returns the sink the process records its controller's signals
+// with, or nil if it has none.
+func (p *VariableProcess) MetricsSink() MetricsSink {
+	return p.metricsSink
+}
+
+// SetMetricsSink sets a sink to record the controller's signals with at each
+// optimization, in addition to the process' controller history. Sinks let
+// processes report to an application's own metrics stack, and the probesink
+// package adapts the github.com/colinc86/probes package to a sink. A nil
+// sink removes the process' sink. It must not be called while the process is
+// executing.
+func (p *VariableProcess) SetMetricsSink(sink MetricsSink) {
+	p.metricsSink = sink
+}
+
+// AddListener registers l to be told about the process' scaling decisions. It
+// may be called while the process is executing.
+func (p *VariableProcess) AddListener(l Listener) {
+	if l == nil {
+		misuse("VariableProcess.AddListener called with a nil listener")
+	}
+	p.listeners.add(l)
+}
+
+// RemoveListener unregisters l and returns whether or not it was registered.
+func (p *VariableProcess) RemoveListener(l Listener) bool {
+	return p.listeners.remove(l)
+}
+
+// Events returns a channel receiving an event for each of the process'
+// optimizations. The channel buffers the most recent events; events are
+// dropped rather than delaying the process when it is full. The channel is
+// never closed, and every call returns the same channel.
+func (p *VariableProcess) Events() <-chan Event {
+	return p.events.channel()
+}
+
+// TraceRecorder returns the recorder tracing the process' scheduling events,
+// or nil if they aren't traced.
+func (p *VariableProcess) TraceRecorder() *TraceRecorder {
+	return p.trace
+}
+
+// SetTraceRecorder sets the recorder tracing the process' scheduling events.
+// Ticks, the usage and sample of each optimization, the routines each
+// optimization adds or removes and every iteration claimed are recorded.
+// Recording adds a small overhead to every claim. A nil recorder stops
+// tracing. It must not be called while the process is executing.
+func (p *VariableProcess) SetTraceRecorder(recorder *TraceRecorder) {
+	p.trace = recorder
+}
+
+// Tracer returns the tracer tracing the process' executions, or nil if it has
+// none.
+func (p *VariableProcess) Tracer() ExecutionTracer {
+	return p.tracer
+}
+
+// SetTracer sets the tracer tracing the process' executions. Each execution
+// starts a span that records every optimization and ends with the execution's
+// final number of routines and duration. A nil tracer stops tracing. It must
+// not be called while the process is executing.
+func (p *VariableProcess) SetTracer(tracer ExecutionTracer) {
+	p.tracer = tracer
+}
+
+// Clock returns the clock the process tells time with.
+func (p *VariableProcess) Clock() Clock {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.clock
+}
+
+// SetClock sets the clock the process tells time with and creates its
+// optimization tickers with. The default is the system clock. A manual clock
+// lets tests advance time to trigger optimizations deterministically instead
+// of sleeping. It must not be called while the process is executing.
+func (p *VariableProcess) SetClock(clock Clock) {
+	if clock == nil {
+		misuse("VariableProcess.SetClock called with a nil clock")
+	}
+
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.clock = clock
+	p.reporter = newUsageReporter(p.reporterSource, clock)
+	p.throughput.setClock(clock)
+}
+
+// CPUCount returns the number of CPUs the process' usage is normalized by, or
+// zero if it is normalized by GOMAXPROCS.
+func (p *VariableProcess) CPUCount() int {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.cpuCount
+}
+
+// SetCPUCount sets the number of CPUs the process' usage is normalized by. The
+// controller's setpoint is full usage of that many CPUs. The default, zero,
+// uses runtime.GOMAXPROCS(0) at the start of each execution, since a program
+// limited to fewer Ps than the machine has CPUs can never use them all. It
+// must not be called while the process is executing.
+func (p *VariableProcess) SetCPUCount(n int) {
+	if n < 0 {
+		misuse("VariableProcess.SetCPUCount called with n = %d; the count must not be negative", n)
+	}
+
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.cpuCount = n
+}
+
+// Throughput returns the meter measuring the number of iterations the process
+// completes per second. The meter is sampled at each of the process'
+// optimizations.
+func (p *VariableProcess) Throughput() *ThroughputMeter {
+	return p.throughput
+}
+
+// RecordLatencies returns whether or not the process records the latency of
+// each operation.
+func (p *VariableProcess) RecordLatencies() bool {
+	return p.histogram != nil
+}
+
+// SetRecordLatencies sets whether or not the process records the latency of
+// each of its operations in a histogram, which Latencies returns. Timing each
+// operation adds a small overhead, so latencies aren't recorded by default.
+// It must not be called while the process is executing.
+func (p *VariableProcess) SetRecordLatencies(enabled bool) {
+	if !enabled {
+		p.histogram = nil
+	} else if p.histogram == nil {
+		p.histogram = newLatencyHistogram()
+	}
+}
+
+// Latencies returns a snapshot of the histogram of the current or last
+// execution's operation latencies, or nil if the process doesn't record them.
+// The latencies of executions that run at the same time are combined.
+func (p *VariableProcess) Latencies() *LatencyHistogram {
+	if p.histogram == nil {
+		return nil
+	}
+	return p.histogram.snapshot()
+}
+
+// SubtractGCUsage returns whether or not the CPUs used by the garbage
+// collector are subtracted from the usage the controller responds to.
+func (p *VariableProcess) SubtractGCUsage() bool {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.subtractGC
+}
+
+// SetSubtractGCUsage sets whether or not the CPUs used by the garbage
+// collector are subtracted from the usage the controller responds to. Heavy
+// garbage collection otherwise looks like useful work, and the controller adds
+// fewer routines than the workload could use. With Go versions before 1.20 the
+// collector's average usage since the program started is subtracted.
+func (p *VariableProcess) SetSubtractGCUsage(enabled bool) {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	if enabled && !p.subtractGC {
+		p.gc.reset()
+	}
+	p.subtractGC = enabled
+}
+
+// UsageHistory returns the most recent CPU usage samples measured by the
+// process' reporter during its last execution, oldest first. Unlike the
+// controller history, the usage history is always recorded, so the raw feedback signal
+// can be inspected after an execution without probing the controller.
+func (p *VariableProcess) UsageHistory() []UsageSample {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.history.history()
+}
+
+// WaitStrategy returns how the routines the process removes wait to be added
+// back.
+func (p *VariableProcess) WaitStrategy() WaitStrategy {
+	return p.waitStrategy
+}
+
+// SetWaitStrategy sets how the routines the process removes wait until a
+// later optimization adds them back. Spinning strategies wake routines sooner
+// at the cost of the CPU time they spin for, which suits latency-sensitive
+// workloads whose controller oscillates around its setpoint. The controller
+// sees the time spent spinning as CPU usage. The default is
+// WaitPark. It must not be called while the process is executing.
+func (p *VariableProcess) SetWaitStrategy(strategy WaitStrategy) {
+	p.waitStrategy = strategy
+}
+
+// CounterShards returns the number of shards each execution's iterations are
+// claimed from, or zero if they are claimed from a single counter.
+func (p *VariableProcess) CounterShards() int {
+	return p.counterShards
+}
+
+// SetCounterShards divides each execution's iterations between n shards with
+// their own counters. Each routine claims iterations from one of the shards
+// and steals from the fullest shard once its own is empty, so that processes
+// running hundreds of routines, far more than there are CPUs, don't contend
+// on a single counter. A shard per CPU is a good starting point. Iterations
+// are no longer begun in order. Zero, the default, and one claim iterations
+// from a single counter. It must not be called while the process is
+// executing.
+func (p *VariableProcess) SetCounterShards(n int) {
+	if n < 0 {
+		misuse("VariableProcess.SetCounterShards called with n = %d; the number of shards must not be negative", n)
+	}
+	p.counterShards = n
+}
+
+// StallTimeout returns the time without a completed iteration after which the
+// process' stall handler is called, or zero if the watchdog is disabled.
+func (p *VariableProcess) StallTimeout() time.Duration {
+	return p.stallTimeout
+}
+
+// SetStallTimeout sets the watchdog of the process' executions. If no
+// iteration completes for timeout while the process is executing, handler is
+// called with the process' stats so that a deadlocked or blocked operation can
+// be diagnosed, for example by logging the stacks returned by runtime.Stack.
+// The handler is called once per stall, from its own goroutine. A zero
+// timeout, the default, disables the watchdog. It must not be called while the
+// process is executing.
+func (p *VariableProcess) SetStallTimeout(timeout time.Duration, handler StallHandler) {
+	checkStallTimeout("VariableProcess.SetStallTimeout", timeout, handler)
+	p.stallTimeout = timeout
+	p.stallHandler = handler
+}
+
+// ProbeSignalLength returns the number of samples the process' controller
+// history keeps, or zero if the history is unbounded.
+func (p *VariableProcess) ProbeSignalLength() int {
+	return p.probeSignalLength
+}
+
+// SetProbeSignalLength sets the number of samples the process' controller
+// history keeps. Once the history is full its oldest samples are discarded, so
+// long probed executions have a predictable memory footprint and the history,
+// and the controller history of the process' summary, hold the last n
+// optimizations. Zero, the default, keeps every sample. The length is applied
+// when the next execution begins. It must not be called while the process is
+// executing.
+func (p *VariableProcess) SetProbeSignalLength(n int) {
+	if n < 0 {
+		misuse("VariableProcess.SetProbeSignalLength called with n = %d; the length must not be negative", n)
+	}
+
+	p.probeSignalLength = n
+}
+
+// SetUsageHistoryLength sets the number of usage samples the process retains.
+// The default is 256, and zero disables the history.
+func (p *VariableProcess) SetUsageHistoryLength(n int) {
+	if n < 0 {
+		misuse("VariableProcess.SetUsageHistoryLength called with n = %d; the length must not be negative", n)
+	}
+
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.history.resize(n)
+}
+
+// Optimizer returns the optimizer the process uses to choose its number of
+// routines.
+func (p *VariableProcess) Optimizer() Optimizer {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.optimizer
+}
+
+// SetOptimizer sets the optimizer the process uses to choose its number of
+// routines. The default, OptimizerPID, drives CPU usage towards full
+// utilization with the process' controller. OptimizerAIMD needs no
+// configuration and adds routines while throughput improves.
+// OptimizerThroughput drives throughput towards the controller
+// configuration's throughput target, OptimizerLatency keeps operation
+// latencies below its latency target, and OptimizerEnergy minimizes the energy
+// used per iteration. It must not be called while the process is executing.
+func (p *VariableProcess) SetOptimizer(optimizer Optimizer) {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.optimizer = optimizer
+
+	p.latencies = nil
+	if optimizer == OptimizerLatency {
+		p.latencies = newLatencyRecorder()
+	}
+
+	p.energyReporter = nil
+	if optimizer == OptimizerEnergy {
+		p.energyReporter = newEnergyReporter()
+	}
+}
+
+// AutoTune returns whether or not the process tunes its controller at the
+// start of each execution.
+func (p *VariableProcess) AutoTune() bool {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	return p.autoTune
+}
+
+// SetAutoTune sets whether or not the process tunes its controller at the
+// start of each execution. Only the PID optimizer is tuned. While tuning, the
+// process runs a relay test that switches between two routine counts, making
+// CPU usage oscillate. The amplitude and period of the oscillation give Kp, Ki
+// and Kd by the Ziegler-Nichols rules, which replace the coefficients of the
+// process' controller configuration. If the workload doesn't oscillate within
+// a few dozen optimizations, the configuration is left unchanged.
+//
+// The tuned configuration can be read with GetControllerConfiguration, so a
+// workload can be tuned once and auto-tuning disabled for later executions.
+func (p *VariableProcess) SetAutoTune(enabled bool) {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+	p.autoTune = enabled
+}
+
+// StackHint returns the number of bytes each of the process' routines grows
+// its stack to before executing.
+func (p *VariableProcess) StackHint() int {
+	return p.stackHint
+}
+
+// SetStackHint sets the number of bytes each of the process' routines grows
+// its stack to before executing its first iteration. Operations that recurse
+// deeply otherwise outgrow their routines' stacks several times, and each
+// time the runtime copies the whole stack to a new one. Because the process
+// starts new routines as it optimizes, hinting saves a copy for each of them.
+// A hint of zero, the default, leaves the stacks alone. It must not be called
+// while the process is executing.
+func (p *VariableProcess) SetStackHint(bytes int) {
+	checkStackHint("VariableProcess.SetStackHint", bytes)
+	p.stackHint = bytes
+}
+
+// Name returns the name the process' routines are labelled with in profiles.
+func (p *VariableProcess) Name() string {
+	return p.name
+}
+
+// SetName sets the name the process' routines are labelled with in profiles.
+// The routines of a named process carry the pprof labels parallel.process,
+// the name, and parallel.routine, the order in which the execution started
+// the routine, along with the labels of the context given to ExecuteContext.
+// An empty name, the default, leaves the labels alone. It must not be called
+// while the process is executing.
+func (p *VariableProcess) SetName(name string) {
+	p.name = name
+}
+
+// NUMANode returns the NUMA node the process binds its routines to, or -1 if
+// they aren't bound.
+func (p *VariableProcess) NUMANode() int {
+	return p.numaNode
+}
+
+// SetNUMANode binds the process' routines to the CPUs of a NUMA node. On
+// machines with several nodes, operations that are bound by memory run
+// fastest on the node their memory is attached to, and routines on other
+// nodes only add cross-node traffic. Each routine locks itself to a thread
+// restricted to the node's CPUs. A node of -1, the default, doesn't bind the
+// routines, and neither does a node the machine doesn't have. Routines are
+// only bound on Linux. It must not be called while the process is executing.
+func (p *VariableProcess) SetNUMANode(node int) {
+	if node < -1 {
+		misuse("VariableProcess.SetNUMANode called with node %d; the node must be -1 or a node number", node)
+	}
+
+	p.numaNode = node
+	p.numaCPUs = nil
+	if node >= 0 {
+		p.numaCPUs = numaNodeCPUs(node)
+	}
+}
+
+// Prepare starts n goroutines that park until the process executes, so that
+// latency-critical callers don't pay for starting goroutines inside the timed
+// section. Routines the process starts, initially or when its controller adds
+// them, run on the prepared goroutines that are idle, and new goroutines are
+// started for the rest. The prepared goroutines park again once their
+// routines return. Calling Prepare again replaces the prepared goroutines,
+// and Prepare(0) exits them. It must not be called while the process is
+// executing.
+func (p *VariableProcess) Prepare(n int) {
+	checkPrepare("VariableProcess.Prepare", n)
+	if p.pool == nil {
+		p.pool = &routinePool{}
+	}
+	p.pool.resize(n)
+}
+
+// CPUAffinity returns the CPUs the process' routines are pinned to, or nil if
+// they aren't pinned.
+func (p *VariableProcess) CPUAffinity() []int {
+	return p.cpuAffinity
+}
+
+// SetCPUAffinity pins each of the process' routines to one of cpus, assigning
+// them in turn by the routines' indices within their execution. A pinned
+// routine locks itself to a thread restricted to its CPU, which keeps its
+// cache warm and its latency predictable at the cost of the runtime's freedom
+// to move it. Pinning takes precedence over the process' NUMA node. A nil or
+// empty slice, the default, leaves the routines unpinned. Routines are only
+// pinned on Linux. It must not be called while the process is executing.
+func (p *VariableProcess) SetCPUAffinity(cpus []int) {
+	checkCPUAffinity("VariableProcess.SetCPUAffinity", cpus)
+	p.cpuAffinity = append([]int(nil), cpus...)
+}
+
+// WarmStart returns whether or not executions start at the number of routines
+// and controller state where the previous execution converged.
+func (p *VariableProcess) WarmStart() bool {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	return p.warmStart
+}
+
+// SetWarmStart sets whether or not executions start at the number of routines
+// and controller state where the previous execution converged, rather than at
+// the process' initial number of routines with a reset controller. Repeated
+// executions of the same workload then skip the ramp up. The controller isn't
+// tuned again on a warm start.
+func (p *VariableProcess) SetWarmStart(enabled bool) {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	p.warmStart = enabled
+}
+
+// AdjustMaxProcs returns whether or not GOMAXPROCS follows the number of the
+// process' routines while it executes.
+func (p *VariableProcess) AdjustMaxProcs() bool {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	return p.adjustMaxProcs
+}
+
+// SetAdjustMaxProcs sets whether or not GOMAXPROCS is raised and lowered with
+// the number of the process' routines while it executes, and restored
+// afterwards. Routines of CPU-bound operations beyond GOMAXPROCS only add
+// scheduler overhead. Processes adjusting GOMAXPROCS at the same time share
+// it, and it is never raised above its value before the first of them began.
+// The controller's setpoint is still taken from that value, so usage should
+// be measured with the clock reporter source. It must not be called while the
+// process is executing.
+func (p *VariableProcess) SetAdjustMaxProcs(enabled bool) {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	p.adjustMaxProcs = enabled
+}
+
+// CalibrationCache returns the cache the process stores its converged state
+// in, or nil, and the workload the state is stored for.
+func (p *VariableProcess) CalibrationCache() (*CalibrationCache, string) {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	return p.calibration, p.calibrationWorkload
+}
+
+// SetCalibrationCache sets the cache the process stores its converged number
+// of routines and controller state in for workload, so that later runs of the
+// same job start near the optimum instead of learning it again. The state is
+// stored each time the process finishes executing, and loaded by the first
+// execution if the process hasn't converged yet. Executions then warm start as
+// if SetWarmStart were enabled. Errors writing the cache are added to the
+// process' summary. A nil cache, the default, stores nothing. It must not be
+// called while the process is executing.
+func (p *VariableProcess) SetCalibrationCache(cache *CalibrationCache, workload string) {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	p.calibration = cache
+	p.calibrationWorkload = workload
+}
+
+// ProcessGroup returns the group whose goroutine budget the process draws
+// from, or nil if the process isn't a member of a group.
+func (p *VariableProcess) ProcessGroup() *ProcessGroup {
+	return p.processGroup
+}
+
+// SetProcessGroup makes the process a member of group, so that its routines
+// draw from the group's goroutine budget. A nil group removes the process from
+// its group. It must not be called while the process is executing.
+func (p *VariableProcess) SetProcessGroup(group *ProcessGroup) {
+	p.processGroup = group
 }
 
 // MARK: Private methods
 
-// reset resets all of the process' properties to their initial state.
-func (p *VariableProcess) reset() {
-	if p.probeController {
-		p.PIDProbe.ClearSignal()
-		p.CPUProbe.ClearSignal()
-		p.ErrorProbe.ClearSignal()
-		p.RoutineProbe.ClearSignal()
+// execute runs x to completion.
+func (p *VariableProcess) execute(x *variableExecution) {
+	checkIterations("VariableProcess.Execute", x.iterations)
+	p.checkOptimizerTarget()
+
+	if x.iterations == 0 {
+		p.executionsMutex.Lock()
+		idle := len(p.executions) == 0
+		if idle {
+			p.record.begin(p.stackHint > 0 || p.SubtractGCUsage())
+			p.progress.reset(0)
+			p.record.end()
+		}
+		p.executionsMutex.Unlock()
+
+		if idle {
+			p.listeners.completed(p.Summary)
+		}
+		return
+	}
+
+	initialRoutines := minInt(p.startingRoutines(), x.iterations)
+	if p.processGroup != nil {
+		x.groupMember = p.processGroup.join()
+		defer p.processGroup.leave(x.groupMember)
+
+		initialRoutines = p.processGroup.acquireAtLeastOne(x.groupMember, initialRoutines)
+	}
+
+	if p.tracer != nil {
+		x.span = p.tracer.StartExecution(x.context(), x.iterations)
+		start := p.clock.Now()
+		defer func() {
+			x.span.End(x.routines(), p.clock.Now().Sub(start))
+		}()
+	}
+
+	p.begin(x, initialRoutines)
+	defer func() {
+		if p.end(x) {
+			p.listeners.completed(p.Summary)
+		}
+	}()
+
+	watchdog := startWatchdog(p.stallTimeout, p.stallHandler, p.throughput, p.Stats)
+	x.wait()
+	watchdog.stop()
+}
+
+// checkOptimizerTarget panics if the process uses the throughput or latency
+// optimizer without a positive target.
+func (p *VariableProcess) checkOptimizerTarget() {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
+
+	if target := p.controller.configuration.ThroughputTarget; p.optimizer == OptimizerThroughput && !(target > 0.0) {
+		misuse("VariableProcess.Execute called with the throughput optimizer and a throughput target of %g; the target must be positive", target)
+	}
+
+	if target := p.controller.configuration.LatencyTarget; p.optimizer == OptimizerLatency && target <= 0 {
+		misuse("VariableProcess.Execute called with the latency optimizer and a latency target of %s; the target must be positive", target)
+	}
+}
+
+// wasStopped returns whether or not Stop was called during the last
+// executions.
+func (p *VariableProcess) wasStopped() bool {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+	return p.stopped
+}
+
+// startingRoutines returns the number of routines a new execution starts
+// with.
+func (p *VariableProcess) startingRoutines() int {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	if p.calibration != nil && p.convergedRoutines == 0 && len(p.executions) == 0 {
+		p.loadCalibration()
+	}
+
+	if p.warmStarting() {
+		return p.convergedRoutines
+	}
+	return p.initialRoutines
+}
+
+// warmStarting returns whether or not an execution beginning now continues
+// from where the previous one converged. The executions mutex must be held.
+func (p *VariableProcess) warmStarting() bool {
+	return (p.warmStart || p.calibration != nil) && p.convergedRoutines > 0 && len(p.executions) == 0
+}
+
+// loadCalibration loads the converged number of routines and controller state
+// from the process' calibration cache. The executions mutex must be held.
+func (p *VariableProcess) loadCalibration() {
+	entry, ok := p.calibration.Get(p.calibrationWorkload)
+	if !ok || entry.Routines < 1 {
+		return
+	}
+
+	p.convergedRoutines = minInt(entry.Routines, p.maxRoutines.get())
+	if entry.Controller != nil {
+		p.controllerMutex.Lock()
+		p.controller.restore(*entry.Controller)
+		p.controllerMutex.Unlock()
+	}
+}
+
+// saveCalibration stores the converged number of routines and controller state
+// in the process' calibration cache, keeping the rest of the workload's entry.
+// The executions mutex must be held.
+func (p *VariableProcess) saveCalibration() error {
+	if p.calibration == nil || p.convergedRoutines == 0 {
+		return nil
+	}
+
+	p.controllerMutex.Lock()
+	state := p.controller.State()
+	p.controllerMutex.Unlock()
+
+	entry, _ := p.calibration.Get(p.calibrationWorkload)
+	entry.Workload = p.calibrationWorkload
+	entry.Routines = p.convergedRoutines
+	entry.Controller = &state
+	entry.Updated = time.Time{}
+	return p.calibration.Set(entry)
+}
+
+// begin starts x's initial routines and adds it to the running executions. If
+// no other execution is running, the process is reset and its ticker started.
+func (p *VariableProcess) begin(x *variableExecution, initialRoutines int) {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	first := len(p.executions) == 0
+	if first {
+		p.stopped = false
+		p.record.begin(p.stackHint > 0 || p.SubtractGCUsage())
+		p.timer.start()
+		p.reset(x.iterations)
+	} else {
+		p.progress.add(x.iterations)
+	}
+
+	if x.deadline != nil {
+		x.deadline.reset(p.clock.Now())
+	}
+
+	if p.counterShards > 1 {
+		x.shards = newShardedCounter(x.iterations, p.counterShards)
+	}
+	x.numRoutines = int64(initialRoutines)
+	x.active = initialRoutines
+	x.goroutines = initialRoutines
+	for n := 0; n < initialRoutines; n++ {
+		p.pool.run(func() {
+			p.runRoutine(x)
+		})
+	}
+
+	p.executions = append(p.executions, x)
+	p.record.observeRoutines(p.numRoutines())
+
+	if first && p.adjustMaxProcs {
+		p.maxProcs = globalMaxProcs.acquire(p.numRoutines())
+	} else {
+		globalMaxProcs.set(p.maxProcs, p.numRoutines())
+	}
+
+	if first {
+		p.startTicker()
+	}
+}
+
+// end removes x from the running executions. If no other execution is
+// running, the process' ticker is stopped, its sinks are flushed and end
+// returns true.
+func (p *VariableProcess) end(x *variableExecution) bool {
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	for i, execution := range p.executions {
+		if execution == x {
+			p.executions = append(p.executions[:i], p.executions[i+1:]...)
+			break
+		}
+	}
+
+	p.endedIterations += x.started()
+	if len(p.executions) > 0 {
+		globalMaxProcs.set(p.maxProcs, p.numRoutines())
+		return false
+	}
+
+	p.stopTicker()
+	globalMaxProcs.release(p.maxProcs)
+	p.maxProcs = nil
+
+	if p.publisher != nil {
+		p.publisher.close()
+		p.record.probesDropped, p.record.probeOverhead = p.publisher.stats()
+		p.publisher = nil
+
+		for _, sink := range p.sinks() {
+			sink.Flush()
+		}
+	}
+
+	if err := p.saveCalibration(); err != nil && p.record.err == nil {
+		p.record.err = err
+	}
+
+	p.record.end()
+	p.timer.stop()
+	return true
+}
+
+// reset resets all of the process' properties to their initial state for an
+// execution of the specified number of iterations.
+func (p *VariableProcess) reset(iterations int) {
+	if p.controllerHistory != nil {
+		p.controllerHistory.begin(p.probeSignalLength)
+	}
+
+	if sinks := p.sinks(); len(sinks) > 0 {
+		p.publisher = newProbePublisher(sinks...)
+	}
+
+	p.progress.reset(iterations)
+
+	p.endedIterations = 0
+	p.routineStats.reset()
+	p.ticks = 0
+	p.lastSample = nil
+
+	p.controllerMutex.Lock()
+	p.tuner = nil
+	p.warmUpStart = p.clock.Now()
+	p.usageSamples = p.usageSamples[:0]
+	p.smoother.reset()
+	p.history.reset(p.clock.Now())
+	p.meter.reset(p.clock.Now())
+	if p.latencies != nil {
+		p.latencies.reset()
+	}
+	if p.histogram != nil {
+		p.histogram.reset()
+	}
+	if p.warmStarting() {
+		p.aimd.reset(p.convergedRoutines, p.clock.Now())
+		p.latency.reset(p.convergedRoutines)
+		p.energy.reset(p.convergedRoutines, 0)
+	} else {
+		p.controller.Reset()
+		p.controller.setpoint = float64(p.usageCPUs())
+		if p.autoTune && p.optimizer == OptimizerPID {
+			p.tuner = newAutoTuner(p.usageCPUs(), p.maxRoutines.get())
+		}
+		p.aimd.reset(p.initialRoutines, p.clock.Now())
+		p.latency.reset(p.initialRoutines)
+		p.energy.reset(p.initialRoutines, 0)
+	}
+	if p.subtractGC {
+		p.gc.reset()
 	}
+	p.controllerMutex.Unlock()
 
-	p.numRoutines = int64(p.initialRoutines)
-	p.iteration.set(0)
-	p.numToRemove = 0
-	p.controller.reset()
 	p.reporter.reset()
+	p.scheduling.reset()
+	p.throttling.reset()
+	if p.energyReporter != nil {
+		p.energyReporter.reset()
+	}
+}
+
+// numRoutines returns the number of routines the running executions are
+// using. The executions mutex must be held.
+func (p *VariableProcess) numRoutines() int {
+	n := 0
+	for _, x := range p.executions {
+		n += x.routines()
+	}
+	return n
+}
+
+// sinks returns the sinks the process records its controller's signals with.
+func (p *VariableProcess) sinks() []MetricsSink {
+	var sinks []MetricsSink
+	if p.controllerHistory != nil {
+		sinks = append(sinks, p.controllerHistory)
+	}
+	if p.metricsSink != nil {
+		sinks = append(sinks, p.metricsSink)
+	}
+	return sinks
+}
+
+// usageCPUs returns the number of CPUs the process' usage is normalized by.
+// The controller mutex must be held.
+func (p *VariableProcess) usageCPUs() int {
+	if p.cpuCount > 0 {
+		return p.cpuCount
+	}
+	return globalMaxProcs.procs()
+}
+
+// iterationsStarted returns the number of iterations begun by the process'
+// executions since it was reset. The executions mutex must be held.
+func (p *VariableProcess) iterationsStarted() int {
+	n := p.endedIterations
+	for _, x := range p.executions {
+		n += x.started()
+	}
+	return n
+}
+
+// startTicker starts a new ticker that ticks once per usage sample and begins
+// optimizing after each optimization interval's samples. The executions mutex
+// must be held.
+func (p *VariableProcess) startTicker() {
+	p.controllerMutex.Lock()
+	interval := p.optimizationInterval / time.Duration(p.samplesPerOptimization)
+	p.controllerMutex.Unlock()
+
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	p.ticker = p.clock.NewTicker(interval)
+	if t, ok := p.ticker.(handlingTicker); ok {
+		t.awaitHandling()
+	}
+	p.tickerDone = make(chan struct{})
+	go p.beginOptimizing(p.ticker, p.tickerDone)
+}
+
+// stopTicker stops the process' ticker and the goroutine receiving its ticks.
+// Stopping a ticker doesn't close its channel, so the goroutine is told to
+// exit through its done channel. The executions mutex must be held.
+func (p *VariableProcess) stopTicker() {
+	p.ticker.Stop()
+	close(p.tickerDone)
+	p.ticker = nil
+	p.tickerDone = nil
 }
 
 // beginOptimizing begins optimizing by calling optimizeNumRoutines each time
-// the process' ticker fires.
-func (p *VariableProcess) beginOptimizing() {
-	p.ticker = time.NewTicker(p.optimizationInterval)
-	for range p.ticker.C {
-		p.optimizeNumRoutines()
+// the ticker fires, until done is closed.
+func (p *VariableProcess) beginOptimizing(ticker Ticker, done <-chan struct{}) {
+	t, handling := ticker.(handlingTicker)
+	for {
+		var now time.Time
+		select {
+		case <-done:
+			return
+		case now = <-ticker.C():
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if p.trace != nil {
+			p.trace.tick(now)
+		}
+		p.throughput.sample()
+		if p.sampleUsage() {
+			p.optimizeNumRoutines()
+		}
+		if handling {
+			t.handled()
+		}
 	}
 }
 
-// runRoutine runs a new routine for the given number of iterations, picking up
-// where other routines have left off.
-func (p *VariableProcess) runRoutine() {
-	i := p.iteration.get()
-	for i < p.iterations {
-		p.operation(i)
+// sampleUsage takes one of the usage samples of the current optimization
+// interval and returns whether the interval is over and the process should
+// optimize. The last sample is taken by the optimization itself.
+func (p *VariableProcess) sampleUsage() bool {
+	p.controllerMutex.Lock()
+	defer p.controllerMutex.Unlock()
 
-		n := atomic.LoadInt64(&p.numToRemove)
-		if n > 0 && atomic.LoadInt64(&p.numRoutines) > 1 {
-			atomic.AddInt64(&p.numToRemove, -1)
-			atomic.AddInt64(&p.numRoutines, -1)
-			break
-		} else if n > 0 {
-			atomic.AddInt64(&p.numToRemove, -1)
+	if len(p.usageSamples)+1 >= p.samplesPerOptimization {
+		return true
+	}
+
+	p.usageSamples = append(p.usageSamples, p.reporter.usage())
+	return false
+}
+
+// runRoutine runs a new routine for x, picking up where x's other routines
+// have left off. Routines removed by an optimization park until a later
+// optimization wakes them, rather than exiting, so that a controller
+// oscillating around its setpoint doesn't repeatedly create goroutines.
+func (p *VariableProcess) runRoutine(x *variableExecution) {
+	progress := routineProgress{counter: p.progress}
+	defer x.returned()
+	stats := RoutineStats{Routine: p.routineStats.index()}
+	start := time.Now()
+	defer func() {
+		p.routineStats.record(&stats, start)
+	}()
+	growStack(p.stackHint)
+	routine := x.routineIndex()
+	labelRoutine(x.context(), p.name, routine)
+	if cpus := pinnedCPU(p.cpuAffinity, routine); cpus != nil {
+		defer bindToCPUs(cpus)()
+	} else if len(p.numaCPUs) > 0 {
+		defer bindToCPUs(p.numaCPUs)()
+	}
+
+	operation := x.operationFor(routine)
+	p.checkpoints.enter()
+	for p.runIterations(x, routine, operation, &progress, &stats) {
+		progress.flush()
+		p.checkpoints.leave()
+		if p.processGroup != nil {
+			p.processGroup.release(x.groupMember, 1)
 		}
 
-		i = p.iteration.add(1)
+		parked := time.Now()
+		if !x.park(p.waitStrategy) {
+			return
+		}
+
+		start = start.Add(time.Since(parked))
+		p.checkpoints.enter()
+	}
+
+	progress.flush()
+	p.checkpoints.leave()
+	if p.processGroup != nil {
+		p.processGroup.release(x.groupMember, 1)
 	}
+	x.exit()
+}
+
+// runIterations runs x's iterations on the routine-th routine, calling
+// operation for each, until none remain, and returns false, or until an
+// optimization removes the routine, and returns true.
+func (p *VariableProcess) runIterations(x *variableExecution, routine int, operation Operation, progress *routineProgress, stats *RoutineStats) bool {
+	p.checkpoints.wait(progress)
+	i, ok := x.next(routine)
+	for ok {
+		progress.claim(1)
+		if p.trace != nil {
+			p.trace.claim(p.clock.Now(), routine, i, i+1)
+		}
+		if p.latencies != nil || p.histogram != nil {
+			start := p.clock.Now()
+			operation(i)
+			latency := p.clock.Now().Sub(start)
+			if p.latencies != nil {
+				p.latencies.record(latency)
+			}
+			if p.histogram != nil {
+				p.histogram.record(latency)
+			}
+		} else {
+			operation(i)
+		}
+		progress.complete()
+		stats.Iterations++
+		p.throughput.Add(1)
+
+		n := atomic.LoadInt64(&x.numToRemove)
+		if n > 0 && atomic.LoadInt64(&x.numRoutines) > 1 {
+			atomic.AddInt64(&x.numToRemove, -1)
+			atomic.AddInt64(&x.numRoutines, -1)
+			return true
+		} else if n > 0 {
+			atomic.AddInt64(&x.numToRemove, -1)
+		}
 
-	p.group.Done()
+		p.checkpoints.wait(progress)
+		i, ok = x.next(routine)
+	}
+	return false
 }
 
-// optimizeNumRoutines variable the number of routines to use for the parallel
-// operation.
+// optimizeNumRoutines varies the number of routines each running execution
+// uses.
+//
+// Executions with a deadline are given the routines they need to meet it.
+// The routines called for by the controller are divided between the other
+// executions in proportion to their remaining iterations. In total the
+// executions never use more than the process' maximum number of routines.
 func (p *VariableProcess) optimizeNumRoutines() {
-	p.group.Add(1)
+	// Listeners are told about the optimization once the executions mutex
+	// has been released so that they may call the process' methods.
+	var sample ControllerSample
+	added, removed, optimized := 0, 0, false
+	defer func() {
+		if optimized {
+			p.listeners.optimized(sample, added, removed)
+		}
+	}()
+
+	p.executionsMutex.Lock()
+	defer p.executionsMutex.Unlock()
+
+	if len(p.executions) == 0 {
+		return
+	}
+
+	maxRoutines := p.maxRoutines.get()
+	targets := make([]int, len(p.executions))
+	available := maxRoutines
+	pooledRemaining := 0
+	pooledRoutines := 0
+	pooled := 0
 
 	p.controllerMutex.Lock()
-	usage := p.reporter.usage()
-	u, e := p.controller.next(usage)
+	p.usageSamples = append(p.usageSamples, p.reporter.usage())
+	usage := medianUsage(p.usageSamples)
+	p.usageSamples = p.usageSamples[:0]
+	now := p.clock.Now()
+	throttled := p.throttling.throttled()
+	joules, measuredEnergy := 0.0, false
+	if p.energyReporter != nil {
+		joules, measuredEnergy = p.energyReporter.energy()
+	}
+	p.history.record(now, usage, throttled, joules)
+	if p.subtractGC {
+		_, gcUsage := p.gc.usage()
+		usage = math.Max(0.0, usage-gcUsage)
+	}
+	if p.trace != nil {
+		p.trace.usage(now, usage)
+	}
+	usage = p.smoother.next(usage, p.controller.configuration.UsageSmoothing)
+	var u, e float64
+	for i, x := range p.executions {
+		if x.deadline == nil {
+			pooledRemaining += x.remaining()
+			pooledRoutines += x.routines()
+			pooled++
+			continue
+		}
+
+		started := x.started()
+		du, de := x.deadline.next(now, started, x.iterations-started, x.routines())
+		targets[i] = clampRoutines(du, available)
+		available -= targets[i]
+		u += du
+		e = de
+	}
+
+	if pooled > 0 && now.Sub(p.warmUpStart) < p.warmUp {
+		// Hold the routines until the process has warmed up.
+		u = float64(pooledRoutines)
+	} else if pooled > 0 && p.optimizer == OptimizerAIMD {
+		u, e = p.aimd.next(p.iterationsStarted(), now, maxRoutines)
+	} else if pooled > 0 && p.optimizer == OptimizerLatency {
+		if p95, ok := p.latencies.percentile(0.95); ok {
+			u, e = p.latency.next(p95, p.controller.configuration.LatencyTarget, maxRoutines)
+		} else {
+			u = p.latency.routines
+		}
+	} else if pooled > 0 && p.optimizer == OptimizerEnergy {
+		if measuredEnergy {
+			u, e = p.energy.next(joules, p.iterationsStarted(), maxRoutines)
+		} else {
+			u = p.energy.routines
+		}
+	} else if pooled > 0 && p.optimizer == OptimizerThroughput {
+		if throughput, ok := p.meter.measure(p.iterationsStarted(), now); ok {
+			u, e = p.controller.nextError(1.0 - throughput/p.controller.configuration.ThroughputTarget)
+		} else {
+			u = p.controller.previousOutput
+		}
+	} else if pooled > 0 && p.tuner != nil {
+		var done, ok bool
+		u, e, done, ok = p.tuner.next(usage)
+		if done {
+			if ok {
+				p.controller.configuration = p.tuner.configuration(p.controller.configuration)
+			}
+			p.controller.Reset()
+			p.controller.hold(u)
+			p.tuner = nil
+		}
+	} else if pooled > 0 {
+		u, e = p.controller.Next(usage)
+	}
+	maxDelta := p.controller.configuration.MaxRoutinesDeltaPerTick
+	deadband := p.controller.configuration.Deadband
+	memoryLimit := p.controller.configuration.MemoryLimit
+	highWater := p.controller.configuration.MemoryHighWater
+	schedulingLimit := p.controller.configuration.SchedulingLatencyLimit
 	p.controllerMutex.Unlock()
 
-	m := int(math.Ceil(u))
-	p.maxRoutines.mutex.Lock()
-	if m > p.maxRoutines.value {
-		m = p.maxRoutines.value
+	m := clampRoutines(u, maxRoutines)
+	if pooled > 0 {
+		m = controlledRoutines(u, pooledRoutines, maxRoutines, deadband, maxDelta)
+		if memoryLimit > 0 || highWater > 0.0 {
+			heap, fraction := memoryReporter{limit: memoryLimit}.usage()
+			limited := memoryLimitedRoutines(m, pooledRoutines, heap, memoryLimit)
+			limited = memoryThrottledRoutines(limited, pooledRoutines, fraction, highWater)
+			if limited < m {
+				// Keep the controller's integral from winding up while memory
+				// rather than CPU usage limits the routines.
+				m = limited
+				p.controllerMutex.Lock()
+				p.controller.hold(float64(m))
+				p.controllerMutex.Unlock()
+			}
+		}
+		if limited := throttledRoutines(m, pooledRoutines, throttled); limited < m {
+			// Usage drops while the program is throttled, but the drop isn't
+			// capacity more routines could use.
+			m = limited
+			p.controllerMutex.Lock()
+			p.controller.hold(float64(m))
+			p.controllerMutex.Unlock()
+		}
+		if schedulingLimit > 0 {
+			if latency, ok := p.scheduling.latency(schedulingLatencyPercentile); ok {
+				if limited := schedulingThrottledRoutines(m, pooledRoutines, latency, schedulingLimit); limited < m {
+					// Adding routines would only make them wait longer, so
+					// keep the integral from winding up here too.
+					m = limited
+					p.controllerMutex.Lock()
+					p.controller.hold(float64(m))
+					p.controllerMutex.Unlock()
+				}
+			}
+		}
+		p.convergedRoutines = maxInt(m, 1)
+		total := minInt(m, available)
+		for i, x := range p.executions {
+			if x.deadline == nil {
+				targets[i] = proportionalRoutines(total, x.remaining(), pooledRemaining)
+			}
+		}
 	}
-	p.maxRoutines.mutex.Unlock()
 
-	routines := int(atomic.LoadInt64(&p.numRoutines))
-	n := m - routines
+	sample = ControllerSample{
+		Usage:    usage,
+		Output:   u,
+		Error:    e,
+		Routines: float64(m),
+	}
+	p.lastSample = &sample
+	if p.trace != nil {
+		p.trace.optimize(now, sample)
+	}
+	if p.publisher != nil {
+		p.publisher.publish(sample)
+	}
+
+	for i, x := range p.executions {
+		if x.shards != nil {
+			x.shards.rebalance()
+		}
+		if n := p.optimizeExecution(x, targets[i]); n > 0 {
+			added += n
+		} else {
+			removed -= n
+		}
+		if x.span != nil {
+			x.span.Optimized(ControllerSample{
+				Usage:    usage,
+				Error:    e,
+				Output:   u,
+				Routines: float64(targets[i]),
+			})
+		}
+	}
+
+	if p.trace != nil {
+		if added > 0 {
+			p.trace.routines(now, added)
+		}
+		if removed > 0 {
+			p.trace.routines(now, -removed)
+		}
+	}
+
+	routines := p.numRoutines()
+	p.record.observeRoutines(routines)
+	globalMaxProcs.set(p.maxProcs, routines)
+	optimized = true
 
-	if p.probeController {
-		p.CPUProbe.C <- usage
-		p.PIDProbe.C <- u
-		p.ErrorProbe.C <- e
-		p.RoutineProbe.C <- float64(m)
+	p.ticks++
+	p.events.send(Event{
+		Tick:     p.ticks,
+		Time:     now,
+		Usage:    usage,
+		Error:    e,
+		Output:   u,
+		Routines: routines,
+	})
+}
+
+// optimizeExecution adds or removes routines so that x uses m routines and
+// returns the number of routines added, or the negated number removed.
+func (p *VariableProcess) optimizeExecution(x *variableExecution, m int) int {
+	if p.processGroup != nil {
+		if share := p.processGroup.share(); m > share {
+			m = share
+		}
 	}
 
-	if n == 0 {
-		p.group.Done()
-	} else if n > 0 {
-		if n > 1 {
-			p.group.Add(n - 1)
+	routines := x.routines()
+	n := m - routines
+	if n > 0 {
+		if remaining := x.remaining(); n > remaining {
+			n = remaining
+		}
+		woken := minInt(n, x.parkedRoutines())
+		n = woken + globalSpawnLimiter.take(n-woken)
+		if p.processGroup != nil {
+			n = p.processGroup.acquire(x.groupMember, n)
 		}
 
-		atomic.AddInt64(&p.numRoutines, int64(n))
+		if n == 0 {
+			return 0
+		}
 
-		for i := 0; i < n; i++ {
-			go p.runRoutine()
+		spawned, ok := x.add(n)
+		if !ok {
+			if p.processGroup != nil {
+				p.processGroup.release(x.groupMember, n)
+			}
+			return 0
 		}
-	} else if n < 0 {
-		if routines > 1 {
-			atomic.StoreInt64(&p.numToRemove, -1*int64(n))
+
+		for i := 0; i < spawned; i++ {
+			p.pool.run(func() {
+				p.runRoutine(x)
+			})
 		}
-		p.group.Done()
+		return n
+	} else if n < 0 && routines > 1 {
+		atomic.StoreInt64(&x.numToRemove, -1*int64(n))
+		return n
+	}
+	return 0
+}
+
+// MARK: Private functions
+
+// proportionalRoutines returns the share of total routines for an execution
+// with remaining of all executions' totalRemaining iterations. Every execution
+// is given at least one routine.
+func proportionalRoutines(total int, remaining int, totalRemaining int) int {
+	n := 0
+	if totalRemaining > 0 {
+		n = int(int64(total) * int64(remaining) / int64(totalRemaining))
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// controlledRoutines returns the number of routines called for by a controller
+// output of u when current routines are running. The output is clamped to
+// maxRoutines and ignored if it is within deadband routines of current, and
+// the result differs from current by at most maxDelta routines unless maxDelta
+// is zero.
+func controlledRoutines(u float64, current int, maxRoutines int, deadband float64, maxDelta int) int {
+	m := clampRoutines(u, maxRoutines)
+	if withinDeadband(u, current, deadband) {
+		m = minInt(current, maxRoutines)
+	}
+	if maxDelta > 0 {
+		m = limitRoutinesDelta(m, current, maxDelta)
+	}
+	return m
+}
+
+// withinDeadband returns whether or not a controller output of u is within
+// deadband routines of the current number of routines.
+func withinDeadband(u float64, current int, deadband float64) bool {
+	return deadband > 0.0 && math.Abs(u-float64(current)) <= deadband
+}
+
+// limitRoutinesDelta returns m limited to within maxDelta of the current
+// number of routines.
+func limitRoutinesDelta(m int, current int, maxDelta int) int {
+	if m > current+maxDelta {
+		return current + maxDelta
+	} else if m < current-maxDelta {
+		return current - maxDelta
+	}
+	return m
+}
+
+// clampRoutines returns the number of routines called for by a controller
+// output of u, between zero and maxRoutines.
+func clampRoutines(u float64, maxRoutines int) int {
+	if maxRoutines <= 0 || !(u > 0.0) {
+		return 0
+	}
+	if u >= float64(maxRoutines) {
+		return maxRoutines
 	}
+	return int(math.Ceil(u))
 }
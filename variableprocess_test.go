@@ -2,6 +2,9 @@ package parallel
 
 import (
 	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -46,6 +49,305 @@ func TestStopVariableProcess(t *testing.T) {
 	}
 }
 
+func TestVariableProcessZeroIterations(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(100*time.Millisecond, 4, 20, c, true)
+	p.Execute(0, func(i int) {
+		t.Errorf("No operations should be executed.")
+	})
+}
+
+func TestVariableProcessTinyRun(t *testing.T) {
+	v := make([]int, 2)
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 8, 20, c, false)
+	p.Execute(len(v), func(i int) {
+		if n := p.NumRoutines(); n > len(v) {
+			t.Errorf("Routine count, %d, should not exceed the iteration count.", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+		}
+	}
+}
+
+func TestVariableProcessConcurrentExecutions(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 8, c, false)
+
+	sizes := []int{2000, 500, 100}
+	counts := make([][]int32, len(sizes))
+	var wg sync.WaitGroup
+	wg.Add(len(sizes))
+	for e, size := range sizes {
+		counts[e] = make([]int32, size)
+		go func(v []int32) {
+			defer wg.Done()
+			p.Execute(len(v), func(i int) {
+				if n := p.NumRoutines(); n > 8+len(sizes) {
+					t.Errorf("Routine count, %d, should not exceed the maximum.", n)
+				}
+				atomic.AddInt32(&v[i], 1)
+				time.Sleep(50 * time.Microsecond)
+			})
+		}(counts[e])
+	}
+	wg.Wait()
+
+	for e, v := range counts {
+		for i, value := range v {
+			if value != 1 {
+				t.Errorf("Index %d of execution %d was executed %d times.", i, e, value)
+				break
+			}
+		}
+	}
+
+	if p.NumRoutines() != 0 {
+		t.Errorf("Routine count, %d, should be 0 after every execution finished.", p.NumRoutines())
+	}
+}
+
+func TestProportionalRoutines(t *testing.T) {
+	if n := proportionalRoutines(10, 750, 1000); n != 7 {
+		t.Errorf("Routines, %d, should be 7.", n)
+	}
+
+	if n := proportionalRoutines(10, 10, 1000); n != 1 {
+		t.Errorf("Routines, %d, should be at least 1.", n)
+	}
+}
+
+func TestVariableProcessCPUCount(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+
+	p.Execute(10, func(i int) {})
+	if s, n := p.controller.Setpoint(), float64(runtime.GOMAXPROCS(0)); s != n {
+		t.Errorf("Setpoint, %f, should be GOMAXPROCS, %f.", s, n)
+	}
+
+	p.SetCPUCount(3)
+	if n := p.CPUCount(); n != 3 {
+		t.Errorf("CPU count, %d, should be 3.", n)
+	}
+
+	p.Execute(10, func(i int) {})
+	if s := p.controller.Setpoint(); s != 3.0 {
+		t.Errorf("Setpoint, %f, should be 3.", s)
+	}
+}
+
+func TestVariableProcessWarmStart(t *testing.T) {
+	c := NewControllerConfiguration(0.0, 1.0, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	p.SetWarmStart(true)
+
+	operation := func(i int) {
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	p.Execute(1000, operation)
+	converged := p.convergedRoutines
+	if converged < 1 {
+		t.Fatalf("Converged routine count, %d, should be recorded.", converged)
+	}
+
+	if n := p.startingRoutines(); n != converged {
+		t.Errorf("Starting routine count, %d, should be %d.", n, converged)
+	}
+
+	p.SetWarmStart(false)
+	if n := p.startingRoutines(); n != 1 {
+		t.Errorf("Starting routine count, %d, should be 1 without a warm start.", n)
+	}
+
+	p.SetWarmStart(true)
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		operation(i)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+}
+
+func TestLimitRoutinesDelta(t *testing.T) {
+	cases := []struct {
+		m, current, maxDelta, limited int
+	}{
+		{20, 4, 2, 6},
+		{1, 8, 3, 5},
+		{5, 4, 2, 5},
+	}
+
+	for _, c := range cases {
+		if n := limitRoutinesDelta(c.m, c.current, c.maxDelta); n != c.limited {
+			t.Errorf("limitRoutinesDelta(%d, %d, %d) = %d, should be %d.", c.m, c.current, c.maxDelta, n, c.limited)
+		}
+	}
+}
+
+func TestWithinDeadband(t *testing.T) {
+	if !withinDeadband(4.8, 4, 1.0) {
+		t.Errorf("An output of 4.8 should be within a deadband of 1 around 4 routines.")
+	}
+
+	if withinDeadband(5.5, 4, 1.0) {
+		t.Errorf("An output of 5.5 should be outside a deadband of 1 around 4 routines.")
+	}
+
+	if withinDeadband(4.0, 4, 0.0) {
+		t.Errorf("A deadband of zero should be disabled.")
+	}
+}
+
+func TestVariableProcessMaxRoutinesDelta(t *testing.T) {
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	c.MaxRoutinesDeltaPerTick = 1
+	p := NewVariableProcess(10*time.Millisecond, 1, 64, c, true)
+
+	v := make([]int, 200)
+	p.Execute(len(v), func(i int) {
+		time.Sleep(time.Millisecond)
+		v[i]++
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+
+	// Without removals, each optimization adds at most one routine to the
+	// previous target.
+	history := p.ControllerHistory()
+	for i := 1; i < len(history); i++ {
+		if history[i].Routines > history[i-1].Routines+1 {
+			t.Errorf("Routines increased from %f to %f in one optimization.", history[i-1].Routines, history[i].Routines)
+			break
+		}
+	}
+}
+
+func TestVariableExecutionParking(t *testing.T) {
+	x := newVariableExecution(10, func(i int) {}, nil)
+	x.active = 2
+	resumed := make(chan bool)
+	go func() {
+		resumed <- x.park(WaitPark)
+	}()
+
+	for x.parkedRoutines() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if spawned, ok := x.add(2); !ok || spawned != 1 {
+		t.Errorf("Adding 2 routines should wake the parked routine and spawn 1, not %d.", spawned)
+	}
+	if !<-resumed {
+		t.Error("The parked routine should resume.")
+	}
+
+	go func() {
+		resumed <- x.park(WaitPark)
+	}()
+	for x.parkedRoutines() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	x.exit()
+	x.exit()
+	if <-resumed {
+		t.Error("The parked routine should exit with the execution's last running routine.")
+	}
+	if _, ok := x.add(1); ok {
+		t.Error("Routines shouldn't be added once every routine has exited.")
+	}
+}
+
+func TestVariableExecutionCompletion(t *testing.T) {
+	x := newVariableExecution(10, func(i int) {}, nil)
+	x.active = 1
+	x.goroutines = 1
+
+	if spawned, ok := x.add(2); !ok || spawned != 2 {
+		t.Fatalf("Adding 2 routines should spawn 2, not %d.", spawned)
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		x.wait()
+		close(waited)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-waited:
+			t.Fatalf("The execution finished with %d goroutines running.", 3-i)
+		case <-time.After(5 * time.Millisecond):
+		}
+		x.returned()
+	}
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Error("The execution should finish once its last goroutine returns.")
+	}
+}
+
+func TestVariableProcessReusesParkedRoutines(t *testing.T) {
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(5*time.Millisecond, 4, 4, c, false)
+	p.Execute(1500, func(i int) {
+		switch i {
+		case 300:
+			p.SetMaxRoutines(1)
+		case 900:
+			p.SetMaxRoutines(4)
+		}
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	if n := len(p.RoutineStats()); n > 4 {
+		t.Errorf("The execution started %d routines instead of waking parked ones.", n)
+	}
+}
+
+func TestVariableProcessStopsOptimizing(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 4, c, false)
+	for n := 0; n < 5; n++ {
+		p.Execute(20, func(i int) {
+			if i == 10 {
+				p.SetOptimizationInterval(2 * time.Millisecond)
+			}
+			time.Sleep(time.Millisecond)
+		})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines were left running after executing.", n-before)
+	}
+}
+
 // MARK: Benchmarks
 
 func BenchmarkVariableProcess(b *testing.B) {
@@ -0,0 +1,71 @@
+package parallel
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// WaitStrategy types determine how the routines a variable process removes
+// wait until a later optimization adds them back, trading CPU time for the
+// latency of waking them.
+type WaitStrategy int
+
+const (
+	// WaitPark routines park on a condition variable as soon as they are
+	// removed. Parked routines use no CPU time, but take the longest to wake.
+	WaitPark WaitStrategy = iota
+
+	// WaitSpinThenPark routines spin for a short while, in case they are added
+	// back by the next optimization, before parking.
+	WaitSpinThenPark
+
+	// WaitSpinThenYield routines spin for a short while and then repeatedly
+	// yield their processor to other goroutines until they are added back.
+	WaitSpinThenYield
+
+	// WaitSpin routines spin until they are added back. They wake the fastest
+	// but keep a CPU busy for as long as they wait.
+	WaitSpin
+)
+
+// The time routines spin before parking or yielding.
+const waitSpinDuration = 50 * time.Microsecond
+
+// String returns the name of the wait strategy.
+func (s WaitStrategy) String() string {
+	switch s {
+	case WaitPark:
+		return "park"
+	case WaitSpinThenPark:
+		return "spin-then-park"
+	case WaitSpinThenYield:
+		return "spin-then-yield"
+	case WaitSpin:
+		return "spin"
+	default:
+		return fmt.Sprintf("WaitStrategy(%d)", int(s))
+	}
+}
+
+// MARK: Private methods
+
+// spin waits, without holding any locks, until the value at generation is no
+// longer n. It returns early once a spin-then-park routine has spun for the
+// spin duration.
+func (s WaitStrategy) spin(generation *int64, n int64) {
+	start := time.Now()
+	for atomic.LoadInt64(generation) == n {
+		switch s {
+		case WaitSpinThenPark:
+			if time.Since(start) >= waitSpinDuration {
+				return
+			}
+		case WaitSpinThenYield:
+			if time.Since(start) >= waitSpinDuration {
+				runtime.Gosched()
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestWaitStrategyString(t *testing.T) {
+	names := map[WaitStrategy]string{
+		WaitPark:          "park",
+		WaitSpinThenPark:  "spin-then-park",
+		WaitSpinThenYield: "spin-then-yield",
+		WaitSpin:          "spin",
+		WaitStrategy(9):   "WaitStrategy(9)",
+	}
+
+	for strategy, name := range names {
+		if strategy.String() != name {
+			t.Errorf("Name, %s, should be %s.", strategy, name)
+		}
+	}
+}
+
+func TestWaitStrategies(t *testing.T) {
+	for _, strategy := range []WaitStrategy{WaitPark, WaitSpinThenPark, WaitSpinThenYield, WaitSpin} {
+		x := newVariableExecution(10, func(i int) {}, nil)
+		x.active = 2
+		resumed := make(chan bool)
+		go func() {
+			resumed <- x.park(strategy)
+		}()
+
+		for x.parkedRoutines() != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		if _, ok := x.add(1); !ok {
+			t.Fatalf("A routine should be added with %s.", strategy)
+		}
+		if !<-resumed {
+			t.Errorf("The parked routine should resume with %s.", strategy)
+		}
+
+		go func() {
+			resumed <- x.park(strategy)
+		}()
+		for x.parkedRoutines() != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		x.exit()
+		x.exit()
+		if <-resumed {
+			t.Errorf("The parked routine should exit with %s.", strategy)
+		}
+	}
+}
+
+func TestVariableProcessWaitStrategy(t *testing.T) {
+	c := NewControllerConfiguration(100.0, 0.0, 0.0, 1.0, 1.0)
+	p := NewVariableProcess(5*time.Millisecond, 4, 4, c, false)
+	p.SetWaitStrategy(WaitSpinThenYield)
+	if p.WaitStrategy() != WaitSpinThenYield {
+		t.Errorf("Wait strategy, %s, should be spin-then-yield.", p.WaitStrategy())
+	}
+
+	v := make([]int, 1000)
+	p.Execute(len(v), func(i int) {
+		switch i {
+		case 200:
+			p.SetMaxRoutines(1)
+		case 600:
+			p.SetMaxRoutines(4)
+		}
+		v[i]++
+		time.Sleep(200 * time.Microsecond)
+	})
+
+	for i, value := range v {
+		if value != 1 {
+			t.Errorf("Index %d was executed %d times.", i, value)
+			break
+		}
+	}
+	if n := len(p.RoutineStats()); n > 4 {
+		t.Errorf("The execution started %d routines instead of waking waiting ones.", n)
+	}
+}
@@ -0,0 +1,110 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// StallHandler types are called when a process' watchdog finds that no
+// iteration has completed for the process' stall timeout.
+type StallHandler func(stall Stall)
+
+// Stall types describe an execution that has stopped making progress.
+type Stall struct {
+	// The time since an iteration last completed.
+	Duration time.Duration
+
+	// The stats of the stalled process.
+	Stats ProcessStats
+}
+
+// The fewest times a watchdog checks its process per stall timeout.
+const watchdogChecksPerTimeout = 4
+
+// watchdog types watch the count of a process' throughput meter while it
+// executes and report a stall when the count stops changing.
+type watchdog struct {
+	timeout time.Duration
+	handler StallHandler
+	meter   *ThroughputMeter
+	stats   func() ProcessStats
+	done    chan struct{}
+	group   sync.WaitGroup
+}
+
+// MARK: Private functions
+
+// startWatchdog starts and returns a watchdog calling handler when the
+// meter's count doesn't change for timeout. It returns nil if timeout is zero.
+func startWatchdog(timeout time.Duration, handler StallHandler, meter *ThroughputMeter, stats func() ProcessStats) *watchdog {
+	if timeout <= 0 || handler == nil {
+		return nil
+	}
+
+	w := &watchdog{
+		timeout: timeout,
+		handler: handler,
+		meter:   meter,
+		stats:   stats,
+		done:    make(chan struct{}),
+	}
+	w.group.Add(1)
+	go w.run()
+	return w
+}
+
+// checkStallTimeout panics if a stall timeout is negative or a positive
+// timeout has no handler.
+func checkStallTimeout(method string, timeout time.Duration, handler StallHandler) {
+	if timeout < 0 {
+		misuse("%s called with a timeout of %s; the timeout must not be negative", method, timeout)
+	} else if timeout > 0 && handler == nil {
+		misuse("%s called with a nil handler", method)
+	}
+}
+
+// MARK: Private methods
+
+// stop stops the watchdog and waits for it to exit. A handler that is running
+// returns before stop does.
+func (w *watchdog) stop() {
+	if w == nil {
+		return
+	}
+
+	close(w.done)
+	w.group.Wait()
+}
+
+// run checks the meter until the watchdog is stopped. A stall is reported
+// once, and again only after an iteration completes and the process stalls
+// again.
+func (w *watchdog) run() {
+	defer w.group.Done()
+
+	interval := w.timeout / watchdogChecksPerTimeout
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	count := w.meter.Count()
+	changed := time.Now()
+	reported := false
+	for {
+		select {
+		case <-w.done:
+			return
+		case now := <-ticker.C:
+			if c := w.meter.Count(); c != count {
+				count = c
+				changed = now
+				reported = false
+			} else if stalled := now.Sub(changed); !reported && stalled >= w.timeout {
+				reported = true
+				w.handler(Stall{Duration: stalled, Stats: w.stats()})
+			}
+		}
+	}
+}
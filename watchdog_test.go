@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestFixedProcessStallTimeout(t *testing.T) {
+	p := NewFixedProcess(2)
+	stalls := make(chan Stall, 4)
+	p.SetStallTimeout(20*time.Millisecond, func(stall Stall) {
+		stalls <- stall
+	})
+
+	release := make(chan struct{})
+	var once sync.Once
+	go func() {
+		stall := <-stalls
+		if stall.Duration < 20*time.Millisecond {
+			t.Errorf("The stall's duration, %s, should be at least the timeout.", stall.Duration)
+		}
+		if stall.Stats.Iterations != 10 {
+			t.Errorf("The stall's iterations, %d, should be 10.", stall.Stats.Iterations)
+		}
+		once.Do(func() { close(release) })
+	}()
+
+	p.Execute(10, func(i int) {
+		if i == 0 {
+			<-release
+		}
+	})
+
+	if p.StallTimeout() != 20*time.Millisecond {
+		t.Errorf("Stall timeout, %s, should be 20ms.", p.StallTimeout())
+	}
+	if n := len(stalls); n != 0 {
+		t.Errorf("The stall was reported %d more times.", n)
+	}
+}
+
+func TestVariableProcessStallTimeout(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	p := NewVariableProcess(time.Millisecond, 1, 2, c, false)
+	release := make(chan struct{})
+	reported := 0
+	p.SetStallTimeout(20*time.Millisecond, func(stall Stall) {
+		reported++
+		close(release)
+	})
+
+	p.Execute(10, func(i int) {
+		if i == 5 {
+			<-release
+		}
+	})
+
+	if reported != 1 {
+		t.Errorf("The stall was reported %d times instead of once.", reported)
+	}
+}
+
+func TestStallTimeoutDisabled(t *testing.T) {
+	p := NewFixedProcess(2)
+	p.SetStallTimeout(20*time.Millisecond, func(stall Stall) {
+		t.Error("The stall handler shouldn't be called.")
+	})
+	p.SetStallTimeout(0, nil)
+
+	p.Execute(2, func(i int) {
+		time.Sleep(50 * time.Millisecond)
+	})
+}
@@ -0,0 +1,90 @@
+package parallel
+
+import "sort"
+
+// WeightFunction types return the relative cost of the i-th operation.
+// Weights are only compared with each other, so any unit may be used.
+// Negative weights are treated as zero.
+type WeightFunction func(i int) float64
+
+// iterationCosts types contain the cumulative weights of an execution's
+// iterations. A nil *iterationCosts represents iterations of equal weight.
+type iterationCosts struct {
+	// The cumulative weights, where prefix[i] is the total weight of the
+	// iterations before i.
+	prefix []float64
+}
+
+// MARK: Initializers
+
+// newIterationCosts evaluates weight for the specified number of iterations
+// and returns their cumulative costs, or nil if weight is nil.
+func newIterationCosts(iterations int, weight WeightFunction) *iterationCosts {
+	if weight == nil {
+		return nil
+	}
+
+	c := &iterationCosts{
+		prefix: make([]float64, iterations+1),
+	}
+
+	for i := 0; i < iterations; i++ {
+		w := weight(i)
+		if w < 0.0 {
+			w = 0.0
+		}
+		c.prefix[i+1] = c.prefix[i] + w
+	}
+
+	return c
+}
+
+// MARK: Private methods
+
+// partition divides the specified number of iterations into numRoutines
+// contiguous blocks of roughly equal total weight and returns their
+// boundaries. The r-th block is [bounds[r], bounds[r+1]).
+func (c *iterationCosts) partition(iterations int, numRoutines int) []int {
+	bounds := make([]int, numRoutines+1)
+	for r := 1; r <= numRoutines; r++ {
+		if c == nil || c.prefix[iterations] == 0.0 {
+			bounds[r] = iterations * r / numRoutines
+			continue
+		}
+
+		target := c.prefix[iterations] * float64(r) / float64(numRoutines)
+		bounds[r] = c.search(bounds[r-1], iterations, target)
+	}
+
+	bounds[numRoutines] = iterations
+	return bounds
+}
+
+// chunk returns the end of a chunk beginning at start that contains roughly
+// the remaining weight divided by divisor. Chunks contain at least one
+// iteration.
+func (c *iterationCosts) chunk(start int, iterations int, divisor int) int {
+	remaining := iterations - start
+	if c == nil || c.prefix[iterations] == c.prefix[start] {
+		n := remaining / divisor
+		if n < 1 {
+			n = 1
+		}
+		return start + n
+	}
+
+	target := c.prefix[start] + (c.prefix[iterations]-c.prefix[start])/float64(divisor)
+	end := c.search(start, iterations, target)
+	if end <= start {
+		end = start + 1
+	}
+	return end
+}
+
+// search returns the smallest index in [low, high] whose cumulative weight is at
+// least target.
+func (c *iterationCosts) search(low int, high int, target float64) int {
+	return low + sort.Search(high-low+1, func(n int) bool {
+		return c.prefix[low+n] >= target
+	})
+}
@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"math"
+	"testing"
+)
+
+// MARK: Tests
+
+func TestIterationCostsPartition(t *testing.T) {
+	iterations := 10000
+	c := newIterationCosts(iterations, func(i int) float64 {
+		return float64(i)
+	})
+
+	bounds := c.partition(iterations, 4)
+	if bounds[0] != 0 || bounds[4] != iterations {
+		t.Fatalf("Bounds, %v, should span [0, %d).", bounds, iterations)
+	}
+
+	total := c.prefix[iterations]
+	for r := 0; r < 4; r++ {
+		weight := c.prefix[bounds[r+1]] - c.prefix[bounds[r]]
+		if math.Abs(weight-total/4) > float64(iterations) {
+			t.Errorf("Block %d, [%d, %d), has weight %f instead of about %f.", r, bounds[r], bounds[r+1], weight, total/4)
+		}
+	}
+}
+
+func TestIterationCostsUniformPartition(t *testing.T) {
+	var c *iterationCosts
+	bounds := c.partition(10, 3)
+
+	expected := []int{0, 3, 6, 10}
+	for i := range expected {
+		if bounds[i] != expected[i] {
+			t.Errorf("Bounds, %v, should be %v.", bounds, expected)
+			break
+		}
+	}
+}
+
+func TestIterationCostsChunk(t *testing.T) {
+	c := newIterationCosts(100, func(i int) float64 {
+		if i < 10 {
+			return 10.0
+		}
+		return 0.0
+	})
+
+	if end := c.chunk(0, 100, 2); end != 5 {
+		t.Errorf("Chunk end, %d, should be 5.", end)
+	}
+
+	if end := c.chunk(10, 100, 2); end != 55 {
+		t.Errorf("Chunk end, %d, should be 55 when the remaining weight is zero.", end)
+	}
+}
+
+func TestFixedProcessWeightCompleteness(t *testing.T) {
+	for _, schedule := range []Schedule{ScheduleDynamic, ScheduleWorkStealing, ScheduleGuided, ScheduleStatic} {
+		v := make([]int, 10007)
+		p := NewFixedProcess(4)
+		p.SetWeight(func(i int) float64 {
+			return float64(len(v) - i)
+		})
+		p.ExecuteSchedule(len(v), schedule, func(i int) {
+			v[i]++
+		})
+
+		for i, value := range v {
+			if value != 1 {
+				t.Errorf("Index %d was executed %d times with schedule %d.", i, value, schedule)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package parallel
+
+// WindowOperation types represent a single operation on a window of data.
+// Responders should process the window of data beginning at start.
+type WindowOperation func(start int)
+
+// The number of blocks ExecuteWindows, ExecuteBlocked and ExecuteGridBlocked
+// give each of a process' routines to balance the load between them.
+const windowBlocksPerRoutine = 4
+
+// MARK: Public functions
+
+// WindowCount returns the number of windows of the specified length, hop
+// elements apart, that fit in dataLen elements of data. Windows that would
+// extend past the end of the data aren't counted.
+func WindowCount(dataLen int, window int, hop int) int {
+	checkWindow("WindowCount", dataLen, window, hop)
+	if dataLen < window {
+		return 0
+	}
+	return (dataLen-window)/hop + 1
+}
+
+// ExecuteWindows executes the operation on process once for each window of the
+// specified length, hop elements apart, that fits in dataLen elements of data.
+// The operation receives the index of the window's first element, so the
+// window is data[start:start+window] and never extends past the end of the
+// data.
+//
+// Consecutive windows are grouped into blocks that are executed in parallel.
+// Windows within a block are visited in order by the same routine, so when
+// windows overlap each one reuses the data its predecessor brought into the
+// cache. Blocks are as large as possible while still giving each of the
+// process' routines, or each CPU if there are more, several to balance the
+// load.
+func ExecuteWindows(process Process, dataLen int, window int, hop int, operation WindowOperation) {
+	checkOperation("ExecuteWindows", operation == nil)
+	count := WindowCount(dataLen, window, hop)
+	if count == 0 {
+		return
+	}
+
+	blocks := minInt(count, balancedBlocks(process))
+	size := (count + blocks - 1) / blocks
+	blocks = (count + size - 1) / size
+
+	process.Execute(blocks, func(i int) {
+		end := minInt((i+1)*size, count)
+		for w := i * size; w < end; w++ {
+			operation(w * hop)
+		}
+	})
+}
+
+// MARK: Private functions
+
+// checkWindow panics if the data length is negative or the window or hop isn't
+// positive.
+func checkWindow(method string, dataLen int, window int, hop int) {
+	if dataLen < 0 {
+		misuse("%s called with a data length of %d; the length must not be negative", method, dataLen)
+	}
+	if window < 1 || hop < 1 {
+		misuse("%s called with a window of %d and a hop of %d; both must be positive", method, window, hop)
+	}
+}
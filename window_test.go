@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MARK: Tests
+
+func TestWindowCount(t *testing.T) {
+	cases := []struct {
+		dataLen, window, hop, count int
+	}{
+		{0, 4, 1, 0},
+		{3, 4, 1, 0},
+		{4, 4, 1, 1},
+		{10, 4, 2, 4},
+		{11, 4, 2, 4},
+		{12, 4, 2, 5},
+		{1024, 256, 64, 13},
+	}
+
+	for _, c := range cases {
+		if count := WindowCount(c.dataLen, c.window, c.hop); count != c.count {
+			t.Errorf("WindowCount(%d, %d, %d) = %d, should be %d.", c.dataLen, c.window, c.hop, count, c.count)
+		}
+	}
+}
+
+func TestExecuteWindows(t *testing.T) {
+	c := NewControllerConfiguration(2.0, 0.0, 1.0, 0.1, 1.0)
+	processes := []Process{
+		NewFixedProcess(4),
+		NewVariableProcess(time.Millisecond, 1, 4, c, false),
+	}
+
+	dataLen, window, hop := 100003, 512, 128
+	for _, p := range processes {
+		visits := make([]int32, WindowCount(dataLen, window, hop))
+		ExecuteWindows(p, dataLen, window, hop, func(start int) {
+			if start%hop != 0 || start < 0 || start+window > dataLen {
+				t.Errorf("Window at %d is out of range.", start)
+				return
+			}
+			atomic.AddInt32(&visits[start/hop], 1)
+		})
+
+		for i, n := range visits {
+			if n != 1 {
+				t.Errorf("Window %d was executed %d times.", i, n)
+				break
+			}
+		}
+	}
+}
+
+func TestExecuteWindowsShortData(t *testing.T) {
+	ExecuteWindows(NewFixedProcess(2), 10, 16, 4, func(start int) {
+		t.Errorf("No windows fit in the data, but one started at %d.", start)
+	})
+}
+
+func TestMisuseWindow(t *testing.T) {
+	expectMisuse(t, "both must be positive", func() {
+		WindowCount(10, 0, 1)
+	})
+
+	expectMisuse(t, "both must be positive", func() {
+		ExecuteWindows(NewFixedProcess(2), 10, 4, 0, func(start int) {})
+	})
+
+	expectMisuse(t, "must not be negative", func() {
+		WindowCount(-1, 4, 1)
+	})
+}